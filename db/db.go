@@ -41,6 +41,10 @@ func createTables() {
 	if _, err := DB.Exec(createUsersTable); err != nil {
 		log.Fatal("Could not create users table:", err)
 	}
+	// role: user / admin
+	if _, err := DB.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user';`); err != nil {
+		log.Fatal("Could not add users.role column:", err)
+	}
 
 	// 4) 刪掉原本的 createEventsTable（因為 events 會改由 Mongo 管）
 
@@ -55,4 +59,183 @@ func createTables() {
 	if _, err := DB.Exec(createRegistrationsTable); err != nil {
 		log.Fatal("Could not create registrations table:", err)
 	}
+	// 事件取消時報名會被作廢（status=voided）
+	alterRegistrations := `
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;`
+	if _, err := DB.Exec(alterRegistrations); err != nil {
+		log.Fatal("Could not alter registrations table:", err)
+	}
+	// 重複事件按單次報名：唯一鍵改成 (user_id, event_id, occurrence)
+	occurrenceRegistrations := `
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS occurrence TEXT NOT NULL DEFAULT '';
+	ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_user_id_event_id_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS registrations_user_event_occurrence_key ON registrations(user_id, event_id, occurrence);
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();`
+	if _, err := DB.Exec(occurrenceRegistrations); err != nil {
+		log.Fatal("Could not add registrations.occurrence:", err)
+	}
+
+	// 6) private 事件的邀請（以 email 對應，受邀者不一定已註冊）
+	createInvitationsTable := `
+	CREATE TABLE IF NOT EXISTS event_invitations (
+		id BIGSERIAL PRIMARY KEY,
+		event_id UUID NOT NULL,
+		email TEXT NOT NULL,
+		invited_by BIGINT NOT NULL REFERENCES users(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (event_id, email)
+	);`
+	if _, err := DB.Exec(createInvitationsTable); err != nil {
+		log.Fatal("Could not create event_invitations table:", err)
+	}
+
+	// 7) 多租戶：組織與組織成員；升級前的資料都屬於預設組織（id=1）
+	createOrganizations := `
+	CREATE TABLE IF NOT EXISTS organizations (
+		id BIGSERIAL PRIMARY KEY,
+		slug TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	INSERT INTO organizations(id, slug, name) VALUES (1, 'default', 'Default') ON CONFLICT (id) DO NOTHING;
+	SELECT setval(pg_get_serial_sequence('organizations', 'id'), GREATEST((SELECT MAX(id) FROM organizations), 1));
+	CREATE TABLE IF NOT EXISTS org_memberships (
+		org_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role TEXT NOT NULL DEFAULT 'member',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (org_id, user_id)
+	);
+	INSERT INTO org_memberships(org_id, user_id) SELECT 1, id FROM users WHERE role <> 'admin' ON CONFLICT DO NOTHING;
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+	ALTER TABLE event_invitations ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+	CREATE INDEX IF NOT EXISTS registrations_org_event_idx ON registrations(org_id, event_id);`
+	if _, err := DB.Exec(createOrganizations); err != nil {
+		log.Fatal("Could not create organizations tables:", err)
+	}
+
+	// 8) 購票：pending 訂單佔名額，付款成功後才建立報名；webhook 事件 id 用來去重
+	createOrders := `
+	CREATE TABLE IF NOT EXISTS orders (
+		id BIGSERIAL PRIMARY KEY,
+		org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id),
+		user_id BIGINT NOT NULL REFERENCES users(id),
+		event_id UUID NOT NULL,
+		occurrence TEXT NOT NULL DEFAULT '',
+		ticket_type_id TEXT NOT NULL,
+		amount BIGINT NOT NULL,
+		currency TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'pending',
+		provider TEXT NOT NULL DEFAULT '',
+		payment_id TEXT NOT NULL DEFAULT '',
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS orders_open_key ON orders(user_id, event_id, occurrence) WHERE status IN ('pending', 'paid');
+	CREATE INDEX IF NOT EXISTS orders_event_ticket_idx ON orders(event_id, occurrence, ticket_type_id);
+	CREATE INDEX IF NOT EXISTS orders_payment_idx ON orders(provider, payment_id);
+	CREATE TABLE IF NOT EXISTS payment_webhook_events (
+		provider TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (provider, event_id)
+	);`
+	if _, err := DB.Exec(createOrders); err != nil {
+		log.Fatal("Could not create orders tables:", err)
+	}
+
+	// 9) 折扣碼與使用紀錄（兌換時鎖住折扣碼那一列再計數）
+	createPromoCodes := `
+	CREATE TABLE IF NOT EXISTS promo_codes (
+		id BIGSERIAL PRIMARY KEY,
+		org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id),
+		code TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		value BIGINT NOT NULL,
+		currency TEXT NOT NULL DEFAULT '',
+		max_redemptions INT NOT NULL DEFAULT 0,
+		per_user_limit INT NOT NULL DEFAULT 0,
+		expires_at TIMESTAMPTZ,
+		event_ids TEXT[] NOT NULL DEFAULT '{}',
+		ticket_type_ids TEXT[] NOT NULL DEFAULT '{}',
+		active BOOLEAN NOT NULL DEFAULT true,
+		created_by BIGINT NOT NULL REFERENCES users(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (org_id, code)
+	);
+	CREATE TABLE IF NOT EXISTS promo_redemptions (
+		id BIGSERIAL PRIMARY KEY,
+		code_id BIGINT NOT NULL REFERENCES promo_codes(id),
+		user_id BIGINT NOT NULL REFERENCES users(id),
+		order_id BIGINT NOT NULL UNIQUE REFERENCES orders(id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX IF NOT EXISTS promo_redemptions_code_user_idx ON promo_redemptions(code_id, user_id);
+	ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount BIGINT NOT NULL DEFAULT 0;
+	ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code TEXT NOT NULL DEFAULT '';`
+	if _, err := DB.Exec(createPromoCodes); err != nil {
+		log.Fatal("Could not create promo code tables:", err)
+	}
+
+	// 10) 報到：票券裡是報名 id，掃票時記錄時間與掃票的人
+	checkInRegistrations := `
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ;
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS checked_in_by BIGINT REFERENCES users(id);`
+	if _, err := DB.Exec(checkInRegistrations); err != nil {
+		log.Fatal("Could not add registrations check-in columns:", err)
+	}
+
+	// 11) 報名表單的答案（有票種的事件先存在訂單，付款成功後帶到報名）
+	answerColumns := `
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS answers JSONB;
+	ALTER TABLE orders ADD COLUMN IF NOT EXISTS answers JSONB;`
+	if _, err := DB.Exec(answerColumns); err != nil {
+		log.Fatal("Could not add answers columns:", err)
+	}
+
+	// 12) RSVP：going / maybe / not_going，+N 同行者；capacity 只算 going + 同行者
+	rsvpColumns := `
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS rsvp TEXT NOT NULL DEFAULT 'going';
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS guests INT NOT NULL DEFAULT 0;
+	ALTER TABLE registrations ADD COLUMN IF NOT EXISTS responded_at TIMESTAMPTZ NOT NULL DEFAULT now();`
+	if _, err := DB.Exec(rsvpColumns); err != nil {
+		log.Fatal("Could not add registrations RSVP columns:", err)
+	}
+
+	// 13) 轉讓與團體報名：交給某個 email 的名額，等對方接受；團體報名替沒有帳號的人保留的名額也佔 capacity
+	createTransfers := `
+	CREATE TABLE IF NOT EXISTS registration_transfers (
+		id BIGSERIAL PRIMARY KEY,
+		org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id),
+		kind TEXT NOT NULL,
+		event_id UUID NOT NULL,
+		occurrence TEXT NOT NULL DEFAULT '',
+		registration_id BIGINT,
+		from_user_id BIGINT NOT NULL REFERENCES users(id),
+		email TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		resolved_at TIMESTAMPTZ
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS registration_transfers_pending_key ON registration_transfers(registration_id) WHERE kind = 'transfer' AND status = 'pending';
+	CREATE UNIQUE INDEX IF NOT EXISTS registration_transfers_held_key ON registration_transfers(event_id, occurrence, email) WHERE kind = 'group' AND status = 'pending';
+	CREATE INDEX IF NOT EXISTS registration_transfers_email_idx ON registration_transfers(email) WHERE status = 'pending';`
+	if _, err := DB.Exec(createTransfers); err != nil {
+		log.Fatal("Could not create registration_transfers table:", err)
+	}
+
+	// 14) 行事曆訂閱：每個使用者一個 feed token（只存雜湊），撤銷就是刪掉這一列
+	createCalendarFeeds := `
+	CREATE TABLE IF NOT EXISTS calendar_feeds (
+		org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id),
+		user_id BIGINT NOT NULL REFERENCES users(id),
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (org_id, user_id)
+	);`
+	if _, err := DB.Exec(createCalendarFeeds); err != nil {
+		log.Fatal("Could not create calendar_feeds table:", err)
+	}
 }
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"restapi/models"
+)
+
+// ScheduledPublisher 定期把到時間的 scheduled 事件改成 published
+type ScheduledPublisher struct {
+	repo      models.EventRepository
+	revisions models.RevisionRepository // 每次發布記一筆修改歷程（操作者 0 = 系統），可為 nil
+	interval  time.Duration
+	onPublish func(models.Event) // 發布後的收尾（例如清快取），可為 nil
+	now       func() time.Time
+}
+
+func NewScheduledPublisher(repo models.EventRepository, revisions models.RevisionRepository, interval time.Duration, onPublish func(models.Event)) *ScheduledPublisher {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ScheduledPublisher{repo: repo, revisions: revisions, interval: interval, onPublish: onPublish, now: time.Now}
+}
+
+// RunOnce 發布一次，回傳這次發布的事件（發布後的內容）
+func (p *ScheduledPublisher) RunOnce() ([]models.Event, error) {
+	due, err := p.repo.PublishDue(p.now())
+	published := make([]models.Event, 0, len(due))
+	for _, before := range due {
+		after := before
+		after.Status, after.PublishAt, after.Version = models.StatusPublished, nil, before.Version+1
+		p.recordRevision(before, after)
+		if p.onPublish != nil {
+			p.onPublish(after)
+		}
+		published = append(published, after)
+	}
+	return published, err
+}
+
+// recordRevision 跟 API 的修改一樣記一筆 update（routes/history.go），事件在哪個組織就記在哪個組織
+func (p *ScheduledPublisher) recordRevision(before, after models.Event) {
+	if p.revisions == nil {
+		return
+	}
+	changes := models.DiffEvents(before, after)
+	delete(changes, "version")
+	snap := after
+	rev := models.Revision{
+		EventID:  after.ID,
+		Rev:      after.Version,
+		Action:   models.RevisionUpdate,
+		UserID:   0,
+		At:       p.now().UTC(),
+		Changes:  changes,
+		Snapshot: &snap,
+	}
+	if err := p.revisions.InOrg(models.OrgOf(after.OrgID)).Append(&rev); err != nil {
+		log.Printf("record revision %s@%d: %v", after.ID, after.Version, err)
+	}
+}
+
+func (p *ScheduledPublisher) Start() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			if evs, err := p.RunOnce(); err != nil {
+				log.Println("scheduled publish error:", err)
+			} else if len(evs) > 0 {
+				log.Printf("scheduled publish: published %d events", len(evs))
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"restapi/models"
+)
+
+// TrashPurger 定期把垃圾桶裡超過保留期限的事件真正刪除
+type TrashPurger struct {
+	repo      models.EventRepository
+	retention time.Duration // 軟刪除後保留多久
+	interval  time.Duration // 多久掃一次
+	now       func() time.Time
+}
+
+func NewTrashPurger(repo models.EventRepository, retention, interval time.Duration) *TrashPurger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TrashPurger{repo: repo, retention: retention, interval: interval, now: time.Now}
+}
+
+// RunOnce 清一次，回傳刪掉幾筆
+func (p *TrashPurger) RunOnce() (int64, error) {
+	return p.repo.PurgeDeleted(p.now().Add(-p.retention))
+}
+
+// Start 啟動背景 goroutine（跟 RateLimiter 的清理一樣用 ticker），回傳 stop
+func (p *TrashPurger) Start() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			if n, err := p.RunOnce(); err != nil {
+				log.Println("trash purge error:", err)
+			} else if n > 0 {
+				log.Printf("trash purge: removed %d events", n)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
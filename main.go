@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 
+	"restapi/jobs"
 	"restapi/middlewares"
 	"restapi/models"
 	"restapi/routes"
@@ -47,6 +49,7 @@ func main() {
 	defer func() { _ = mg.Disconnect(context.Background()) }()
 
 	eventsCol := mg.Database("app").Collection("events")
+	revisionsCol := mg.Database("app").Collection("event_revisions")
 
 	// Redis
 	redisAddr := os.Getenv("REDIS_ADDR")
@@ -61,17 +64,71 @@ func main() {
 	inv := utils.NewCacheInvalidator(rdb)
 
 	// Gin + middlewares
+	orgRepo := models.NewSQLOrganizationRepository(sqldb)
 	server := gin.Default()
+	server.Use(middlewares.RequestID()) // problem+json 的 instance 用它
+	// 多租戶：子網域（TENANT_BASE_DOMAIN=events.example.com → acme.events.example.com）/ X-Tenant / token 的 orgId
+	// 要在 ResponseCache 之前，快取 key 才分得出組織
+	server.Use(middlewares.Tenant(middlewares.TenantConfig{Orgs: orgRepo, BaseDomain: os.Getenv("TENANT_BASE_DOMAIN")}))
 	server.Use(middlewares.ResponseCache(rdb, 30*time.Second))
 
+	eventRepo := models.NewMongoEventRepository(eventsCol)
+	revisionRepo := models.NewMongoRevisionRepository(revisionsCol)
+
+	// 垃圾桶定期清除（預設保留 30 天，可用 TRASH_RETENTION=72h 調整）
+	purger := jobs.NewTrashPurger(eventRepo, durationFromEnv("TRASH_RETENTION", 30*24*time.Hour), time.Hour)
+	stopPurger := purger.Start()
+	defer stopPurger()
+
+	// 排程發布：每分鐘把到時間的 scheduled 事件發布、記修改歷程，並清掉列表快取
+	publisher := jobs.NewScheduledPublisher(eventRepo, revisionRepo, time.Minute, func(e models.Event) {
+		inv.PurgeEventsList(context.Background())
+		inv.PurgeEventItem(context.Background(), e.ID)
+	})
+	stopPublisher := publisher.Start()
+	defer stopPublisher()
+
+	// 金流：目前只有本機 provider（付款立即成功，不碰真的錢）；接正式金流時換掉這裡的 PaymentProvider
+	paymentSecret := os.Getenv("LOCAL_PAYMENT_SECRET")
+	if paymentSecret == "" {
+		paymentSecret = "dev-local-payment-secret"
+	}
+
 	// Routes
 	routes.RegisterRoutes(server, 
 		models.NewSQLUserRepository(sqldb), 
 		models.NewSQLRegistrationRepository(sqldb), 
-		models.NewMongoEventRepository(eventsCol), 
-		rdb, inv)
+		eventRepo, 
+		rdb, inv,
+		routes.WithPasswordPolicy(passwordPolicyFromEnv()),
+		routes.WithRevisions(revisionRepo),
+		routes.WithSearchIndex(models.NewMongoSearchIndex(eventsCol)),
+		routes.WithInvitations(models.NewSQLInvitationRepository(sqldb)),
+		routes.WithOrganizations(orgRepo),
+		routes.WithPayments(models.NewSQLOrderRepository(sqldb), models.NewLocalPaymentProvider(paymentSecret)),
+		routes.WithPromoCodes(models.NewSQLPromoRepository(sqldb)),
+		routes.WithCalendarFeeds(models.NewSQLCalendarFeedRepository(sqldb)))
 
 	if err := server.Run(":8080"); err != nil {
 		log.Fatal("gin.Run error:", err)
 	}
 }
+
+// 密碼規則可用環境變數調整：PASSWORD_MIN_LENGTH、PASSWORD_REQUIRE_SYMBOL=true
+func passwordPolicyFromEnv() models.PasswordPolicy {
+	p := models.DefaultPasswordPolicy
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && n > 0 {
+		p.MinLength = n
+	}
+	if b, err := strconv.ParseBool(os.Getenv("PASSWORD_REQUIRE_SYMBOL")); err == nil {
+		p.RequireSymbol = b
+	}
+	return p
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
@@ -10,16 +10,27 @@ import (
 func Authenticate(context *gin.Context) {
 	token := context.Request.Header.Get("Authorization")
 	if token == "" {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorized"})
+		utils.AbortWithProblem(context, http.StatusUnauthorized, utils.CodeUnauthorized, "Missing Authorization header.")
 		return
 	}
 
 	userId, err := utils.VerifyToken(token)
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not authorized."})
+		utils.AbortWithProblem(context, http.StatusUnauthorized, utils.CodeUnauthorized, "Invalid or expired token.")
 		return
 	}
 
 	context.Set("userId", userId)
 	context.Next()
 }
+
+// OptionalAuthenticate 用在公開路由：有合法 token 就放 userId，沒有或無效都當訪客放行
+// （例如擁有者可以看自己的草稿）
+func OptionalAuthenticate(context *gin.Context) {
+	if token := context.Request.Header.Get("Authorization"); token != "" {
+		if userId, err := utils.VerifyToken(token); err == nil {
+			context.Set("userId", userId)
+		}
+	}
+	context.Next()
+}
@@ -6,6 +6,8 @@ import (
 	"crypto/sha1"
 	"encoding/gob"
 	"encoding/hex"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,16 +38,35 @@ func CacheKeyFrom(c *gin.Context) (string, string) {
 	if method != "GET" || path == "" {
 		return "", ""
 	}
+	// 帶 Authorization 的回應因人而異（例如 /events/trash），共用快取不能存
+	// 分享 token 同理（private 事件），連查都不查，避免跟別人共用同一份
+	if c.GetHeader("Authorization") != "" || c.Query("share") != "" || c.GetHeader("X-Share-Token") != "" {
+		return "", ""
+	}
+	// 個人行事曆訂閱（/calendar/:token）：token 就是憑證，key 又不含 token，絕對不能共用
+	if strings.HasPrefix(path, "/calendar/") {
+		return "", ""
+	}
+
+	// 多租戶：同一個路徑在不同組織是不同內容（Tenant middleware 放的 orgId；沒裝 → 0）
+	org := strconv.FormatInt(c.GetInt64("orgId"), 10)
 
 	switch {
-	case strings.HasPrefix(path, "/events/:id"):
+	case path == "/events/:id":
 		id := c.Param("id")
-		return "cache:events:item:" + sha1Hex("GET|/events/"+id), "item"  // cache:events:item:abcd1234...
-	case strings.HasPrefix(path, "/events"):
-		return "cache:events:list:" + sha1Hex("GET|/events|"+rawq), "list"
+		if rawq != "" { // ?occurrence= 的 RSVP 統計不同，要分開存（仍在 item 命名空間，PurgeEventItem 一起清）
+			id += "|" + rawq
+		}
+		return "cache:events:item:" + org + ":" + sha1Hex("GET|/events/"+id), "item"  // cache:events:item:1:abcd1234...
+	case strings.HasPrefix(path, "/events/:id/"): // /events/:id/occurrences、/tickets：每個事件各一份，編輯時跟著 PurgeEventItem 清掉
+		return "cache:events:item:" + org + ":" + sha1Hex("GET|"+path+"|"+c.Param("id")+"|"+rawq), "item"
+	case path == "/events":
+		return "cache:events:list:" + org + ":" + sha1Hex("GET|/events|"+rawq), "list"
+	case path == "/events/search", path == "/events/facets": // 搜尋結果、分類統計跟列表一樣：任何事件寫入都可能改變，跟著 PurgeEventsList 清掉
+		return "cache:events:list:" + org + ":" + sha1Hex("GET|"+path+"|"+rawq), "list"
 	default:
 		// 其他 GET 也想快取可以在這加
-		return "cache:generic:" + sha1Hex(method+"|"+path+"|"+rawq), "generic"
+		return "cache:generic:" + org + ":" + sha1Hex(method+"|"+path+"|"+rawq), "generic"
 	}
 }
 
@@ -62,6 +83,9 @@ func ResponseCache(rdb *redis.Client, ttl time.Duration) gin.HandlerFunc {
 			var hit cachedBody
 			if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&hit); err == nil { // 把 Redis 裡存的快取資料解碼回 hit 變數 //把「Redis 中的快取資料」轉回 Go 的物件
 				for k, vals := range hit.Header {
+					if k == http.CanonicalHeaderKey(RequestIDHeader) { // 這次請求的 id 已經由 RequestID() 設好，不能換成被快取那次的
+						continue
+					}
 					for _, v := range vals {
 						c.Writer.Header().Add(k, v)   //還原 API 回應時的 Handler
 					}
@@ -70,7 +94,8 @@ func ResponseCache(rdb *redis.Client, ttl time.Duration) gin.HandlerFunc {
 				c.Writer.Header().Set("X-Cache", "HIT")
 				c.Status(hit.Status)  //還原 HTTP 狀態碼
 				_, _ = c.Writer.Write(hit.Body)  //還原 Response Body   
-				return  //有快取 不呼叫 c.Next() 
+				c.Abort() //有快取 後面的 handler 不能再跑（否則 body 會再寫一次）
+				return
 			}
 		}
 
@@ -82,7 +107,8 @@ func ResponseCache(rdb *redis.Client, ttl time.Duration) gin.HandlerFunc {
 		c.Next() //來去存回應
 
 		// 只快取 2xx  //回應回來了 把 buf 存 Redis做後續收尾
-		if bw.Status() >= 200 && bw.Status() < 300 {
+		// handler 標了 Cache-Control: private / no-store（例如非 public 事件）就不存
+		if bw.Status() >= 200 && bw.Status() < 300 && cacheable(c.Writer.Header().Get("Cache-Control")) {
 			item := cachedBody{
 				Status: bw.Status(),
 				Header: c.Writer.Header(),
@@ -99,6 +125,11 @@ func ResponseCache(rdb *redis.Client, ttl time.Duration) gin.HandlerFunc {
 	}
 }
 
+func cacheable(cacheControl string) bool {
+	cc := strings.ToLower(cacheControl)
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
+
 type bufferedWriter struct{ gin.ResponseWriter; buf *bytes.Buffer }
 
 func (w *bufferedWriter) Write(b []byte) (int, error) {
@@ -3,10 +3,13 @@ package middlewares
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+
+	"restapi/utils"
 )
 
 type QuotaRule struct {
@@ -37,9 +40,8 @@ func Quota(rdb *redis.Client, rule QuotaRule) gin.HandlerFunc {
 			_ = rdb.Expire(ctx, key, rule.Window).Err()  //告訴 Redis「這個 key 再過 duration 時間就自動刪掉
 		}
 		if int(n) > rule.Limit {
-			c.AbortWithStatusJSON(429, gin.H{
-				"message": "Usage quota exceeded. Please try again later.",
-			})
+			utils.AbortWithProblem(c, http.StatusTooManyRequests, utils.CodeQuotaExceeded,
+				"Usage quota exceeded. Please try again later.")
 			return
 		}
 		c.Header("X-Quota-Used", fmt.Sprintf("%d/%d", n, rule.Limit))  //X-Quota-Used: 5/100
@@ -8,6 +8,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
+
+	"restapi/utils"
 )
 
 // 限速器設定
@@ -90,9 +92,8 @@ func (rl *RateLimiter) Middleware(selectKey KeySelector) gin.HandlerFunc {
 		if !lim.Allow() {
 			// 附上 Retry-After，這裡簡單回 1 秒
 			c.Header("Retry-After", "1")
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"message": "Too many requests. Please try again later.",
-			})
+			utils.AbortWithProblem(c, http.StatusTooManyRequests, utils.CodeRateLimited,
+				"Too many requests. Please try again later.")
 			return
 		}
 		c.Next()
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"restapi/utils"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// 只接受短的、安全字元的外部 request id，避免把任意字串塞進 log / 回應
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 為每個請求配一個 id：沿用 client 帶的 X-Request-ID，否則產生 UUID
+// 放進 context（utils.RequestIDKey）並回寫到回應 header，problem+json 的 instance 就是它
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set(utils.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
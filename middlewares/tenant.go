@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// TenantHeader 沒有子網域時（例如本機、內部呼叫）用 header 指定組織 slug
+const TenantHeader = "X-Tenant"
+
+type TenantConfig struct {
+	Orgs       models.OrganizationRepository
+	BaseDomain string // 例如 events.example.com → acme.events.example.com 的 tenant 是 acme；空 → 不看子網域
+}
+
+// Tenant 決定這個請求屬於哪個組織，放進 context 的 "orgId"（之後 repository 都以它過濾）
+// 順序：子網域 → X-Tenant header → JWT 的 orgId claim → 預設組織
+// 子網域 / header 指定的組織與 token 簽發的組織不同 → 403，token 只能在簽發的組織使用
+// 要放在 ResponseCache / Quota 之前，快取與配額的 key 才會帶到 tenant
+func Tenant(cfg TenantConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var explicit int64
+		if slug := tenantSlug(c, cfg.BaseDomain); slug != "" {
+			org, err := cfg.Orgs.GetBySlug(slug)
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				utils.AbortWithProblem(c, http.StatusNotFound, utils.CodeNotFound, "Unknown organization.")
+				return
+			case err != nil:
+				utils.AbortWithProblem(c, http.StatusInternalServerError, utils.CodeInternal, "Could not resolve organization.")
+				return
+			}
+			explicit = org.ID
+		}
+
+		var claimed int64
+		if token := c.GetHeader("Authorization"); token != "" {
+			if claims, err := utils.ParseToken(token); err == nil {
+				claimed = claims.OrgID
+				if claimed == 0 {
+					claimed = models.DefaultOrgID // 多租戶之前簽的 token
+				}
+			}
+		}
+
+		org := models.DefaultOrgID
+		switch {
+		case explicit != 0 && claimed != 0 && explicit != claimed:
+			utils.AbortWithProblem(c, http.StatusForbidden, utils.CodeForbidden, "Token was issued for another organization.")
+			return
+		case explicit != 0:
+			org = explicit
+		case claimed != 0:
+			org = claimed
+		}
+		c.Set("orgId", org)
+		c.Next()
+	}
+}
+
+func tenantSlug(c *gin.Context, baseDomain string) string {
+	if baseDomain != "" {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(host)
+		if sub, ok := strings.CutSuffix(host, "."+strings.ToLower(baseDomain)); ok && sub != "" && !strings.Contains(sub, ".") {
+			return sub
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(c.GetHeader(TenantHeader)))
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// 行事曆訂閱：行事曆 app 沒辦法帶 Authorization header，所以個人的 feed 用網址裡的 token 驗證。
+// 每個使用者（在每個組織）同時只有一個 token；重新產生或撤銷後舊網址立即失效。
+// 只存 token 的 SHA-256，資料庫外洩也拿不到可用的網址
+type CalendarFeed struct {
+	UserID    int64     `json:"userId"`
+	OrgID     int64     `json:"-"` // 由 repository 設定
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewCalendarToken 256 bits 亂數，URL-safe
+func NewCalendarToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CalendarTokenHash 存進資料庫、查詢時用的雜湊
+func CalendarTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,39 @@
+package models
+
+import "database/sql"
+
+// 每個使用者在每個組織一列：PRIMARY KEY(org_id, user_id)，token_hash UNIQUE
+type sqlCalendarFeedRepo struct {
+    db  *sql.DB
+    org int64
+}
+
+func NewSQLCalendarFeedRepository(db *sql.DB) CalendarFeedRepository {
+    return &sqlCalendarFeedRepo{db: db}
+}
+
+func (r *sqlCalendarFeedRepo) InOrg(orgID int64) CalendarFeedRepository {
+    return &sqlCalendarFeedRepo{db: r.db, org: orgID}
+}
+
+func (r *sqlCalendarFeedRepo) Rotate(userID int64, tokenHash string) (CalendarFeed, error) {
+    f := CalendarFeed{UserID: userID, OrgID: insertOrg(r.org)}
+    err := r.db.QueryRow(`INSERT INTO calendar_feeds(org_id, user_id, token_hash) VALUES ($1,$2,$3)
+        ON CONFLICT (org_id, user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = now()
+        RETURNING created_at`, f.OrgID, userID, tokenHash).Scan(&f.CreatedAt)
+    return f, mapSQLErr(err)
+}
+
+func (r *sqlCalendarFeedRepo) Revoke(userID int64) error {
+    res, err := r.db.Exec(`DELETE FROM calendar_feeds WHERE user_id=$1 AND ($2::bigint = 0 OR org_id = $2)`, userID, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 { return ErrNotFound }
+    return nil
+}
+
+func (r *sqlCalendarFeedRepo) ByToken(tokenHash string) (CalendarFeed, error) {
+    var f CalendarFeed
+    err := r.db.QueryRow(`SELECT org_id, user_id, created_at FROM calendar_feeds WHERE token_hash=$1 AND ($2::bigint = 0 OR org_id = $2)`,
+        tokenHash, r.org).Scan(&f.OrgID, &f.UserID, &f.CreatedAt)
+    return f, mapSQLErr(err)
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 領域錯誤（sentinel）：Repository 一律把底層 driver 錯誤轉成這幾種，
+// routes 再統一對應到 HTTP 狀態碼，handler 不需要認得 mongo / pq 的錯誤。
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrForbidden  = errors.New("forbidden")
+	ErrValidation = errors.New("validation failed")
+
+	// 帳密錯誤（不分帳號不存在或密碼錯）
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// 金流拒絕付款 / 退款（卡片被拒、餘額不足…）
+	ErrPaymentFailed = errors.New("payment failed")
+)
+
+// ValidationError 帶有欄位層級的錯誤訊息（field → message）
+// errors.Is(err, ErrValidation) 會成立
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func NewValidationError() *ValidationError {
+	return &ValidationError{Fields: map[string]string{}}
+}
+
+// Add 記錄一個欄位錯誤；同一欄位只保留第一個
+func (e *ValidationError) Add(field, msg string) {
+	if _, ok := e.Fields[field]; !ok {
+		e.Fields[field] = msg
+	}
+}
+
+// Merge 併入另一個 ValidationError 的欄位（nil 或其他錯誤忽略）
+func (e *ValidationError) Merge(err error) {
+	var other *ValidationError
+	if errors.As(err, &other) {
+		for f, msg := range other.Fields {
+			e.Add(f, msg)
+		}
+	}
+}
+
+// OrNil 沒有任何欄位錯誤時回 nil，方便 `return v.OrNil()`
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+e.Fields[k])
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// VersionConflictError 條件寫入時版本不符；Current 是目前資料庫裡的版本
+// errors.Is(err, ErrConflict) 會成立
+type VersionConflictError struct {
+	Current Event
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s: event %s is at version %d", ErrConflict, e.Current.ID, e.Current.Version)
+}
+
+func (e *VersionConflictError) Is(target error) bool { return target == ErrConflict }
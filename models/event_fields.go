@@ -0,0 +1,68 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// eventField 對應 Event 的一個欄位：API 用 json 名稱，Mongo 用 bson 名稱
+type eventField struct {
+	json  string
+	bson  string
+	index int
+}
+
+// 由 struct tag 推出欄位表；沒有 bson tag 時 driver 預設用小寫欄位名（DateTime → datetime）
+var eventFields = func() []eventField {
+	t := reflect.TypeOf(Event{})
+	out := make([]eventField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		bsonName := strings.Split(f.Tag.Get("bson"), ",")[0]
+		if bsonName == "" {
+			bsonName = strings.ToLower(f.Name)
+		}
+		out = append(out, eventField{json: name, bson: bsonName, index: i})
+	}
+	return out
+}()
+
+func lookupEventField(jsonName string) (eventField, bool) {
+	for _, f := range eventFields {
+		if f.json == jsonName {
+			return f, true
+		}
+	}
+	return eventField{}, false
+}
+
+// FieldChange 單一欄位的前後值
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// DiffEvents 逐欄比較兩個事件，回傳有變動的欄位（key 為 json 名稱）
+func DiffEvents(old, updated Event) map[string]FieldChange {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(updated)
+	out := map[string]FieldChange{}
+	for _, f := range eventFields {
+		a, b := ov.Field(f.index).Interface(), nv.Field(f.index).Interface()
+		if !fieldEqual(a, b) {
+			out[f.json] = FieldChange{From: a, To: b}
+		}
+	}
+	return out
+}
+
+func fieldEqual(a, b any) bool {
+	if ta, ok := a.(time.Time); ok {
+		return ta.Equal(b.(time.Time))
+	}
+	return reflect.DeepEqual(a, b)
+}
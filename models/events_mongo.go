@@ -3,6 +3,8 @@ package models
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,17 +15,128 @@ import (
 
 type mongoEventRepo struct {
     col *mongo.Collection
+    org int64 // 0 → 不限組織（背景工作）
 }
 
 func NewMongoEventRepository(col *mongo.Collection) EventRepository {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    // 附近 / 地圖搜尋；沒有 geo 的文件不會進索引
+    _, _ = col.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "geo", Value: "2dsphere"}}})
+    // tags 是陣列 → multikey index
+    _, _ = col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "tags", Value: 1}}},
+        {Keys: bson.D{{Key: "category", Value: 1}}},
+        {Keys: bson.D{{Key: "orgid", Value: 1}, {Key: "datetime", Value: 1}}},
+    })
     return &mongoEventRepo{col: col}
 }
 
-func (r *mongoEventRepo) GetAll() ([]Event, error) {
+func (r *mongoEventRepo) InOrg(orgID int64) EventRepository {
+    return &mongoEventRepo{col: r.col, org: orgID}
+}
+
+// orgScope 把租戶條件加進 filter；預設組織也包含沒有 orgid 的舊資料
+func orgScope(filter bson.M, org int64) bson.M {
+    switch org {
+    case 0:
+    case DefaultOrgID:
+        filter["orgid"] = bson.M{"$in": bson.A{DefaultOrgID, nil}}
+    default:
+        filter["orgid"] = org
+    }
+    return filter
+}
+
+func (r *mongoEventRepo) scope(filter bson.M) bson.M { return orgScope(filter, r.org) }
+
+func (r *mongoEventRepo) GetAll(f EventFilter) ([]Event, error) {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
+    return r.find(ctx, r.scope(listedFilter(f)))
+}
+
+// listedFilter 公開列表（GetAll）與全文搜尋共用的查詢條件
+func listedFilter(f EventFilter) bson.M {
+    // nil 同時符合 null 與欄位不存在（舊資料沒有 status → 視為已發布、沒有 visibility → public）
+    filter := bson.M{
+        "deletedat":  nil,
+        "status":     bson.M{"$in": bson.A{StatusPublished, "", nil}},
+        "visibility": bson.M{"$in": bson.A{VisibilityPublic, "", nil}},
+    }
+    if f.To != nil {
+        filter["datetime"] = bson.M{"$lte": *f.To}
+    }
+    if f.From != nil {
+        // 重複事件只要系列在範圍結束前開始就可能有發生，交給呼叫端展開
+        filter["$or"] = bson.A{
+            bson.M{"recurrence": nil, "datetime": bson.M{"$gte": *f.From}},
+            bson.M{"recurrence": bson.M{"$ne": nil}},
+        }
+    }
+    if f.Category != "" {
+        filter["category"] = f.Category
+    }
+    if len(f.Tags) > 0 {
+        filter["tags"] = bson.M{"$all": f.Tags}
+    }
+    if f.Near != nil {
+        // $nearSphere 走 2dsphere 索引，結果已依距離排序
+        filter["geo"] = bson.M{"$nearSphere": bson.M{
+            "$geometry":    bson.M{"type": "Point", "coordinates": bson.A{f.Near.Lng, f.Near.Lat}},
+            "$maxDistance": f.Near.RadiusKm * 1000, // 公尺
+        }}
+    }
+    if f.Box != nil {
+        b := f.Box
+        within := bson.M{"$geoWithin": bson.M{"$geometry": bson.M{"type": "Polygon", "coordinates": bson.A{bson.A{
+            bson.A{b.MinLng, b.MinLat}, bson.A{b.MaxLng, b.MinLat}, bson.A{b.MaxLng, b.MaxLat},
+            bson.A{b.MinLng, b.MaxLat}, bson.A{b.MinLng, b.MinLat},
+        }}}}}
+        if f.Near != nil {
+            // 同一欄位不能同時放兩個地理運算子，改用 $and
+            filter["$and"] = bson.A{bson.M{"geo": within}}
+        } else {
+            filter["geo"] = within
+        }
+    }
+    return filter
+}
+
+// Facets 用 $facet 一次算出標籤與分類的數量
+// （aggregation 的 $match 不能用 $nearSphere，所以只帶日期 / 分類 / 標籤條件）
+func (r *mongoEventRepo) Facets(f EventFilter) (Facets, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
 
-    cur, err := r.col.Find(ctx, bson.M{})
+    f.Near, f.Box = nil, nil
+    count := func(field string) bson.A {
+        return bson.A{
+            bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+            bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+            bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+        }
+    }
+    pipeline := mongo.Pipeline{
+        {{Key: "$match", Value: r.scope(listedFilter(f))}},
+        {{Key: "$facet", Value: bson.M{
+            "tags":       append(bson.A{bson.M{"$unwind": "$tags"}}, count("tags")...),
+            "categories": append(bson.A{bson.M{"$match": bson.M{"category": bson.M{"$nin": bson.A{"", nil}}}}}, count("category")...),
+        }}},
+    }
+    cur, err := r.col.Aggregate(ctx, pipeline)
+    if err != nil { return Facets{}, mapMongoErr(err) }
+    defer cur.Close(ctx)
+
+    out := Facets{Tags: []FacetCount{}, Categories: []FacetCount{}}
+    if cur.Next(ctx) {
+        if err := cur.Decode(&out); err != nil { return Facets{}, err }
+    }
+    return out, cur.Err()
+}
+
+func (r *mongoEventRepo) find(ctx context.Context, filter bson.M) ([]Event, error) {
+    cur, err := r.col.Find(ctx, filter)
     if err != nil { return nil, err }
     defer cur.Close(ctx)
 
@@ -41,9 +154,8 @@ func (r *mongoEventRepo) GetByID(id string) (Event, error) {
     defer cancel()
 
     var e Event
-    if err := r.col.FindOne(ctx, bson.M{"id": id}).Decode(&e); err != nil {
-        if errors.Is(err, mongo.ErrNoDocuments) { return Event{}, err }
-        return Event{}, err
+    if err := r.col.FindOne(ctx, r.scope(bson.M{"id": id, "deletedat": nil})).Decode(&e); err != nil {
+        return Event{}, mapMongoErr(err)
     }
     return e, nil
 }
@@ -51,20 +163,172 @@ func (r *mongoEventRepo) GetByID(id string) (Event, error) {
 func (r *mongoEventRepo) Create(e *Event) error {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
+    if e.Version == 0 { e.Version = 1 }
+    if r.org != 0 { e.OrgID = r.org }
     _, err := r.col.InsertOne(ctx, e)
-    return err
+    return mapMongoErr(err)
+}
+
+func (r *mongoEventRepo) CreateMany(es []Event) (int, error) {
+    if len(es) == 0 { return 0, nil }
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+    docs := make([]any, len(es))
+    for i := range es {
+        if es[i].Version == 0 { es[i].Version = 1 }
+        if r.org != 0 { es[i].OrgID = r.org }
+        docs[i] = es[i]
+    }
+    _, err := r.col.InsertMany(ctx, docs) // 預設 ordered：遇到錯誤就停，之後的不寫
+    if err == nil { return len(es), nil }
+    n := 0 // 連線錯誤之類：不確定寫了多少，當作沒有
+    var bulk mongo.BulkWriteException
+    if errors.As(err, &bulk) && len(bulk.WriteErrors) > 0 {
+        n = bulk.WriteErrors[0].Index // 第一個錯誤之前的都寫入了
+    }
+    return n, mapMongoErr(err)
 }
 
 func (r *mongoEventRepo) Update(e *Event) error {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
-    _, err := r.col.UpdateOne(ctx, bson.M{"id": e.ID}, bson.M{"$set": e})
-    return err
+
+    expected := e.Version
+    e.Version = expected + 1
+    if r.org != 0 { e.OrgID = r.org } // 不能把事件搬到別的組織
+    res, err := r.col.UpdateOne(ctx, r.scope(versionFilter(e.ID, expected)), bson.M{"$set": e})
+    if err != nil { e.Version = expected; return mapMongoErr(err) }
+    if res.MatchedCount == 0 { e.Version = expected; return r.conflictOrNotFound(e.ID) }
+    return nil
+}
+
+// Patch 只 $set 有變動的欄位，不會把其他欄位蓋成零值
+func (r *mongoEventRepo) Patch(e *Event, fields []string) error {
+    set := bson.M{}
+    ev := reflect.ValueOf(*e)
+    for _, name := range fields {
+        f, ok := lookupEventField(name)
+        if !ok {
+            v := NewValidationError()
+            v.Add(name, "unknown field")
+            return v
+        }
+        set[f.bson] = ev.Field(f.index).Interface()
+    }
+    if len(set) == 0 { return nil }
+    set["version"] = e.Version + 1
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    res, err := r.col.UpdateOne(ctx, r.scope(versionFilter(e.ID, e.Version)), bson.M{"$set": set})
+    if err != nil { return mapMongoErr(err) }
+    if res.MatchedCount == 0 { return r.conflictOrNotFound(e.ID) }
+    e.Version++
+    return nil
+}
+
+// Delete 軟刪除：設 deletedat，資料保留到 PurgeDeleted 清掉為止
+func (r *mongoEventRepo) Delete(id string, version int64) error {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    res, err := r.col.UpdateOne(ctx, r.scope(versionFilter(id, version)),
+        bson.M{"$set": bson.M{"deletedat": time.Now().UTC(), "version": version + 1}})
+    if err != nil { return mapMongoErr(err) }
+    if res.MatchedCount == 0 { return r.conflictOrNotFound(id) }
+    return nil
+}
+
+/* -------------------- 垃圾桶 -------------------- */
+
+func (r *mongoEventRepo) ListDeleted(ownerID int64) ([]Event, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    filter := bson.M{"deletedat": bson.M{"$ne": nil}}
+    if ownerID != 0 { filter["userid"] = ownerID }
+    return r.find(ctx, r.scope(filter))
+}
+
+func (r *mongoEventRepo) GetDeleted(id string) (Event, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    var e Event
+    if err := r.col.FindOne(ctx, r.scope(bson.M{"id": id, "deletedat": bson.M{"$ne": nil}})).Decode(&e); err != nil {
+        return Event{}, mapMongoErr(err)
+    }
+    return e, nil
 }
 
-func (r *mongoEventRepo) Delete(id string) error {
+func (r *mongoEventRepo) Restore(id string, version int64) error {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
-    _, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+    filter := bson.M{"id": id, "version": version, "deletedat": bson.M{"$ne": nil}}
+    res, err := r.col.UpdateOne(ctx, r.scope(filter),
+        bson.M{"$set": bson.M{"deletedat": nil, "version": version + 1}})
+    if err != nil { return mapMongoErr(err) }
+    if res.MatchedCount == 0 {
+        cur, err := r.GetDeleted(id)
+        if err != nil { return err }
+        return &VersionConflictError{Current: cur}
+    }
+    return nil
+}
+
+func (r *mongoEventRepo) PurgeDeleted(before time.Time) (int64, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+    res, err := r.col.DeleteMany(ctx, r.scope(bson.M{"deletedat": bson.M{"$ne": nil, "$lt": before}}))
+    if err != nil { return 0, mapMongoErr(err) }
+    return res.DeletedCount, nil
+}
+
+// PublishDue 逐筆做條件更新（帶 version），避免跟使用者同時修改互相覆蓋
+func (r *mongoEventRepo) PublishDue(now time.Time) ([]Event, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+
+    due, err := r.find(ctx, r.scope(bson.M{"deletedat": nil, "status": StatusScheduled, "publishat": bson.M{"$lte": now}}))
+    if err != nil { return nil, err }
+
+    var out []Event
+    for _, e := range due {
+        res, err := r.col.UpdateOne(ctx, r.scope(bson.M{"id": e.ID, "version": e.Version, "status": StatusScheduled}),
+            bson.M{"$set": bson.M{"status": StatusPublished, "publishat": nil, "version": e.Version + 1}})
+        if err != nil { return out, mapMongoErr(err) }
+        if res.MatchedCount == 0 { continue } // 剛好被改掉，下次再看
+        out = append(out, e) // 發布前的內容
+    }
+    return out, nil
+}
+
+// versionFilter 以 id + 預期版本做條件寫入
+// 舊資料沒有 version 欄位，視為版本 0
+// 已軟刪除的事件不能再被修改（要先 Restore）
+func versionFilter(id string, version int64) bson.M {
+    if version == 0 {
+        return bson.M{"id": id, "deletedat": nil, "$or": bson.A{
+            bson.M{"version": 0},
+            bson.M{"version": bson.M{"$exists": false}},
+        }}
+    }
+    return bson.M{"id": id, "deletedat": nil, "version": version}
+}
+
+// conflictOrNotFound 條件寫入沒命中時，分辨是「不存在」還是「版本不符」
+func (r *mongoEventRepo) conflictOrNotFound(id string) error {
+    cur, err := r.GetByID(id)
+    if err != nil { return err }
+    return &VersionConflictError{Current: cur}
+}
+
+// mapMongoErr 把 driver 錯誤轉成領域錯誤；其他錯誤原樣回傳（→ 5xx）
+func mapMongoErr(err error) error {
+    switch {
+    case err == nil:
+        return nil
+    case errors.Is(err, mongo.ErrNoDocuments):
+        return ErrNotFound
+    case mongo.IsDuplicateKeyError(err):
+        return fmt.Errorf("%w: %v", ErrConflict, err)
+    }
     return err
 }
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// GeoPoint GeoJSON Point，coordinates 依 GeoJSON 規範是 [lng, lat]
+// 存進 Mongo 的 geo 欄位，配合 2dsphere 索引做 $nearSphere / $geoWithin
+type GeoPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+const earthRadiusKm = 6371.0
+
+// 附近搜尋的半徑限制（km）
+const (
+	DefaultNearRadiusKm = 10.0
+	MaxNearRadiusKm     = 500.0
+)
+
+func NewGeoPoint(lat, lng float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: [2]float64{lng, lat}}
+}
+
+func (p *GeoPoint) Lat() float64 { return p.Coordinates[1] }
+func (p *GeoPoint) Lng() float64 { return p.Coordinates[0] }
+
+// GeoNear 以 (Lat, Lng) 為中心、RadiusKm 為半徑
+type GeoNear struct {
+	Lat, Lng, RadiusKm float64
+}
+
+// GeoBox 地圖視窗（不處理跨換日線）
+type GeoBox struct {
+	MinLng, MinLat, MaxLng, MaxLat float64
+}
+
+func (b GeoBox) Contains(p *GeoPoint) bool {
+	return p != nil && p.Lng() >= b.MinLng && p.Lng() <= b.MaxLng && p.Lat() >= b.MinLat && p.Lat() <= b.MaxLat
+}
+
+// DistanceKm 球面距離（haversine）
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat, dLng := rad(lat2-lat1), rad(lng2-lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// DistanceFrom 事件到 (lat, lng) 的距離；沒有座標 → ok=false
+func (e *Event) DistanceFrom(lat, lng float64) (km float64, ok bool) {
+	if e.Geo == nil {
+		return 0, false
+	}
+	return DistanceKm(lat, lng, e.Geo.Lat(), e.Geo.Lng()), true
+}
+
+func validLatLng(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+func (e *Event) validateGeo(v *ValidationError) {
+	if e.Geo == nil {
+		return
+	}
+	if e.Geo.Type == "" {
+		e.Geo.Type = "Point"
+	}
+	if e.Geo.Type != "Point" {
+		v.Add("geo.type", "must be Point")
+	}
+	if !validLatLng(e.Geo.Lat(), e.Geo.Lng()) {
+		v.Add("geo.coordinates", "must be [lng, lat] within [-180,180] / [-90,90]")
+	}
+}
+
+// Validate 查詢條件本身的檢查（給 routes 解析 query 後用）
+func (n GeoNear) Validate() error {
+	v := NewValidationError()
+	if !validLatLng(n.Lat, n.Lng) {
+		v.Add("near", "must be lat,lng within [-90,90] / [-180,180]")
+	}
+	if n.RadiusKm <= 0 || n.RadiusKm > MaxNearRadiusKm {
+		v.Add("radius", fmt.Sprintf("must be greater than 0 and at most %g km", MaxNearRadiusKm))
+	}
+	return v.OrNil()
+}
+
+func (b GeoBox) Validate() error {
+	v := NewValidationError()
+	if !validLatLng(b.MinLat, b.MinLng) || !validLatLng(b.MaxLat, b.MaxLng) || b.MinLng >= b.MaxLng || b.MinLat >= b.MaxLat {
+		v.Add("bbox", "must be minLng,minLat,maxLng,maxLat with min < max")
+	}
+	return v.OrNil()
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 匯入事件（POST /events/import）：一列一個事件，驗證規則與建立事件相同。
+//   jsonl：JSON Lines，每行就是 POST /events 的 body；空白行略過
+//   csv：  第一列是欄位名稱（json 名稱），只支援純量欄位：
+//          name, description, location, category, tags（以 ; 分隔）, visibility,
+//          dateTime, endTime, publishAt（RFC 3339）, timeZone, status, capacity, lat, lng（兩者都有才設 geo）
+//          票種、報名表單、重複規則這類巢狀欄位請用 JSON Lines
+const (
+	ImportCSV       = "csv"
+	ImportJSONLines = "jsonl"
+)
+
+const (
+	MaxImportRows    = 5000
+	maxImportLineLen = 1 << 20 // JSON Lines 單行上限
+)
+
+var importCSVColumns = []string{"name", "description", "location", "category", "tags", "visibility",
+	"dateTime", "endTime", "publishAt", "timeZone", "status", "capacity", "lat", "lng"}
+
+// ImportRow 解析後的一列；Err 是這一列本身的格式錯誤（*ValidationError），事件規則另外驗證
+type ImportRow struct {
+	Line  int // 在來源檔的行號（CSV 的標題列是第 1 行），錯誤報告用
+	Event Event
+	Err   error
+}
+
+// ParseImport 讀完整個來源。整份檔案的問題（格式不明、CSV 欄位名稱不對、太多列）回 *ValidationError
+func ParseImport(r io.Reader, format string) ([]ImportRow, error) {
+	switch format {
+	case ImportCSV:
+		return parseImportCSV(r)
+	case ImportJSONLines:
+		return parseImportJSONLines(r)
+	}
+	v := NewValidationError()
+	v.Add("format", "must be csv or jsonl")
+	return nil, v
+}
+
+func tooManyImportRows() error {
+	v := NewValidationError()
+	v.Add("rows", fmt.Sprintf("at most %d rows per import", MaxImportRows))
+	return v
+}
+
+func parseImportJSONLines(r io.Reader) ([]ImportRow, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxImportLineLen)
+	var rows []ImportRow
+	for line := 1; sc.Scan(); line++ {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, tooManyImportRows()
+		}
+		row := ImportRow{Line: line}
+		if err := json.Unmarshal(b, &row.Event); err != nil {
+			v := NewValidationError()
+			v.Add("line", "is not a valid event JSON object")
+			row.Err = v
+		}
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		v := NewValidationError()
+		if errors.Is(err, bufio.ErrTooLong) {
+			v.Add("line", fmt.Sprintf("lines must be at most %d bytes", maxImportLineLen))
+			return nil, v
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+func parseImportCSV(r io.Reader) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, importCSVError(err)
+	}
+	v := NewValidationError()
+	seen := map[string]bool{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")) // Excel 存的 CSV 會帶 BOM
+		header[i] = name
+		field := fmt.Sprintf("header[%d]", i)
+		switch {
+		case !slices.Contains(importCSVColumns, name):
+			v.Add(field, "unknown column "+strconv.Quote(name)+" (use JSON Lines for nested fields)")
+		case seen[name]:
+			v.Add(field, "duplicate column "+strconv.Quote(name))
+		}
+		seen[name] = true
+	}
+	if !seen["name"] || !seen["dateTime"] {
+		v.Add("header", "name and dateTime columns are required")
+	}
+	if err := v.OrNil(); err != nil {
+		return nil, err
+	}
+
+	var rows []ImportRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, importCSVError(err)
+		}
+		if len(rows) == MaxImportRows {
+			return nil, tooManyImportRows()
+		}
+		line, _ := cr.FieldPos(0)
+		row := ImportRow{Line: line}
+		row.Err = row.Event.setCSVFields(header, rec)
+		rows = append(rows, row)
+	}
+}
+
+// 欄位數不對、引號沒關好：整份檔案都不可信，不逐列報告
+func importCSVError(err error) error {
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) {
+		return err
+	}
+	v := NewValidationError()
+	v.Add("csv", fmt.Sprintf("line %d: %v", pe.Line, pe.Err))
+	return v
+}
+
+func (e *Event) setCSVFields(header, rec []string) error {
+	v := NewValidationError()
+	parseTime := func(field, s string) *time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			v.Add(field, "must be an RFC 3339 time")
+			return nil
+		}
+		return &t
+	}
+	var lat, lng *float64
+	parseFloat := func(field, s string) *float64 {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			v.Add(field, "must be a number")
+			return nil
+		}
+		return &f
+	}
+	for i, name := range header {
+		s := strings.TrimSpace(rec[i])
+		if s == "" {
+			continue
+		}
+		switch name {
+		case "name":
+			e.Name = s
+		case "description":
+			e.Description = s
+		case "location":
+			e.Location = s
+		case "category":
+			e.Category = s
+		case "tags":
+			e.Tags = strings.Split(s, ";") // NormalizeTaxonomy 會去掉空白與重複
+		case "visibility":
+			e.Visibility = s
+		case "timeZone":
+			e.TimeZone = s
+		case "status":
+			e.Status = s
+		case "dateTime":
+			if t := parseTime(name, s); t != nil {
+				e.DateTime = *t
+			}
+		case "endTime":
+			e.EndTime = parseTime(name, s)
+		case "publishAt":
+			e.PublishAt = parseTime(name, s)
+		case "capacity":
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				v.Add(name, "must be an integer")
+			}
+			e.Capacity = n
+		case "lat":
+			lat = parseFloat(name, s)
+		case "lng":
+			lng = parseFloat(name, s)
+		}
+	}
+	switch {
+	case lat != nil && lng != nil:
+		e.Geo = &GeoPoint{Type: "Point", Coordinates: [2]float64{*lng, *lat}} // GeoJSON 是 [lng, lat]
+	case lat != nil || lng != nil:
+		v.Add("geo", "lat and lng must be given together")
+	}
+	return v.OrNil()
+}
@@ -0,0 +1,59 @@
+package models
+
+import "database/sql"
+
+// org=0 → 不限組織；每個查詢都帶 ($n = 0 OR org_id = $n)
+type sqlInvitationRepo struct {
+    db  *sql.DB
+    org int64
+}
+
+func NewSQLInvitationRepository(db *sql.DB) InvitationRepository {
+    return &sqlInvitationRepo{db: db}
+}
+
+func (r *sqlInvitationRepo) InOrg(orgID int64) InvitationRepository {
+    return &sqlInvitationRepo{db: r.db, org: orgID}
+}
+
+// insertOrg 新資料寫入的組織；不限組織時落在預設組織
+func insertOrg(org int64) int64 {
+    if org == 0 { return DefaultOrgID }
+    return org
+}
+
+func (r *sqlInvitationRepo) Create(inv *Invitation) error {
+    // UNIQUE(event_id, email) → 重複邀請回 ErrConflict
+    inv.OrgID = insertOrg(r.org)
+    err := r.db.QueryRow(`INSERT INTO event_invitations(event_id, email, invited_by, org_id) VALUES ($1,$2,$3,$4) RETURNING id, created_at`,
+        inv.EventID, inv.Email, inv.InvitedBy, inv.OrgID).Scan(&inv.ID, &inv.CreatedAt)
+    return mapSQLErr(err)
+}
+
+func (r *sqlInvitationRepo) List(eventID string) ([]Invitation, error) {
+    rows, err := r.db.Query(`SELECT id, event_id, email, invited_by, created_at FROM event_invitations
+        WHERE event_id=$1 AND ($2::bigint = 0 OR org_id = $2) ORDER BY id`, eventID, r.org)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := []Invitation{}
+    for rows.Next() {
+        var inv Invitation
+        if err := rows.Scan(&inv.ID, &inv.EventID, &inv.Email, &inv.InvitedBy, &inv.CreatedAt); err != nil { return nil, err }
+        out = append(out, inv)
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlInvitationRepo) Delete(eventID string, id int64) error {
+    res, err := r.db.Exec(`DELETE FROM event_invitations WHERE event_id=$1 AND id=$2 AND ($3::bigint = 0 OR org_id = $3)`, eventID, id, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 { return ErrNotFound }
+    return nil
+}
+
+func (r *sqlInvitationRepo) IsInvited(eventID, email string) (bool, error) {
+    var ok bool
+    err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM event_invitations WHERE event_id=$1 AND email=$2 AND ($3::bigint = 0 OR org_id = $3))`, eventID, email, r.org).Scan(&ok)
+    return ok, mapSQLErr(err)
+}
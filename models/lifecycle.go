@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// 事件生命週期
+//
+//	draft ──► scheduled ──(publishAt 到)──► published ──► completed
+//	  │  ◄──────┘ │                            │
+//	  └───────────┴──────────► cancelled ◄─────┘
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusCancelled = "cancelled"
+	StatusCompleted = "completed"
+)
+
+var transitions = map[string][]string{
+	StatusDraft:     {StatusScheduled, StatusPublished, StatusCancelled},
+	StatusScheduled: {StatusDraft, StatusPublished, StatusCancelled},
+	StatusPublished: {StatusCancelled, StatusCompleted},
+	StatusCancelled: {},
+	StatusCompleted: {},
+}
+
+// EffectiveStatus 舊資料沒有 status，視為已發布（維持原本「建立即公開」的行為）
+func (e *Event) EffectiveStatus() string {
+	if e.Status == "" {
+		return StatusPublished
+	}
+	return e.Status
+}
+
+// IsListed 是否出現在公開列表（只有已發布且 public）
+func (e *Event) IsListed() bool {
+	return e.EffectiveStatus() == StatusPublished && e.EffectiveVisibility() == VisibilityPublic
+}
+
+// IsPublic 是否可被任何人以 id 讀取；草稿與排程中只有擁有者看得到
+func (e *Event) IsPublic() bool {
+	switch e.EffectiveStatus() {
+	case StatusDraft, StatusScheduled:
+		return false
+	}
+	return true
+}
+
+// AcceptsRegistrations 只有已發布的事件可以報名
+func (e *Event) AcceptsRegistrations() bool { return e.EffectiveStatus() == StatusPublished }
+
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateInitialStatus 建立時只能是 draft / scheduled / published（預設 draft）
+func (e *Event) ValidateInitialStatus(now time.Time) error {
+	if e.Status == "" {
+		e.Status = StatusDraft
+	}
+	v := NewValidationError()
+	switch e.Status {
+	case StatusDraft, StatusPublished:
+		e.PublishAt = nil
+	case StatusScheduled:
+		if e.PublishAt == nil || !e.PublishAt.After(now) {
+			v.Add("publishAt", "must be in the future for scheduled events")
+		}
+	default:
+		v.Add("status", "must be one of draft, scheduled, published")
+	}
+	return v.OrNil()
+}
+
+// Transition 依狀態機改變 Status；不合法的轉換回 ErrConflict（狀態衝突）
+// 排程需要未來的 publishAt
+func (e *Event) Transition(to string, publishAt *time.Time, now time.Time) error {
+	from := e.EffectiveStatus()
+	if _, ok := transitions[to]; !ok {
+		v := NewValidationError()
+		v.Add("status", "unknown status")
+		return v
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: cannot change status from %s to %s", ErrConflict, from, to)
+	}
+	if to == StatusScheduled {
+		if publishAt == nil || !publishAt.After(now) {
+			v := NewValidationError()
+			v.Add("publishAt", "must be in the future")
+			return v
+		}
+		t := publishAt.UTC()
+		e.PublishAt = &t
+	} else {
+		e.PublishAt = nil
+	}
+	e.Status = to
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import "fmt"
+
+// 事件層級的角色：
+//   owner         Event.UserID；全部權限，包含刪除、管理成員、轉移擁有權
+//   co-organizer  編輯內容、改狀態、管理邀請、看報名名單
+//   checker       看報名名單、掃票報到
+// owner 不放在 Members 裡，由 Event.UserID 表示，避免兩邊不一致
+const (
+	MemberOwner       = "owner"
+	MemberCoOrganizer = "co-organizer"
+	MemberChecker     = "checker"
+)
+
+type EventMember struct {
+	UserID int64  `json:"userId"`
+	Role   string `json:"role"`
+}
+
+type Permission int
+
+const (
+	PermView          Permission = iota // 草稿、private 也能看
+	PermEdit                            // PUT / PATCH / 狀態轉換 / 單次例外 / 邀請 / 分享連結 / rollback
+	PermViewAttendees                   // 報名名單
+	PermDelete                          // 刪除（進垃圾桶）
+	PermManageMembers                   // 新增 / 移除成員、轉移擁有權
+	PermCheckIn                         // 掃票報到
+	PermExportAttendees                 // 匯出報名名單（含 email 與表單答案）；checker 只能在現場看名單，不能匯出
+)
+
+var rolePermissions = map[string][]Permission{
+	MemberOwner:       {PermView, PermEdit, PermViewAttendees, PermDelete, PermManageMembers, PermCheckIn, PermExportAttendees},
+	MemberCoOrganizer: {PermView, PermEdit, PermViewAttendees, PermCheckIn, PermExportAttendees},
+	MemberChecker:     {PermView, PermViewAttendees, PermCheckIn},
+}
+
+// RoleOf 使用者在這個事件的角色；不是成員 → ""
+func (e *Event) RoleOf(userID int64) string {
+	if userID == 0 {
+		return ""
+	}
+	if e.UserID == userID {
+		return MemberOwner
+	}
+	for _, m := range e.Members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// Can 使用者在這個事件有沒有權限 p
+func (e *Event) Can(userID int64, p Permission) bool {
+	for _, have := range rolePermissions[e.RoleOf(userID)] {
+		if have == p {
+			return true
+		}
+	}
+	return false
+}
+
+// MemberList 含 owner 的完整成員列表
+func (e *Event) MemberList() []EventMember {
+	out := []EventMember{{UserID: e.UserID, Role: MemberOwner}}
+	return append(out, e.Members...)
+}
+
+// AddMember 新增非 owner 成員；已是成員 → ErrConflict
+func (e *Event) AddMember(userID int64, role string) error {
+	if role != MemberCoOrganizer && role != MemberChecker {
+		v := NewValidationError()
+		v.Add("role", "must be co-organizer or checker")
+		return v
+	}
+	if e.RoleOf(userID) != "" {
+		return fmt.Errorf("%w: user %d is already a member", ErrConflict, userID)
+	}
+	e.Members = append(append([]EventMember(nil), e.Members...), EventMember{UserID: userID, Role: role})
+	return nil
+}
+
+// RemoveMember 移除非 owner 成員；owner 要先轉移擁有權
+func (e *Event) RemoveMember(userID int64) error {
+	if e.UserID == userID {
+		return fmt.Errorf("%w: transfer ownership before removing the owner", ErrConflict)
+	}
+	out := make([]EventMember, 0, len(e.Members))
+	for _, m := range e.Members {
+		if m.UserID != userID {
+			out = append(out, m)
+		}
+	}
+	if len(out) == len(e.Members) {
+		return fmt.Errorf("%w: user %d is not a member", ErrNotFound, userID)
+	}
+	e.Members = out
+	return nil
+}
+
+// TransferOwnership 新 owner 從 Members 移出，原 owner 降為 co-organizer
+func (e *Event) TransferOwnership(to int64) error {
+	if to == 0 || to == e.UserID {
+		v := NewValidationError()
+		v.Add("userId", "must be another user")
+		return v
+	}
+	members := []EventMember{{UserID: e.UserID, Role: MemberCoOrganizer}}
+	for _, m := range e.Members {
+		if m.UserID != to {
+			members = append(members, m)
+		}
+	}
+	e.UserID, e.Members = to, members
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// 訂單：購票報名先建立 pending 訂單佔住名額，付款成功（同步回應或 webhook）後才建立報名。
+//
+//	pending → paid → refunded
+//	pending → failed / cancelled / expired（釋出名額）
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderFailed    = "failed"
+	OrderCancelled = "cancelled" // 付款完成前使用者取消
+	OrderExpired   = "expired"   // 超過 ExpiresAt 仍未付款
+	OrderRefunded  = "refunded"
+)
+
+// OrderTTL pending 訂單佔位的時間；過期後不再計入已售數量
+const OrderTTL = 30 * time.Minute
+
+type Order struct {
+	ID           int64     `json:"id"`
+	OrgID        int64     `json:"-"` // 由 repository 設定
+	UserID       int64     `json:"userId"`
+	EventID      string    `json:"eventId"`
+	Occurrence   string    `json:"occurrence,omitempty"`
+	TicketTypeID string    `json:"ticketTypeId"`
+	Amount       int64     `json:"amount"`              // 實付金額，與 TicketType.Price 同單位
+	Discount     int64     `json:"discount,omitempty"`  // 折扣碼折抵的金額
+	PromoCode    string    `json:"promoCode,omitempty"` // 使用的折扣碼（見 promo.go）
+	Answers      Answers   `json:"answers,omitempty"`   // 報名表單的答案，付款成功後帶到報名
+	Currency     string    `json:"currency,omitempty"`
+	Status       string    `json:"status"`
+	Provider     string    `json:"provider,omitempty"`
+	PaymentID    string    `json:"paymentId,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// IsOpen pending / paid 的訂單佔有名額
+func (o Order) IsOpen(now time.Time) bool {
+	return o.Status == OrderPaid || (o.Status == OrderPending && now.Before(o.ExpiresAt))
+}
+
+type OrderRepository interface {
+	InOrg(orgID int64) OrderRepository
+
+	// Create 在同一個交易裡檢查名額（同事件、單次、票種的有效訂單 < capacity；0 = 不限）再寫入；
+	// 額滿或同一人同一場已有有效訂單 → ErrConflict。o.Status 為空時設為 pending
+	Create(o *Order, capacity int) error
+	Get(id int64) (Order, error)
+	GetByPayment(provider, paymentID string) (Order, error)
+	// FindOpen 使用者在某場的 pending / paid 訂單；沒有 → ErrNotFound
+	FindOpen(userID int64, eventID, occurrence string) (Order, error)
+	SetPayment(id int64, paymentID string) error
+	// Transition 只有目前狀態在 from 之中才改成 to，否則 ErrConflict（webhook 重送、競態都靠這個擋）
+	Transition(id int64, to string, from ...string) error
+	// ListByEvent 事件在某個狀態的訂單；occurrence 空白 → 全部單次。取消事件或單次時用來退款
+	ListByEvent(eventID, occurrence, status string) ([]Order, error)
+	// Sold 某場各票種的有效訂單數（ticketTypeId → 數量）
+	Sold(eventID, occurrence string) (map[string]int, error)
+
+	// RecordWebhook 記錄已處理的 webhook 事件；已記錄過 → false
+	RecordWebhook(provider, eventID string) (bool, error)
+	// ForgetWebhook 處理失敗時移除紀錄，讓金流重送時能再處理一次
+	ForgetWebhook(provider, eventID string) error
+}
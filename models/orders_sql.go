@@ -0,0 +1,143 @@
+package models
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/lib/pq"
+)
+
+type sqlOrderRepo struct {
+    db  *sql.DB
+    org int64 // 0 → 不限組織（webhook 不知道是哪個組織）
+}
+
+func NewSQLOrderRepository(db *sql.DB) OrderRepository {
+    return &sqlOrderRepo{db: db}
+}
+
+func (r *sqlOrderRepo) InOrg(orgID int64) OrderRepository {
+    return &sqlOrderRepo{db: r.db, org: orgID}
+}
+
+const orderColumns = `id, org_id, user_id, event_id, occurrence, ticket_type_id, amount, discount, promo_code, answers, currency, status, provider, payment_id, expires_at, created_at, updated_at`
+
+func scanOrder(row interface{ Scan(...any) error }) (Order, error) {
+    var o Order
+    err := row.Scan(&o.ID, &o.OrgID, &o.UserID, &o.EventID, &o.Occurrence, &o.TicketTypeID, &o.Amount, &o.Discount, &o.PromoCode, &o.Answers, &o.Currency,
+        &o.Status, &o.Provider, &o.PaymentID, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt)
+    return o, mapSQLErr(err)
+}
+
+func (r *sqlOrderRepo) Create(o *Order, capacity int) error {
+    tx, err := r.db.Begin()
+    if err != nil { return err }
+    defer tx.Rollback()
+
+    // 同一場同一票種序列化：advisory lock 到交易結束自動釋放
+    if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, o.EventID+"@"+o.Occurrence+"#"+o.TicketTypeID); err != nil {
+        return err
+    }
+    // 過期的 pending 訂單先標記掉，才不會擋住 orders_open_key
+    if _, err := tx.Exec(`UPDATE orders SET status=$1, updated_at=now() WHERE event_id=$2 AND occurrence=$3 AND status=$4 AND expires_at <= now()`,
+        OrderExpired, o.EventID, o.Occurrence, OrderPending); err != nil {
+        return err
+    }
+    if capacity > 0 {
+        var sold int
+        if err := tx.QueryRow(`SELECT COUNT(*) FROM orders WHERE event_id=$1 AND occurrence=$2 AND ticket_type_id=$3 AND status IN ($4,$5)`,
+            o.EventID, o.Occurrence, o.TicketTypeID, OrderPending, OrderPaid).Scan(&sold); err != nil {
+            return err
+        }
+        if sold >= capacity { return fmt.Errorf("%w: ticket type %s is sold out", ErrConflict, o.TicketTypeID) }
+    }
+
+    if o.Status == "" { o.Status = OrderPending }
+    o.OrgID = insertOrg(r.org)
+    // UNIQUE(user_id, event_id, occurrence) WHERE status IN (pending, paid) → 重複購買回 ErrConflict
+    err = tx.QueryRow(`INSERT INTO orders(org_id, user_id, event_id, occurrence, ticket_type_id, amount, discount, promo_code, answers, currency, status, provider, expires_at)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13) RETURNING id, created_at, updated_at`,
+        o.OrgID, o.UserID, o.EventID, o.Occurrence, o.TicketTypeID, o.Amount, o.Discount, o.PromoCode, o.Answers, o.Currency, o.Status, o.Provider, o.ExpiresAt).
+        Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+    if err != nil { return mapSQLErr(err) }
+    return mapSQLErr(tx.Commit())
+}
+
+func (r *sqlOrderRepo) Get(id int64) (Order, error) {
+    return scanOrder(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id=$1 AND ($2::bigint = 0 OR org_id = $2)`, id, r.org))
+}
+
+func (r *sqlOrderRepo) GetByPayment(provider, paymentID string) (Order, error) {
+    return scanOrder(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE provider=$1 AND payment_id=$2 AND ($3::bigint = 0 OR org_id = $3)`,
+        provider, paymentID, r.org))
+}
+
+func (r *sqlOrderRepo) FindOpen(userID int64, eventID, occurrence string) (Order, error) {
+    return scanOrder(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders
+        WHERE user_id=$1 AND event_id=$2 AND occurrence=$3 AND status IN ($4,$5) AND ($6::bigint = 0 OR org_id = $6)`,
+        userID, eventID, occurrence, OrderPending, OrderPaid, r.org))
+}
+
+func (r *sqlOrderRepo) SetPayment(id int64, paymentID string) error {
+    res, err := r.db.Exec(`UPDATE orders SET payment_id=$2, updated_at=now() WHERE id=$1 AND ($3::bigint = 0 OR org_id = $3)`, id, paymentID, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 { return ErrNotFound }
+    return nil
+}
+
+func (r *sqlOrderRepo) Transition(id int64, to string, from ...string) error {
+    res, err := r.db.Exec(`UPDATE orders SET status=$2, updated_at=now() WHERE id=$1 AND status = ANY($3) AND ($4::bigint = 0 OR org_id = $4)`,
+        id, to, pq.Array(from), r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 {
+        // 分辨「沒有這筆」與「狀態不符」
+        if _, err := r.Get(id); err != nil { return err }
+        return fmt.Errorf("%w: order %d cannot become %s", ErrConflict, id, to)
+    }
+    return nil
+}
+
+func (r *sqlOrderRepo) ListByEvent(eventID, occurrence, status string) ([]Order, error) {
+    rows, err := r.db.Query(`SELECT `+orderColumns+` FROM orders WHERE event_id=$1 AND status=$2 AND ($3::bigint = 0 OR org_id = $3)
+        AND ($4 = '' OR occurrence = $4) ORDER BY id`,
+        eventID, status, r.org, occurrence)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    var out []Order
+    for rows.Next() {
+        o, err := scanOrder(rows)
+        if err != nil { return nil, err }
+        out = append(out, o)
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlOrderRepo) Sold(eventID, occurrence string) (map[string]int, error) {
+    rows, err := r.db.Query(`SELECT ticket_type_id, COUNT(*) FROM orders
+        WHERE event_id=$1 AND occurrence=$2 AND (status=$3 OR (status=$4 AND expires_at > now())) AND ($5::bigint = 0 OR org_id = $5)
+        GROUP BY ticket_type_id`, eventID, occurrence, OrderPaid, OrderPending, r.org)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := map[string]int{}
+    for rows.Next() {
+        var id string
+        var n int
+        if err := rows.Scan(&id, &n); err != nil { return nil, err }
+        out[id] = n
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlOrderRepo) RecordWebhook(provider, eventID string) (bool, error) {
+    res, err := r.db.Exec(`INSERT INTO payment_webhook_events(provider, event_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, provider, eventID)
+    if err != nil { return false, mapSQLErr(err) }
+    n, err := res.RowsAffected()
+    return n == 1, err
+}
+
+func (r *sqlOrderRepo) ForgetWebhook(provider, eventID string) error {
+    _, err := r.db.Exec(`DELETE FROM payment_webhook_events WHERE provider=$1 AND event_id=$2`, provider, eventID)
+    return mapSQLErr(err)
+}
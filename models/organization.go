@@ -0,0 +1,75 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// 多租戶：每個組織（社群）是一個 tenant，事件、報名、邀請、歷程都帶 org id。
+// 升級前的資料（沒有 org id）一律屬於預設組織 DefaultOrgID。
+// 各 repository 用 InOrg(orgID) 取得只看得到該組織資料的版本；orgID=0 → 不限（背景工作用）。
+const DefaultOrgID int64 = 1
+
+// 組織層級的角色（與事件層級的 owner / co-organizer / checker 分開）
+const (
+	OrgOwner  = "owner"  // 管理成員（含 admin）
+	OrgAdmin  = "admin"  // 管理成員；在組織內等同全域 admin（垃圾桶、還原、歷程）
+	OrgMember = "member" // 一般成員：建立事件、報名
+)
+
+type Organization struct {
+	ID        int64     `json:"id"`
+	Slug      string    `json:"slug"` // 子網域 / X-Tenant header 用，例如 acme.events.example.com
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type OrgMembership struct {
+	OrgID     int64     `json:"orgId"`
+	UserID    int64     `json:"userId"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IsOrgAdmin owner / admin 都能管理組織
+func IsOrgAdmin(role string) bool { return role == OrgOwner || role == OrgAdmin }
+
+// OrganizationRepository 租戶本身的資料；每個方法都明確帶 org id，不需要 InOrg
+type OrganizationRepository interface {
+	Create(o *Organization) error // slug 重複 → ErrConflict
+	GetByID(id int64) (Organization, error)
+	GetBySlug(slug string) (Organization, error)
+
+	AddMember(m *OrgMembership) error // 已是成員 → ErrConflict
+	RemoveMember(orgID, userID int64) error
+	ListMembers(orgID int64) ([]OrgMembership, error)
+	RoleOf(orgID, userID int64) (string, error) // 不是成員 → ""
+}
+
+var slugRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func (o *Organization) Validate() error {
+	v := NewValidationError()
+	if !slugRe.MatchString(o.Slug) {
+		v.Add("slug", "must be a DNS label: lowercase letters, digits and '-', at most 63 characters")
+	}
+	if o.Name == "" || len(o.Name) > 100 {
+		v.Add("name", "is required and must be at most 100 characters")
+	}
+	return v.OrNil()
+}
+
+// OrgOf 沒有 org id 的舊資料屬於預設組織
+func OrgOf(orgID int64) int64 {
+	if orgID == 0 {
+		return DefaultOrgID
+	}
+	return orgID
+}
+
+// InOrg 與 repository 的 InOrg 相同語意：orgID=0 → 不限
+func (e *Event) InOrg(orgID int64) bool { return orgID == 0 || OrgOf(e.OrgID) == orgID }
+
+func ValidOrgRole(role string) bool {
+	return role == OrgOwner || role == OrgAdmin || role == OrgMember
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+    "database/sql"
+    "errors"
+)
+
+type sqlOrganizationRepo struct{ db *sql.DB }
+
+func NewSQLOrganizationRepository(db *sql.DB) OrganizationRepository {
+    return &sqlOrganizationRepo{db}
+}
+
+func (r *sqlOrganizationRepo) Create(o *Organization) error {
+    err := r.db.QueryRow(`INSERT INTO organizations(slug, name) VALUES ($1,$2) RETURNING id, created_at`, o.Slug, o.Name).
+        Scan(&o.ID, &o.CreatedAt)
+    return mapSQLErr(err) // slug 重複 → ErrConflict
+}
+
+func (r *sqlOrganizationRepo) GetByID(id int64) (Organization, error) {
+    return r.getOne(`SELECT id, slug, name, created_at FROM organizations WHERE id=$1`, id)
+}
+
+func (r *sqlOrganizationRepo) GetBySlug(slug string) (Organization, error) {
+    return r.getOne(`SELECT id, slug, name, created_at FROM organizations WHERE slug=$1`, slug)
+}
+
+func (r *sqlOrganizationRepo) getOne(query string, arg any) (Organization, error) {
+    var o Organization
+    if err := r.db.QueryRow(query, arg).Scan(&o.ID, &o.Slug, &o.Name, &o.CreatedAt); err != nil {
+        return Organization{}, mapSQLErr(err)
+    }
+    return o, nil
+}
+
+func (r *sqlOrganizationRepo) AddMember(m *OrgMembership) error {
+    err := r.db.QueryRow(`INSERT INTO org_memberships(org_id, user_id, role) VALUES ($1,$2,$3) RETURNING created_at`,
+        m.OrgID, m.UserID, m.Role).Scan(&m.CreatedAt)
+    return mapSQLErr(err) // PK(org_id, user_id) → ErrConflict
+}
+
+func (r *sqlOrganizationRepo) RemoveMember(orgID, userID int64) error {
+    res, err := r.db.Exec(`DELETE FROM org_memberships WHERE org_id=$1 AND user_id=$2`, orgID, userID)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 { return ErrNotFound }
+    return nil
+}
+
+func (r *sqlOrganizationRepo) ListMembers(orgID int64) ([]OrgMembership, error) {
+    rows, err := r.db.Query(`SELECT org_id, user_id, role, created_at FROM org_memberships WHERE org_id=$1 ORDER BY user_id`, orgID)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := []OrgMembership{}
+    for rows.Next() {
+        var m OrgMembership
+        if err := rows.Scan(&m.OrgID, &m.UserID, &m.Role, &m.CreatedAt); err != nil { return nil, err }
+        out = append(out, m)
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlOrganizationRepo) RoleOf(orgID, userID int64) (string, error) {
+    var role string
+    err := r.db.QueryRow(`SELECT role FROM org_memberships WHERE org_id=$1 AND user_id=$2`, orgID, userID).Scan(&role)
+    if errors.Is(err, sql.ErrNoRows) { return "", nil }
+    return role, mapSQLErr(err)
+}
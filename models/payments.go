@@ -0,0 +1,180 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// 付款狀態（金流回應與 webhook 共用）
+const (
+	PaymentSucceeded = "succeeded"
+	PaymentPending   = "pending" // 需要使用者到 CheckoutURL 完成付款，結果由 webhook 通知
+	PaymentFailed    = "failed"
+)
+
+// Webhook 事件種類
+const (
+	WebhookPaymentSucceeded = "payment.succeeded"
+	WebhookPaymentFailed    = "payment.failed"
+	WebhookRefundSucceeded  = "refund.succeeded"
+)
+
+type Payment struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	CheckoutURL string `json:"checkoutUrl,omitempty"`
+}
+
+// PaymentEvent 驗證過簽章的 webhook 內容；ID 用來做冪等
+type PaymentEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	PaymentID string `json:"paymentId"`
+}
+
+// PaymentProvider 金流介面；實作負責跟外部服務溝通與驗證 webhook 簽章
+type PaymentProvider interface {
+	Name() string
+	CreatePayment(o Order) (Payment, error)
+	Refund(paymentID string, amount int64) error // 金流拒絕 → ErrPaymentFailed
+	// ParseWebhook 驗證簽章並解析；簽章不符 → ErrForbidden，格式錯誤 → ErrValidation
+	ParseWebhook(payload []byte, header http.Header) (PaymentEvent, error)
+}
+
+/* -------- 本機金流（開發 / 測試用，不碰真的錢）-------- */
+
+// LocalSignatureHeader 本機金流 webhook 的簽章 header：hex(HMAC-SHA256(secret, body))
+const LocalSignatureHeader = "X-Local-Signature"
+
+type LocalPaymentProvider struct {
+	secret []byte
+	manual bool // true → 付款停在 pending，等 webhook
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*localPayment
+	declined map[int64]bool // 這些金額會被拒（模擬卡片被拒）
+}
+
+type localPayment struct {
+	amount   int64
+	status   string
+	refunded int64
+}
+
+type LocalPaymentOption func(*LocalPaymentProvider)
+
+// WithManualConfirmation 付款不會立刻成功，需要送 payment.succeeded webhook
+func WithManualConfirmation() LocalPaymentOption {
+	return func(p *LocalPaymentProvider) { p.manual = true }
+}
+
+// WithDeclinedAmounts 這些金額的付款一律被拒
+func WithDeclinedAmounts(amounts ...int64) LocalPaymentOption {
+	return func(p *LocalPaymentProvider) {
+		for _, a := range amounts {
+			p.declined[a] = true
+		}
+	}
+}
+
+func NewLocalPaymentProvider(secret string, opts ...LocalPaymentOption) *LocalPaymentProvider {
+	p := &LocalPaymentProvider{secret: []byte(secret), payments: map[string]*localPayment{}, declined: map[int64]bool{}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *LocalPaymentProvider) Name() string { return "local" }
+
+func (p *LocalPaymentProvider) CreatePayment(o Order) (Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	id := fmt.Sprintf("pay_local_%d", p.seq)
+	switch {
+	case p.declined[o.Amount]:
+		p.payments[id] = &localPayment{amount: o.Amount, status: PaymentFailed}
+		return Payment{ID: id, Status: PaymentFailed}, nil
+	case p.manual:
+		p.payments[id] = &localPayment{amount: o.Amount, status: PaymentPending}
+		return Payment{ID: id, Status: PaymentPending, CheckoutURL: "/payments/local/" + id}, nil
+	default:
+		p.payments[id] = &localPayment{amount: o.Amount, status: PaymentSucceeded}
+		return Payment{ID: id, Status: PaymentSucceeded}, nil
+	}
+}
+
+func (p *LocalPaymentProvider) Refund(paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pay, ok := p.payments[paymentID]
+	if !ok {
+		return fmt.Errorf("%w: unknown payment %s", ErrPaymentFailed, paymentID)
+	}
+	if pay.status != PaymentSucceeded || pay.refunded+amount > pay.amount {
+		return fmt.Errorf("%w: payment %s cannot be refunded", ErrPaymentFailed, paymentID)
+	}
+	pay.refunded += amount
+	return nil
+}
+
+// Confirm 模擬使用者在結帳頁付款完成：更新付款狀態並回傳簽好的 webhook（body、header）
+func (p *LocalPaymentProvider) Confirm(paymentID string, succeeded bool) ([]byte, http.Header) {
+	p.mu.Lock()
+	typ := WebhookPaymentFailed
+	if pay, ok := p.payments[paymentID]; ok {
+		pay.status = PaymentFailed
+		if succeeded {
+			pay.status = PaymentSucceeded
+		}
+	}
+	if succeeded {
+		typ = WebhookPaymentSucceeded
+	}
+	p.seq++
+	evID := fmt.Sprintf("evt_local_%d", p.seq)
+	p.mu.Unlock()
+	return p.SignWebhook(PaymentEvent{ID: evID, Type: typ, PaymentID: paymentID})
+}
+
+// SignWebhook 產生本機金流格式的 webhook（測試重送同一事件時用）
+func (p *LocalPaymentProvider) SignWebhook(ev PaymentEvent) ([]byte, http.Header) {
+	body, _ := json.Marshal(ev)
+	h := http.Header{}
+	h.Set(LocalSignatureHeader, p.sign(body))
+	return body, h
+}
+
+func (p *LocalPaymentProvider) sign(body []byte) string {
+	m := hmac.New(sha256.New, p.secret)
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+func (p *LocalPaymentProvider) ParseWebhook(payload []byte, header http.Header) (PaymentEvent, error) {
+	got, err := hex.DecodeString(header.Get(LocalSignatureHeader))
+	want, _ := hex.DecodeString(p.sign(payload))
+	if err != nil || !hmac.Equal(got, want) {
+		return PaymentEvent{}, fmt.Errorf("%w: bad webhook signature", ErrForbidden)
+	}
+	var ev PaymentEvent
+	v := NewValidationError()
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		v.Add("body", "must be a JSON payment event")
+		return PaymentEvent{}, v
+	}
+	if ev.ID == "" {
+		v.Add("id", "is required")
+	}
+	if ev.PaymentID == "" {
+		v.Add("paymentId", "is required")
+	}
+	return ev, v.OrNil()
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 折扣碼：組織內唯一（不分大小寫，一律存大寫）。
+// 使用紀錄（promo_redemptions）跟著訂單：訂單失敗 / 取消 / 退款時歸還，過期未付款的在下次兌換時歸還。
+const (
+	PromoPercent = "percent" // Value = 1..100（%）
+	PromoFixed   = "fixed"   // Value = 折抵金額（與票價同單位），Currency 必須與票種相同
+)
+
+type PromoCode struct {
+	ID             int64      `json:"id"`
+	OrgID          int64      `json:"-"` // 由 repository 設定
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Value          int64      `json:"value"`
+	Currency       string     `json:"currency,omitempty"`
+	MaxRedemptions int        `json:"maxRedemptions"` // 總使用次數上限；0 = 不限
+	PerUserLimit   int        `json:"perUserLimit"`   // 每人使用次數上限；0 = 不限
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	EventIDs       []string   `json:"eventIds,omitempty"`      // 空 = 組織內所有事件
+	TicketTypeIDs  []string   `json:"ticketTypeIds,omitempty"` // 空 = 所有票種
+	Active         bool       `json:"active"`
+	Redemptions    int        `json:"redemptions"` // 目前有效的使用次數（唯讀）
+	CreatedBy      int64      `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type PromoRepository interface {
+	InOrg(orgID int64) PromoRepository
+	Create(p *PromoCode) error // 同組織 code 重複 → ErrConflict
+	Get(id int64) (PromoCode, error)
+	GetByCode(code string) (PromoCode, error)
+	List(eventID string) ([]PromoCode, error) // 適用於 eventID 的折扣碼；"" → 全部
+	Deactivate(id int64) error
+	// Redeem 在同一個交易裡鎖住折扣碼、檢查停用 / 到期 / 總量 / 每人上限，再記一筆使用；
+	// 不能用 → ErrConflict。同一張訂單只能用一次
+	Redeem(codeID, userID, orderID int64) error
+	// Release 歸還訂單的使用紀錄；沒有紀錄 → nil
+	Release(orderID int64) error
+}
+
+var promoCodeRe = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// NormalizePromoCode 去空白、轉大寫（建立與兌換都用）
+func NormalizePromoCode(code string) string { return strings.ToUpper(strings.TrimSpace(code)) }
+
+func (p *PromoCode) Normalize() {
+	p.Code = NormalizePromoCode(p.Code)
+	p.Currency = strings.ToUpper(strings.TrimSpace(p.Currency))
+	p.ExpiresAt = utcPtr(p.ExpiresAt)
+}
+
+func (p *PromoCode) Validate(now time.Time) error {
+	v := NewValidationError()
+	if !promoCodeRe.MatchString(p.Code) {
+		v.Add("code", "must be 3-32 letters, digits, '-' or '_'")
+	}
+	switch p.Kind {
+	case PromoPercent:
+		if p.Value < 1 || p.Value > 100 {
+			v.Add("value", "must be between 1 and 100 for percent codes")
+		}
+		if p.Currency != "" {
+			v.Add("currency", "is only used by fixed codes")
+		}
+	case PromoFixed:
+		if p.Value < 1 {
+			v.Add("value", "must be positive")
+		}
+		if !validCurrency(p.Currency) {
+			v.Add("currency", "must be an ISO 4217 code such as USD")
+		}
+	default:
+		v.Add("kind", "must be percent or fixed")
+	}
+	if p.MaxRedemptions < 0 {
+		v.Add("maxRedemptions", "must not be negative")
+	}
+	if p.PerUserLimit < 0 {
+		v.Add("perUserLimit", "must not be negative")
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(now) {
+		v.Add("expiresAt", "must be in the future")
+	}
+	return v.OrNil()
+}
+
+// AppliesTo 折扣碼能不能用在這個事件的這個票種（不含次數，次數由 Redeem 檢查）
+func (p PromoCode) AppliesTo(eventID string, t TicketType, now time.Time) error {
+	v := NewValidationError()
+	switch {
+	case !p.Active:
+		v.Add("promoCode", "is no longer active")
+	case p.ExpiresAt != nil && !now.Before(*p.ExpiresAt):
+		v.Add("promoCode", "has expired")
+	case len(p.EventIDs) > 0 && !slices.Contains(p.EventIDs, eventID),
+		len(p.TicketTypeIDs) > 0 && !slices.Contains(p.TicketTypeIDs, t.ID):
+		v.Add("promoCode", "does not apply to this ticket")
+	case t.Price == 0:
+		v.Add("promoCode", "cannot be used on free tickets")
+	case p.Kind == PromoFixed && p.Currency != t.Currency:
+		v.Add("promoCode", "is in a different currency")
+	}
+	return v.OrNil()
+}
+
+// Discount 折抵金額，不會超過票價（百分比無條件捨去）
+func (p PromoCode) Discount(price int64) int64 {
+	d := p.Value
+	if p.Kind == PromoPercent {
+		d = price * p.Value / 100
+	}
+	return min(d, price)
+}
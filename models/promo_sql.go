@@ -0,0 +1,127 @@
+package models
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/lib/pq"
+)
+
+type sqlPromoRepo struct {
+    db  *sql.DB
+    org int64 // 0 → 不限組織
+}
+
+func NewSQLPromoRepository(db *sql.DB) PromoRepository {
+    return &sqlPromoRepo{db: db}
+}
+
+func (r *sqlPromoRepo) InOrg(orgID int64) PromoRepository {
+    return &sqlPromoRepo{db: r.db, org: orgID}
+}
+
+// redemptions 是即時算的，不存計數欄位（歸還時只要刪掉紀錄）
+const promoColumns = `p.id, p.org_id, p.code, p.kind, p.value, p.currency, p.max_redemptions, p.per_user_limit, p.expires_at,
+    p.event_ids, p.ticket_type_ids, p.active, p.created_by, p.created_at,
+    (SELECT COUNT(*) FROM promo_redemptions r WHERE r.code_id = p.id)`
+
+func scanPromo(row interface{ Scan(...any) error }) (PromoCode, error) {
+    var p PromoCode
+    var expires sql.NullTime
+    err := row.Scan(&p.ID, &p.OrgID, &p.Code, &p.Kind, &p.Value, &p.Currency, &p.MaxRedemptions, &p.PerUserLimit, &expires,
+        pq.Array(&p.EventIDs), pq.Array(&p.TicketTypeIDs), &p.Active, &p.CreatedBy, &p.CreatedAt, &p.Redemptions)
+    if err != nil { return PromoCode{}, mapSQLErr(err) }
+    if expires.Valid {
+        t := expires.Time.UTC()
+        p.ExpiresAt = &t
+    }
+    return p, nil
+}
+
+func (r *sqlPromoRepo) Create(p *PromoCode) error {
+    // UNIQUE(org_id, code) → 重複回 ErrConflict
+    p.OrgID, p.Active = insertOrg(r.org), true
+    err := r.db.QueryRow(`INSERT INTO promo_codes(org_id, code, kind, value, currency, max_redemptions, per_user_limit, expires_at, event_ids, ticket_type_ids, created_by)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING id, created_at`,
+        p.OrgID, p.Code, p.Kind, p.Value, p.Currency, p.MaxRedemptions, p.PerUserLimit, p.ExpiresAt,
+        pq.Array(p.EventIDs), pq.Array(p.TicketTypeIDs), p.CreatedBy).Scan(&p.ID, &p.CreatedAt)
+    return mapSQLErr(err)
+}
+
+func (r *sqlPromoRepo) Get(id int64) (PromoCode, error) {
+    return scanPromo(r.db.QueryRow(`SELECT `+promoColumns+` FROM promo_codes p WHERE p.id=$1 AND ($2::bigint = 0 OR p.org_id = $2)`, id, r.org))
+}
+
+func (r *sqlPromoRepo) GetByCode(code string) (PromoCode, error) {
+    // 不限組織時（背景工作）code 可能重複，只取預設組織的
+    return scanPromo(r.db.QueryRow(`SELECT `+promoColumns+` FROM promo_codes p WHERE p.code=$1 AND p.org_id = $2`,
+        NormalizePromoCode(code), insertOrg(r.org)))
+}
+
+func (r *sqlPromoRepo) List(eventID string) ([]PromoCode, error) {
+    rows, err := r.db.Query(`SELECT `+promoColumns+` FROM promo_codes p
+        WHERE ($1 = '' OR cardinality(p.event_ids) = 0 OR $1 = ANY(p.event_ids)) AND ($2::bigint = 0 OR p.org_id = $2)
+        ORDER BY p.id`, eventID, r.org)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := []PromoCode{}
+    for rows.Next() {
+        p, err := scanPromo(rows)
+        if err != nil { return nil, err }
+        out = append(out, p)
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlPromoRepo) Deactivate(id int64) error {
+    res, err := r.db.Exec(`UPDATE promo_codes SET active=false WHERE id=$1 AND ($2::bigint = 0 OR org_id = $2)`, id, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 { return ErrNotFound }
+    return nil
+}
+
+func (r *sqlPromoRepo) Redeem(codeID, userID, orderID int64) error {
+    tx, err := r.db.Begin()
+    if err != nil { return err }
+    defer tx.Rollback()
+
+    // FOR UPDATE：同一個折扣碼的兌換在這裡排隊，計數與寫入之間不會有人插隊
+    var maxRedemptions, perUser int
+    var usable bool
+    err = tx.QueryRow(`SELECT max_redemptions, per_user_limit, active AND (expires_at IS NULL OR expires_at > now()) FROM promo_codes
+        WHERE id=$1 AND ($2::bigint = 0 OR org_id = $2) FOR UPDATE`, codeID, r.org).Scan(&maxRedemptions, &perUser, &usable)
+    if err != nil { return mapSQLErr(err) }
+    if !usable {
+        return fmt.Errorf("%w: promo code %d is no longer valid", ErrConflict, codeID)
+    }
+
+    // 已經不佔名額的訂單（過期未付款，或漏掉 Release 的）先歸還。
+    // 過期之後才付款成功的訂單一律退款（routes/checkout.go applyPaymentEvent），不會變成沒有兌換紀錄的 paid 訂單
+    if _, err := tx.Exec(`DELETE FROM promo_redemptions r USING orders o
+        WHERE r.code_id=$1 AND o.id=r.order_id AND NOT (o.status=$2 OR (o.status=$3 AND o.expires_at > now()))`,
+        codeID, OrderPaid, OrderPending); err != nil {
+        return err
+    }
+    var total, mine int
+    if err := tx.QueryRow(`SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id=$2) FROM promo_redemptions WHERE code_id=$1`, codeID, userID).
+        Scan(&total, &mine); err != nil {
+        return err
+    }
+    if maxRedemptions > 0 && total >= maxRedemptions {
+        return fmt.Errorf("%w: promo code %d is fully redeemed", ErrConflict, codeID)
+    }
+    if perUser > 0 && mine >= perUser {
+        return fmt.Errorf("%w: promo code %d already used by user %d", ErrConflict, codeID, userID)
+    }
+    // UNIQUE(order_id)：同一張訂單不會記兩次
+    if _, err := tx.Exec(`INSERT INTO promo_redemptions(code_id, user_id, order_id) VALUES ($1,$2,$3)`, codeID, userID, orderID); err != nil {
+        return mapSQLErr(err)
+    }
+    return mapSQLErr(tx.Commit())
+}
+
+func (r *sqlPromoRepo) Release(orderID int64) error {
+    _, err := r.db.Exec(`DELETE FROM promo_redemptions WHERE order_id=$1`, orderID)
+    return mapSQLErr(err)
+}
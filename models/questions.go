@@ -0,0 +1,231 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 報名表單：事件可以定義幾個問題（飲食需求、T-shirt 尺寸、公司…），報名時一起填。
+// 答案存在報名（有票種的事件先存在訂單，付款成功後帶到報名）
+const (
+	QuestionText        = "text"
+	QuestionSelect      = "select"       // 單選：答案是 options 其中之一
+	QuestionMultiSelect = "multi-select" // 複選：答案是 options 的子集合
+)
+
+const (
+	MaxQuestions         = 20
+	MaxQuestionLabelLen  = 200
+	MaxQuestionOptions   = 50
+	MaxQuestionOptionLen = 100
+	MaxTextAnswerLen     = 1000
+)
+
+type Question struct {
+	ID       string   `json:"id"` // 建立時自動產生；更新時帶回原 id 才對得上既有答案
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // select / multi-select 的選項
+}
+
+// Answers 問題 id → 答案；text / select 是 string，multi-select 是 []string。沒填的不放
+type Answers map[string]any
+
+// Text 匯出用：multi-select 以 "; " 串起來
+func (a Answers) Text(questionID string) string {
+	switch v := a[questionID].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, "; ")
+	}
+	return ""
+}
+
+// Value / Scan：存成 JSONB
+func (a Answers) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *Answers) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("answers: cannot scan %T", src)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*a = normalizeAnswerTypes(raw)
+	return nil
+}
+
+// normalizeAnswerTypes JSON 解回來的 []any 轉成 []string，其他非字串的值丟掉
+func normalizeAnswerTypes(raw map[string]any) Answers {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := Answers{}
+	for id, v := range raw {
+		switch v := v.(type) {
+		case string:
+			out[id] = v
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, s := range v {
+				if s, ok := s.(string); ok {
+					list = append(list, s)
+				}
+			}
+			out[id] = list
+		}
+	}
+	return out
+}
+
+// NormalizeQuestions 補上問題 id、整理文字、選項去空白去重複（建立、更新前呼叫）
+func (e *Event) NormalizeQuestions() {
+	if len(e.Questions) == 0 {
+		e.Questions = nil
+		return
+	}
+	for i := range e.Questions {
+		q := &e.Questions[i]
+		if q.ID == "" {
+			q.ID = newQuestionID()
+		}
+		q.Label = strings.TrimSpace(q.Label)
+		q.Type = strings.ToLower(strings.TrimSpace(q.Type))
+		opts := make([]string, 0, len(q.Options))
+		for _, o := range q.Options {
+			if o = strings.TrimSpace(o); o != "" && !slices.Contains(opts, o) {
+				opts = append(opts, o)
+			}
+		}
+		q.Options = opts
+		if len(q.Options) == 0 {
+			q.Options = nil
+		}
+	}
+}
+
+func newQuestionID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "q_" + hex.EncodeToString(b)
+}
+
+func (e *Event) validateQuestions(v *ValidationError) {
+	if len(e.Questions) > MaxQuestions {
+		v.Add("questions", fmt.Sprintf("must have at most %d questions", MaxQuestions))
+		return
+	}
+	seen := map[string]bool{}
+	for i, q := range e.Questions {
+		field := fmt.Sprintf("questions[%d]", i)
+		switch {
+		case q.Label == "" || utf8.RuneCountInString(q.Label) > MaxQuestionLabelLen:
+			v.Add(field+".label", fmt.Sprintf("is required and must be at most %d characters", MaxQuestionLabelLen))
+		case q.Type != QuestionText && q.Type != QuestionSelect && q.Type != QuestionMultiSelect:
+			v.Add(field+".type", "must be text, select or multi-select")
+		case q.Type == QuestionText && len(q.Options) > 0:
+			v.Add(field+".options", "are only used by select questions")
+		case q.Type != QuestionText && (len(q.Options) == 0 || len(q.Options) > MaxQuestionOptions):
+			v.Add(field+".options", fmt.Sprintf("must have 1 to %d options", MaxQuestionOptions))
+		case seen[q.ID]:
+			v.Add(field+".id", "must be unique")
+		}
+		for _, o := range q.Options {
+			if utf8.RuneCountInString(o) > MaxQuestionOptionLen {
+				v.Add(field+".options", fmt.Sprintf("must be at most %d characters each", MaxQuestionOptionLen))
+				break
+			}
+		}
+		seen[q.ID] = true
+	}
+}
+
+// ValidateAnswers 依事件的報名表單檢查答案，回傳整理過的答案（去空白、複選依選項順序）。
+// 錯誤欄位為 answers.<問題 id>
+func (e *Event) ValidateAnswers(in map[string]any) (Answers, error) {
+	v := NewValidationError()
+	out := Answers{}
+	for id := range in {
+		if !slices.ContainsFunc(e.Questions, func(q Question) bool { return q.ID == id }) {
+			v.Add("answers."+id, "is not a question of this event")
+		}
+	}
+	for _, q := range e.Questions {
+		field := "answers." + q.ID
+		raw, given := in[q.ID]
+		switch q.Type {
+		case QuestionText, QuestionSelect:
+			s, ok := raw.(string)
+			if given && raw != nil && !ok {
+				v.Add(field, "must be a string")
+				continue
+			}
+			s = strings.TrimSpace(s)
+			switch {
+			case s == "":
+			case q.Type == QuestionText && utf8.RuneCountInString(s) > MaxTextAnswerLen:
+				v.Add(field, fmt.Sprintf("must be at most %d characters", MaxTextAnswerLen))
+			case q.Type == QuestionSelect && !slices.Contains(q.Options, s):
+				v.Add(field, "must be one of the options")
+			default:
+				out[q.ID] = s
+			}
+		case QuestionMultiSelect:
+			list, ok := raw.([]any)
+			if given && raw != nil && !ok {
+				v.Add(field, "must be a list of options")
+				continue
+			}
+			picked := make([]string, 0, len(list))
+			for _, item := range list {
+				s, _ := item.(string)
+				if !slices.Contains(q.Options, strings.TrimSpace(s)) {
+					v.Add(field, "must only contain the options")
+					break
+				}
+				picked = append(picked, strings.TrimSpace(s))
+			}
+			sort.Slice(picked, func(i, j int) bool {
+				return slices.Index(q.Options, picked[i]) < slices.Index(q.Options, picked[j])
+			})
+			if picked = slices.Compact(picked); len(picked) > 0 {
+				out[q.ID] = picked
+			}
+		}
+		if _, answered := out[q.ID]; q.Required && !answered {
+			v.Add(field, "is required")
+		}
+	}
+	if err := v.OrNil(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
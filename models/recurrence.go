@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"restapi/utils"
+)
+
+// 重複事件：帶 Recurrence 的 Event 就是「系列」本身（parent series），
+// DateTime 是第一次的開始時間（DTSTART），每一次發生（occurrence）在讀取時依 RRULE 展開，不另外存。
+// 單次例外（取消 / 改期）記在 Exceptions，以原本的開始時間對應。
+type Recurrence struct {
+	RRule      string                `json:"rrule"`
+	Exceptions []OccurrenceException `json:"exceptions,omitempty"`
+}
+
+type OccurrenceException struct {
+	Date      time.Time  `json:"date"` // 原本的開始時間（= occurrence id）
+	Cancelled bool       `json:"cancelled,omitempty"`
+	MovedTo   *time.Time `json:"movedTo,omitempty"`
+}
+
+// Occurrence 展開後的單次發生
+type Occurrence struct {
+	ID            string    `json:"occurrenceId"`
+	Start         time.Time  `json:"start"`
+	End           *time.Time `json:"end,omitempty"` // 有 EndTime 時依系列長度推算
+	OriginalStart time.Time `json:"originalStart"`
+	Cancelled     bool      `json:"cancelled,omitempty"`
+	Moved         bool      `json:"moved,omitempty"`
+}
+
+// 單一系列每次最多展開的筆數
+const MaxOccurrences = 500
+
+const occurrenceIDLayout = "20060102T150405Z"
+
+// OccurrenceID 以原本開始時間（UTC）當 id，例如 20300107T100000Z
+func OccurrenceID(t time.Time) string { return t.UTC().Format(occurrenceIDLayout) }
+
+func ParseOccurrenceID(s string) (time.Time, error) {
+	t, err := time.Parse(occurrenceIDLayout, s)
+	if err != nil {
+		v := NewValidationError()
+		v.Add("occurrence", "must look like 20060102T150405Z")
+		return time.Time{}, v
+	}
+	return t, nil
+}
+
+func (e *Event) IsRecurring() bool { return e.Recurrence != nil }
+
+func (e *Event) rule() (*utils.RRule, error) {
+	if e.Recurrence == nil {
+		return nil, fmt.Errorf("%w: event is not recurring", ErrValidation)
+	}
+	return utils.ParseRRule(e.Recurrence.RRule)
+}
+
+// dtstart 在事件時區展開，每週 10:00 跨夏令時間仍是當地 10:00
+func (e *Event) dtstart() time.Time { return e.DateTime.In(e.Zone()) }
+
+func (e *Event) exception(original time.Time) (OccurrenceException, bool) {
+	if e.Recurrence == nil {
+		return OccurrenceException{}, false
+	}
+	for _, ex := range e.Recurrence.Exceptions {
+		if ex.Date.Equal(original) {
+			return ex, true
+		}
+	}
+	return OccurrenceException{}, false
+}
+
+func (e *Event) occurrenceAt(original time.Time) Occurrence {
+	original = original.UTC()
+	o := Occurrence{ID: OccurrenceID(original), Start: original, OriginalStart: original}
+	if ex, ok := e.exception(original); ok {
+		o.Cancelled = ex.Cancelled
+		if ex.MovedTo != nil {
+			o.Start, o.Moved = ex.MovedTo.UTC(), true
+		}
+	}
+	if e.EndTime != nil {
+		end := o.Start.Add(e.Duration())
+		o.End = &end
+	}
+	return o
+}
+
+// Occurrences 展開開始時間落在 [from, to] 的發生（含已取消，Cancelled=true）
+// 改期的以新時間判斷是否在範圍內
+func (e *Event) Occurrences(from, to time.Time, limit int) ([]Occurrence, error) {
+	r, err := e.rule()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > MaxOccurrences {
+		limit = MaxOccurrences
+	}
+
+	var out []Occurrence
+	seen := map[string]bool{}
+	for _, t := range r.Between(e.dtstart(), from, to, limit) {
+		o := e.occurrenceAt(t)
+		seen[o.ID] = true
+		if o.Start.Before(from) || o.Start.After(to) { // 被改期移出範圍
+			continue
+		}
+		out = append(out, o)
+	}
+	// 原本在範圍外、但被改期進來的
+	for _, ex := range e.Recurrence.Exceptions {
+		if ex.MovedTo == nil || seen[OccurrenceID(ex.Date)] {
+			continue
+		}
+		if !ex.MovedTo.Before(from) && !ex.MovedTo.After(to) && r.Contains(e.dtstart(), ex.Date) {
+			out = append(out, e.occurrenceAt(ex.Date))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// FindOccurrence 以 occurrence id 找單次發生；不屬於規則 → ErrNotFound
+func (e *Event) FindOccurrence(id string) (Occurrence, error) {
+	r, err := e.rule()
+	if err != nil {
+		return Occurrence{}, err
+	}
+	t, err := ParseOccurrenceID(id)
+	if err != nil {
+		return Occurrence{}, err
+	}
+	if !r.Contains(e.dtstart(), t) {
+		return Occurrence{}, fmt.Errorf("%w: no occurrence %s", ErrNotFound, id)
+	}
+	return e.occurrenceAt(t), nil
+}
+
+// SetException 新增或取代某次發生的例外
+func (e *Event) SetException(ex OccurrenceException) {
+	ex.Date = ex.Date.UTC()
+	for i, cur := range e.Recurrence.Exceptions {
+		if cur.Date.Equal(ex.Date) {
+			e.Recurrence.Exceptions[i] = ex
+			return
+		}
+	}
+	e.Recurrence.Exceptions = append(e.Recurrence.Exceptions, ex)
+	sort.Slice(e.Recurrence.Exceptions, func(i, j int) bool {
+		return e.Recurrence.Exceptions[i].Date.Before(e.Recurrence.Exceptions[j].Date)
+	})
+}
+
+// validateRecurrence 只檢查 RRULE 能不能解析、例外是否對得上規則
+func (e *Event) validateRecurrence(v *ValidationError) {
+	if e.Recurrence == nil {
+		return
+	}
+	r, err := utils.ParseRRule(e.Recurrence.RRule)
+	if err != nil {
+		v.Add("recurrence.rrule", err.Error())
+		return
+	}
+	e.Recurrence.RRule = r.String() // 正規化
+	for _, ex := range e.Recurrence.Exceptions {
+		if !r.Contains(e.dtstart(), ex.Date) {
+			v.Add("recurrence.exceptions", "date "+OccurrenceID(ex.Date)+" is not an occurrence")
+		}
+	}
+}
@@ -1,20 +1,222 @@
 package models
 
-import "database/sql"
+import (
+    "database/sql"
+    "fmt"
+    "time"
+)
 
-type sqlRegistrationRepo struct{ db *sql.DB }
+type sqlRegistrationRepo struct {
+    db  *sql.DB
+    org int64 // 0 → 不限組織
+}
 
 func NewSQLRegistrationRepository(db *sql.DB) RegistrationRepository {
-    return &sqlRegistrationRepo{db}
+    return &sqlRegistrationRepo{db: db}
+}
+
+func (r *sqlRegistrationRepo) InOrg(orgID int64) RegistrationRepository {
+    return &sqlRegistrationRepo{db: r.db, org: orgID}
+}
+
+func (r *sqlRegistrationRepo) Register(reg *Registration, capacity int) error {
+    if reg.RSVP == "" { reg.RSVP = RSVPGoing }
+    tx, err := r.db.Begin()
+    if err != nil { return err }
+    defer tx.Rollback()
+
+    if err := checkSeats(tx, reg, capacity); err != nil { return err }
+    if err := insertRegistration(tx, reg, insertOrg(r.org)); err != nil { return err }
+    return mapSQLErr(tx.Commit())
+}
+
+// insertRegistration 依賴 UNIQUE(user_id, event_id, occurrence) 來杜絕重複
+func insertRegistration(tx *sql.Tx, reg *Registration, org int64) error {
+    if reg.RSVP == "" { reg.RSVP = RSVPGoing }
+    err := tx.QueryRow(`INSERT INTO registrations(user_id, event_id, occurrence, org_id, answers, rsvp, guests) VALUES ($1,$2,$3,$4,$5,$6,$7)
+        RETURNING id, status, created_at, responded_at`,
+        reg.UserID, reg.EventID, reg.Occurrence, org, reg.Answers, reg.RSVP, reg.Guests).Scan(&reg.ID, &reg.Status, &reg.CreatedAt, &reg.RespondedAt)
+    return mapSQLErr(err)
+}
+
+func (r *sqlRegistrationRepo) UpdateRSVP(reg *Registration, capacity int) error {
+    tx, err := r.db.Begin()
+    if err != nil { return err }
+    defer tx.Rollback()
+
+    if err := checkSeats(tx, reg, capacity); err != nil { return err }
+    updated, err := scanRegistration(tx.QueryRow(`UPDATE registrations SET rsvp=$4, guests=$5, responded_at=now()
+        WHERE user_id=$1 AND event_id=$2 AND occurrence=$3 AND status=$6 AND ($7::bigint = 0 OR org_id = $7)
+        RETURNING `+registrationColumns,
+        reg.UserID, reg.EventID, reg.Occurrence, reg.RSVP, reg.Guests, RegistrationActive, r.org))
+    if err != nil { return err }
+    if err := tx.Commit(); err != nil { return mapSQLErr(err) }
+    *reg = updated
+    return nil
+}
+
+// checkSeats going 的人要佔名額時，在交易內檢查同一場其他人已佔的名額
+func checkSeats(tx *sql.Tx, reg *Registration, capacity int) error {
+    if reg.RSVP != RSVPGoing { return nil }
+    return reserveSeats(tx, reg.EventID, reg.Occurrence, reg.UserID, 1+reg.Guests, capacity)
+}
+
+// reserveSeats 已佔（going + 同行者 + 團體保留，不含 excludeUser 自己）+ seats 超過 capacity → ErrConflict。
+// 同一場序列化：advisory lock 到交易結束自動釋放
+func reserveSeats(tx *sql.Tx, eventID, occurrence string, excludeUser int64, seats, capacity int) error {
+    if capacity <= 0 { return nil }
+    if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, "rsvp:"+eventID+"@"+occurrence); err != nil {
+        return err
+    }
+    var taken int
+    if err := tx.QueryRow(`SELECT
+        (SELECT COALESCE(SUM(1 + guests), 0) FROM registrations
+            WHERE event_id=$1 AND occurrence=$2 AND status=$3 AND rsvp=$4 AND user_id <> $5) +
+        (SELECT COUNT(*) FROM registration_transfers WHERE event_id=$1 AND occurrence=$2 AND kind=$6 AND status=$7)`,
+        eventID, occurrence, RegistrationActive, RSVPGoing, excludeUser, TransferGroupSeat, TransferPending).Scan(&taken); err != nil {
+        return mapSQLErr(err)
+    }
+    if taken+seats > capacity {
+        return fmt.Errorf("%w: event is full (%d of %d seats taken)", ErrConflict, taken, capacity)
+    }
+    return nil
+}
+
+func (r *sqlRegistrationRepo) RSVPCounts(eventID string) (map[string]RSVPCounts, error) {
+    rows, err := r.db.Query(`SELECT occurrence, rsvp, COUNT(*), COALESCE(SUM(guests), 0) FROM registrations
+        WHERE event_id=$1 AND status=$2 AND ($3::bigint = 0 OR org_id = $3) GROUP BY occurrence, rsvp`,
+        eventID, RegistrationActive, r.org)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := map[string]RSVPCounts{}
+    for rows.Next() {
+        var occ, rsvp string
+        var n, guests int
+        if err := rows.Scan(&occ, &rsvp, &n, &guests); err != nil { return nil, mapSQLErr(err) }
+        c := out[occ]
+        switch rsvp {
+        case RSVPGoing:
+            c.Going, c.Guests = c.Going+n, c.Guests+guests
+        case RSVPMaybe:
+            c.Maybe += n
+        case RSVPNotGoing:
+            c.NotGoing += n
+        }
+        out[occ] = c
+    }
+    if err := rows.Err(); err != nil { return nil, err }
+
+    // 團體報名保留的名額
+    held, err := r.db.Query(`SELECT occurrence, COUNT(*) FROM registration_transfers
+        WHERE event_id=$1 AND kind=$2 AND status=$3 AND ($4::bigint = 0 OR org_id = $4) GROUP BY occurrence`,
+        eventID, TransferGroupSeat, TransferPending, r.org)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer held.Close()
+    for held.Next() {
+        var occ string
+        var n int
+        if err := held.Scan(&occ, &n); err != nil { return nil, mapSQLErr(err) }
+        c := out[occ]
+        c.Held = n
+        out[occ] = c
+    }
+    return out, held.Err()
+}
+
+// VoidByEvent 事件取消時把有效報名標記為 voided（保留紀錄，不刪除）
+func (r *sqlRegistrationRepo) VoidByEvent(eventID string) (int64, error) {
+    res, err := r.db.Exec(`UPDATE registrations SET status=$2, voided_at=now() WHERE event_id=$1 AND status=$3 AND ($4::bigint = 0 OR org_id = $4)`,
+        eventID, RegistrationVoided, RegistrationActive, r.org)
+    if err != nil { return 0, mapSQLErr(err) }
+    return res.RowsAffected()
+}
+
+// VoidByOccurrence 同 VoidByEvent，只處理重複事件的某一次
+func (r *sqlRegistrationRepo) VoidByOccurrence(eventID, occurrence string) (int64, error) {
+    res, err := r.db.Exec(`UPDATE registrations SET status=$3, voided_at=now() WHERE event_id=$1 AND occurrence=$2 AND status=$4 AND ($5::bigint = 0 OR org_id = $5)`,
+        eventID, occurrence, RegistrationVoided, RegistrationActive, r.org)
+    if err != nil { return 0, mapSQLErr(err) }
+    return res.RowsAffected()
+}
+
+func (r *sqlRegistrationRepo) Cancel(userID int64, eventID, occurrence string) error {
+    res, err := r.db.Exec(`DELETE FROM registrations WHERE user_id=$1 AND event_id=$2 AND occurrence=$3 AND ($4::bigint = 0 OR org_id = $4)`,
+        userID, eventID, occurrence, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 0 { return ErrNotFound }
+    return nil
+}
+
+const registrationColumns = `id, user_id, event_id, occurrence, status, created_at, checked_in_at, COALESCE(checked_in_by, 0), answers, rsvp, guests, responded_at`
+
+// extra：registrationColumns 之後額外 SELECT 的欄位
+func scanRegistration(row interface{ Scan(...any) error }, extra ...any) (Registration, error) {
+    var reg Registration
+    var checkedIn sql.NullTime
+    dest := []any{&reg.ID, &reg.UserID, &reg.EventID, &reg.Occurrence, &reg.Status, &reg.CreatedAt, &checkedIn, &reg.CheckedInBy, &reg.Answers,
+        &reg.RSVP, &reg.Guests, &reg.RespondedAt}
+    err := row.Scan(append(dest, extra...)...)
+    if err != nil { return Registration{}, mapSQLErr(err) }
+    if checkedIn.Valid {
+        t := checkedIn.Time.UTC()
+        reg.CheckedInAt = &t
+    }
+    return reg, nil
+}
+
+func (r *sqlRegistrationRepo) list(where string, args ...any) ([]Registration, error) {
+    rows, err := r.db.Query(`SELECT `+registrationColumns+` FROM registrations WHERE `+where, args...)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := []Registration{}
+    for rows.Next() {
+        reg, err := scanRegistration(rows)
+        if err != nil { return nil, err }
+        out = append(out, reg)
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlRegistrationRepo) ListByEvent(eventID string) ([]Registration, error) {
+    return r.list(`event_id=$1 AND ($2::bigint = 0 OR org_id = $2) ORDER BY occurrence, created_at, id`, eventID, r.org)
+}
+
+// lib/pq 邊讀邊回傳資料列，rows.Next 一次只解一筆
+func (r *sqlRegistrationRepo) StreamAttendees(eventID, occurrence string, fn func(Attendee) error) error {
+    rows, err := r.db.Query(`SELECT `+registrationColumns+`, COALESCE((SELECT u.email FROM users u WHERE u.id = registrations.user_id), '')
+        FROM registrations
+        WHERE event_id=$1 AND ($2 = '' OR occurrence = $2) AND ($3::bigint = 0 OR org_id = $3)
+        ORDER BY occurrence, created_at, id`, eventID, occurrence, r.org)
+    if err != nil { return mapSQLErr(err) }
+    defer rows.Close()
+
+    for rows.Next() {
+        var a Attendee
+        reg, err := scanRegistration(rows, &a.Email)
+        if err != nil { return err }
+        a.Registration = reg
+        if err := fn(a); err != nil { return err }
+    }
+    return rows.Err()
+}
+
+func (r *sqlRegistrationRepo) ListByUser(userID int64) ([]Registration, error) {
+    return r.list(`user_id=$1 AND ($2::bigint = 0 OR org_id = $2) ORDER BY created_at DESC, id DESC`, userID, r.org)
 }
 
-func (r *sqlRegistrationRepo) Register(userID int64, eventID string) error {
-    // 依賴 UNIQUE(user_id, event_id) 來杜絕重複
-    _, err := r.db.Exec(`INSERT INTO registrations(user_id, event_id) VALUES ($1,$2)`, userID, eventID)
-    return err
+func (r *sqlRegistrationRepo) Get(id int64) (Registration, error) {
+    return scanRegistration(r.db.QueryRow(`SELECT `+registrationColumns+` FROM registrations WHERE id=$1 AND ($2::bigint = 0 OR org_id = $2)`, id, r.org))
 }
 
-func (r *sqlRegistrationRepo) Cancel(userID int64, eventID string) error {
-    _, err := r.db.Exec(`DELETE FROM registrations WHERE user_id=$1 AND event_id=$2`, userID, eventID)
-    return err
+func (r *sqlRegistrationRepo) CheckIn(id, by int64, at time.Time) error {
+    res, err := r.db.Exec(`UPDATE registrations SET checked_in_at=$2, checked_in_by=$3
+        WHERE id=$1 AND status=$4 AND checked_in_at IS NULL AND ($5::bigint = 0 OR org_id = $5)`,
+        id, at.UTC(), by, RegistrationActive, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 1 { return nil }
+    // 沒更新到：分辨不存在與已報到 / 已作廢
+    if _, err := r.Get(id); err != nil { return err }
+    return fmt.Errorf("%w: registration %d is already checked in or no longer active", ErrConflict, id)
 }
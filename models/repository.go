@@ -4,37 +4,217 @@ import "time"
 
 type Event struct {
     ID          string    `json:"id"` // 使用 UUID（跨庫統一鍵）
+    OrgID       int64     `json:"orgId,omitempty" bson:"orgid,omitempty"` // 所屬組織（tenant）；舊資料沒有 → DefaultOrgID
     Name        string    `json:"name"`
     Description string    `json:"description"`
-    Location    string    `json:"location"`
-    DateTime    time.Time `json:"dateTime"`
-    UserID      int64     `json:"userId"` // 建立者（來自 SQL Users）
+    Location    string    `json:"location"` // 地點顯示名稱（自由文字）
+    Geo         *GeoPoint `json:"geo,omitempty"` // 選填座標（GeoJSON Point），供附近 / 地圖搜尋
+    Category    string    `json:"category,omitempty"` // 單一分類（小寫，見 taxonomy.go）
+    Tags        []string  `json:"tags,omitempty"`     // 標籤（小寫、去重）
+    Visibility  string    `json:"visibility,omitempty"` // public / unlisted / private（見 visibility.go）
+    DateTime    time.Time `json:"dateTime"` // 開始時間（UTC）
+    EndTime     *time.Time `json:"endTime,omitempty"` // 結束時間（UTC），必須晚於 DateTime
+    TimeZone    string     `json:"timeZone,omitempty"` // IANA 時區；空 = UTC（見 timezone.go）
+    UserID      int64     `json:"userId"` // 擁有者（來自 SQL Users）；建立者，轉移擁有權後會變
+    Members     []EventMember `json:"members,omitempty"` // 共同主辦 / 報到人員（見 membership.go）
+    Version     int64     `json:"version"` // 樂觀鎖：每次寫入 +1（新建為 1）
+    DeletedAt   *time.Time `json:"deletedAt,omitempty"` // 軟刪除時間；nil = 未刪除
+    Status      string     `json:"status"`              // draft / scheduled / published / cancelled / completed（見 lifecycle.go）
+    PublishAt   *time.Time `json:"publishAt,omitempty"` // scheduled 時自動發布的時間
+    Recurrence  *Recurrence `json:"recurrence,omitempty"` // 非 nil → 重複事件系列（見 recurrence.go）
+    TicketTypes []TicketType `json:"ticketTypes,omitempty"` // 票種；空 → 免費報名（見 tickets.go）
+    Questions   []Question   `json:"questions,omitempty"`   // 報名表單（見 questions.go）
+    Capacity    int          `json:"capacity,omitempty"`    // 每一場的名額（going + 同行者）；0 = 不限（見 rsvp.go）
+}
+
+// EventFilter 公開列表的篩選條件；零值 = 不篩選
+type EventFilter struct {
+    From *time.Time // 開始時間 >= From（重複事件：系列在 To 之前開始即列入，由呼叫端展開）
+    To   *time.Time
+    Near *GeoNear // 半徑內（只含有座標的事件）
+    Box  *GeoBox  // 地圖視窗內
+    Category string
+    Tags     []string // 必須全部符合
+}
+
+// Matches 與 Mongo 查詢相同的判斷，給記憶體實作（測試 mock）用
+func (f EventFilter) Matches(e Event) bool {
+    if f.To != nil && e.DateTime.After(*f.To) { return false }
+    if f.From != nil && !e.IsRecurring() && e.DateTime.Before(*f.From) { return false }
+    if f.Near != nil {
+        d, ok := e.DistanceFrom(f.Near.Lat, f.Near.Lng)
+        if !ok || d > f.Near.RadiusKm { return false }
+    }
+    if f.Box != nil && !f.Box.Contains(e.Geo) { return false }
+    if f.Category != "" && e.Category != f.Category { return false }
+    return e.HasTags(f.Tags)
 }
 
 // ===== Events =====
 type EventRepository interface {  //就把它當成一個struct 可以接收任何實體化它方法的物件   var a EventRepository = 
-    GetAll() ([]Event, error)
+    // InOrg 只看得到 / 只寫得進 orgID 的事件（Create 會設 e.OrgID）；0 → 不限
+    InOrg(orgID int64) EventRepository
+
+    GetAll(f EventFilter) ([]Event, error) // 公開列表：只回已發布（含沒有 status 的舊資料）
     GetByID(id string) (Event, error)
     Create(e *Event) error
+    // CreateMany 一次寫入多筆（匯入用），每筆的 Version / OrgID 同 Create；
+    // 依序寫入，中途失敗時前面的已寫入，回傳寫入的筆數
+    CreateMany(es []Event) (int, error)
+    // Update / Patch / Delete 都以 e.Version（或 version）為預期版本做條件寫入：
+    // 版本不符回 *VersionConflictError，成功後 e.Version 會是新版本
     Update(e *Event) error
-    Delete(id string) error
+    Patch(e *Event, fields []string) error // 只寫入 fields（json 欄位名）列出的欄位
+    Delete(id string, version int64) error  // 軟刪除：只設 deletedAt，GetAll / GetByID 看不到
+
+    // ===== 垃圾桶 =====
+    ListDeleted(ownerID int64) ([]Event, error) // ownerID=0 → 全部（admin）
+    GetDeleted(id string) (Event, error)
+    Restore(id string, version int64) error
+    PurgeDeleted(before time.Time) (int64, error) // 真正刪除 deletedAt < before 的事件
+
+    // Facets 已發布事件的標籤 / 分類數量（f 只看日期、分類、標籤）
+    Facets(f EventFilter) (Facets, error)
+
+    // PublishDue 把 publishAt <= now 的 scheduled 事件改為 published（version + 1），
+    // 回傳這次發布的事件「發布前」的內容，呼叫端要記修改歷程
+    PublishDue(now time.Time) ([]Event, error)
+}
+
+// ===== Revisions（事件修改歷程，只新增不修改）=====
+const (
+    RevisionCreate   = "create"
+    RevisionUpdate   = "update"
+    RevisionDelete   = "delete"
+    RevisionRestore  = "restore"
+    RevisionRollback = "rollback"
+)
+
+type Revision struct {
+    EventID  string                 `json:"eventId"`
+    OrgID    int64                  `json:"-" bson:"orgid,omitempty"` // 由 repository 設定
+    Rev      int64                  `json:"rev"` // = 寫入後的 Event.Version
+    Action   string                 `json:"action"`
+    UserID   int64                  `json:"userId"` // 操作者
+    At       time.Time              `json:"at"`
+    Changes  map[string]FieldChange `json:"changes"`            // 欄位層級 diff
+    Snapshot *Event                 `json:"snapshot,omitempty"` // 寫入後的完整內容（List 不回傳）
+    FromRev  int64                  `json:"fromRev,omitempty"`  // rollback 的來源版本
+}
+
+type RevisionRepository interface {
+    InOrg(orgID int64) RevisionRepository
+    Append(r *Revision) error
+    List(eventID string) ([]Revision, error) // 依 rev 由舊到新，不含 Snapshot
+    Get(eventID string, rev int64) (Revision, error)
+}
+
+// ===== Invitations（private 事件的受邀者，以 email 對應）=====
+type Invitation struct {
+    ID        int64     `json:"id"`
+    EventID   string    `json:"eventId"`
+    OrgID     int64     `json:"-"` // 由 repository 設定
+    Email     string    `json:"email"`
+    InvitedBy int64     `json:"invitedBy"`
+    CreatedAt time.Time `json:"createdAt"`
+}
+
+type InvitationRepository interface {
+    InOrg(orgID int64) InvitationRepository
+    Create(inv *Invitation) error // 同一事件重複邀請同一 email → ErrConflict
+    List(eventID string) ([]Invitation, error)
+    Delete(eventID string, id int64) error
+    IsInvited(eventID, email string) (bool, error)
+}
+
+// ===== Calendar feeds（個人行事曆訂閱的 token，見 calendar.go）=====
+type CalendarFeedRepository interface {
+    InOrg(orgID int64) CalendarFeedRepository
+    Rotate(userID int64, tokenHash string) (CalendarFeed, error) // 設定新的 token；舊的立即失效
+    Revoke(userID int64) error                                   // 沒有 token → ErrNotFound
+    ByToken(tokenHash string) (CalendarFeed, error)              // 找不到 → ErrNotFound
 }
 
 // ===== Users（維持你原本邏輯）=====
+const (
+    RoleUser  = "user"
+    RoleAdmin = "admin" // 可看全部垃圾桶、還原任何事件
+)
+
 type User struct {
     ID       int64  `json:"id"`
     Email    string `json:"email"`
     Password string `json:"password"`
+    Role     string `json:"role"`
 }
+// 使用者本身是全域的（email 唯一）；InOrg 之後只看得到該組織的成員與全域 admin，
+// Create 會同時把新使用者加入該組織（member）
 type UserRepository interface {
+    InOrg(orgID int64) UserRepository
     Create(u *User) error
     ValidateCredentials(email, plain string) (User, error)
     GetByID(id int64) (User, error)
+    GetByEmail(email string) (User, error) // 不分大小寫
 }
 
 // ===== Registrations =====
+type Registration struct {
+    ID          int64      `json:"id"` // 票券（QR code）裡的報名編號
+    UserID      int64      `json:"userId"`
+    EventID     string     `json:"eventId"`
+    Occurrence  string     `json:"occurrence,omitempty"`
+    Status      string     `json:"status"`
+    CreatedAt   time.Time  `json:"createdAt"`
+    CheckedInAt *time.Time `json:"checkedInAt,omitempty"` // 報到時間；nil = 還沒報到
+    CheckedInBy int64      `json:"checkedInBy,omitempty"` // 掃票的人
+    Answers     Answers    `json:"answers,omitempty"`     // 報名表單的答案（見 questions.go）
+    RSVP        string     `json:"rsvp"`                  // going / maybe / not_going（見 rsvp.go）
+    Guests      int        `json:"guests"`                // +N 同行者（只有 going 佔名額）
+    RespondedAt time.Time  `json:"respondedAt"`           // 最後一次回覆 RSVP 的時間
+}
+
+// Attendee 匯出名單用：報名加上報名者的 email
+type Attendee struct {
+    Registration
+    Email string `json:"email"`
+}
+
+const (
+    RegistrationActive = "active"
+    RegistrationVoided = "voided" // 事件取消後作廢
+)
+
+// occurrence：重複事件的單次 id（OccurrenceID）；一般事件為 ""
 type RegistrationRepository interface {
-    Register(userID int64, eventID string) error
-    Cancel(userID int64, eventID string) error
-    // 需要的話：ListByUser(userID), ListByEvent(eventID)...
+    InOrg(orgID int64) RegistrationRepository
+    // Register 寫入後 reg.ID / Status / CreatedAt / RespondedAt 會被設定；同一人同一場重複 → ErrConflict
+    // capacity > 0 時，going 超過名額（已佔 + 1 + guests）→ ErrConflict
+    Register(reg *Registration, capacity int) error
+    // UpdateRSVP 依 user / event / occurrence 改 active 報名的 RSVP 與同行人數，成功後 reg 為更新後的完整報名；
+    // 沒有報名 → ErrNotFound，名額不足同 Register
+    UpdateRSVP(reg *Registration, capacity int) error
+    RSVPCounts(eventID string) (map[string]RSVPCounts, error) // 依 occurrence 分開的回覆統計
+    Cancel(userID int64, eventID, occurrence string) error
+    VoidByEvent(eventID string) (int64, error) // 事件取消：作廢所有有效報名，回傳筆數
+    VoidByOccurrence(eventID, occurrence string) (int64, error) // 單次取消：只作廢該次的有效報名
+    ListByEvent(eventID string) ([]Registration, error) // 報名名單（含 voided）
+    ListByUser(userID int64) ([]Registration, error)    // 使用者自己的報名（含 voided），新的在前
+    // StreamAttendees 依 ListByEvent 的順序逐筆交給 fn，不把整份名單載入記憶體；occurrence 空白 → 全部場次。
+    // fn 回傳錯誤就停止並回傳該錯誤
+    StreamAttendees(eventID, occurrence string, fn func(Attendee) error) error
+    Get(id int64) (Registration, error)
+    // CheckIn 記錄報到；已報到過或報名不是 active → ErrConflict（條件更新，同一張票同時掃兩次也只會成功一次）
+    CheckIn(id, by int64, at time.Time) error
+
+    // ===== 轉讓與團體報名（見 transfers.go）=====
+    OfferTransfer(t *Transfer) error // 同一筆報名已有 pending 的轉讓 → ErrConflict
+    GetTransfer(id int64) (Transfer, error)
+    PendingTransfers(email string) ([]Transfer, error) // 寄給 email、還沒回覆的，新的在前
+    // AcceptTransfer transfer：報名改到 userID 名下，answers 取代原本的答案；group：用保留的名額為 userID 建立報名。
+    // 已處理過、報名已取消或已報到、userID 已報名 → ErrConflict。回傳接受後的報名
+    AcceptTransfer(id, userID int64, answers Answers) (Registration, error)
+    ResolveTransfer(id int64, status string) error // pending → declined / cancelled；已處理過 → ErrConflict
+    // RegisterGroup 同一個交易內建立 regs 的報名、為 holds 保留名額；任何一筆失敗（重複、額滿）整批不寫入。
+    // 寫入後 regs / holds 的 ID 等欄位會被設定
+    RegisterGroup(regs []Registration, holds []Transfer, capacity int) error
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRevisionRepo struct {
+	col *mongo.Collection
+	org int64
+}
+
+// NewMongoRevisionRepository 使用獨立的 collection（例如 event_revisions）
+// 建立 (eventid, rev) 唯一索引，同一版本不會被記兩次
+func NewMongoRevisionRepository(col *mongo.Collection) RevisionRepository {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _ = col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "eventid", Value: 1}, {Key: "rev", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return &mongoRevisionRepo{col: col}
+}
+
+func (r *mongoRevisionRepo) InOrg(orgID int64) RevisionRepository {
+	return &mongoRevisionRepo{col: r.col, org: orgID}
+}
+
+func (r *mongoRevisionRepo) Append(rev *Revision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if r.org != 0 {
+		rev.OrgID = r.org
+	}
+	_, err := r.col.InsertOne(ctx, rev)
+	return mapMongoErr(err)
+}
+
+func (r *mongoRevisionRepo) List(eventID string) ([]Revision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "rev", Value: 1}}).
+		SetProjection(bson.M{"snapshot": 0})
+	cur, err := r.col.Find(ctx, orgScope(bson.M{"eventid": eventID}, r.org), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := []Revision{}
+	for cur.Next(ctx) {
+		var rev Revision
+		if err := cur.Decode(&rev); err != nil {
+			return nil, err
+		}
+		out = append(out, rev)
+	}
+	return out, cur.Err()
+}
+
+func (r *mongoRevisionRepo) Get(eventID string, rev int64) (Revision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var out Revision
+	if err := r.col.FindOne(ctx, orgScope(bson.M{"eventid": eventID, "rev": rev}, r.org)).Decode(&out); err != nil {
+		return Revision{}, mapMongoErr(err)
+	}
+	return out, nil
+}
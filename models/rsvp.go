@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RSVP：報名時（或之後）回覆會不會到，可以帶 +N 位同行者。
+// 只有 going 佔名額：1 + 同行人數；事件的 capacity 是每一場（重複事件為每一單次）的名額上限
+const (
+	RSVPGoing    = "going"
+	RSVPMaybe    = "maybe"
+	RSVPNotGoing = "not_going"
+)
+
+const MaxGuests = 10
+
+// Seats 這筆報名佔的名額
+func (r Registration) Seats() int {
+	if r.Status != RegistrationActive || r.RSVP != RSVPGoing {
+		return 0
+	}
+	return 1 + r.Guests
+}
+
+// NormalizeRSVP 空白 → going；大小寫與 "not going" / "not-going" 寫法統一
+func NormalizeRSVP(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+	if s == "" {
+		return RSVPGoing
+	}
+	return s
+}
+
+// ValidateRSVP 檢查回覆與同行人數；不會到的人不能帶同行者
+func ValidateRSVP(rsvp string, guests int) error {
+	v := NewValidationError()
+	switch rsvp {
+	case RSVPGoing, RSVPMaybe, RSVPNotGoing:
+	default:
+		v.Add("rsvp", "must be going, maybe or not_going")
+	}
+	switch {
+	case guests < 0 || guests > MaxGuests:
+		v.Add("guests", fmt.Sprintf("must be between 0 and %d", MaxGuests))
+	case guests > 0 && rsvp == RSVPNotGoing:
+		v.Add("guests", "must be 0 when not going")
+	}
+	return v.OrNil()
+}
+
+func (e *Event) validateCapacity(v *ValidationError) {
+	switch {
+	case e.Capacity < 0:
+		v.Add("capacity", "must not be negative")
+	case e.Capacity > 0 && e.HasTickets():
+		v.Add("capacity", "cannot be combined with ticket types (use ticket quantities)")
+	}
+}
+
+// RSVPCounts 某一場的回覆統計（只算 active 報名）
+type RSVPCounts struct {
+	Going    int `json:"going"` // 人數，不含同行者
+	Maybe    int `json:"maybe"`
+	NotGoing int `json:"notGoing"`
+	Guests   int `json:"guests"` // going 帶的同行者
+	Held     int `json:"held"`   // 團體報名替還沒有帳號的人保留的名額（見 transfers.go）
+}
+
+// Attending 佔名額的人數：going + 同行者 + 保留的名額
+func (c RSVPCounts) Attending() int { return c.Going + c.Guests + c.Held }
+
+func (c *RSVPCounts) Add(o RSVPCounts) {
+	c.Going += o.Going
+	c.Maybe += o.Maybe
+	c.NotGoing += o.NotGoing
+	c.Guests += o.Guests
+	c.Held += o.Held
+}
+
+// Count 把一筆報名算進統計
+func (c *RSVPCounts) Count(r Registration) {
+	if r.Status != RegistrationActive {
+		return
+	}
+	switch r.RSVP {
+	case RSVPGoing:
+		c.Going++
+		c.Guests += r.Guests
+	case RSVPMaybe:
+		c.Maybe++
+	case RSVPNotGoing:
+		c.NotGoing++
+	}
+}
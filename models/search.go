@@ -0,0 +1,144 @@
+package models
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchIndex 事件全文搜尋；Mongo 用 text index（search_mongo.go），
+// 測試與 mock 用記憶體版（search_memory.go）
+type SearchIndex interface {
+	InOrg(orgID int64) SearchIndex // 只搜得到 orgID 的事件；0 → 不限
+	Search(q SearchQuery) ([]SearchHit, error)
+}
+
+type SearchQuery struct {
+	Text   string
+	Filter EventFilter // 只支援日期範圍（Mongo 的 $text 不能跟 $nearSphere 同時用）
+	Limit  int
+}
+
+// SearchHit 一筆結果；Highlights 只含有命中的欄位，命中字詞以 <mark> 包起來（其餘內容已 HTML escape）
+type SearchHit struct {
+	Event      Event             `json:"event"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	highlightSnippet   = 160 // description 這類長欄位只回命中附近的片段（字元數）
+)
+
+// 欄位權重：名稱 > 地點 > 描述（Mongo text index 用同一組）
+var searchWeights = map[string]int{"name": 10, "location": 3, "description": 1}
+
+// SearchTerms 把查詢拆成小寫字詞（去重）；分隔符是非字母數字
+func SearchTerms(q string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Highlights 對 name / description / location 標出命中字詞
+func Highlights(e Event, terms []string) map[string]string {
+	out := map[string]string{}
+	for field, text := range map[string]string{"name": e.Name, "description": e.Description, "location": e.Location} {
+		if h, ok := Highlight(text, terms, highlightSnippet); ok {
+			out[field] = h
+		}
+	}
+	return out
+}
+
+// Highlight 以 <mark> 標出 terms（不分大小寫）；超過 maxLen 字元時只取第一個命中附近的片段
+func Highlight(text string, terms []string, maxLen int) (string, bool) {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) { // 特殊字元小寫後長度會變，位置對不上就不標
+		return "", false
+	}
+	type span struct{ start, end int }
+	var spans []span
+	for i := 0; i < len(lower); {
+		best := 0
+		for _, t := range terms {
+			if len(t) > best && strings.HasPrefix(lower[i:], t) {
+				best = len(t)
+			}
+		}
+		if best > 0 {
+			spans = append(spans, span{i, i + best})
+			i += best
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(lower[i:])
+		i += size
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+
+	// 片段範圍（byte 位置，對齊字元邊界）
+	from, to := 0, len(text)
+	if maxLen > 0 && utf8.RuneCountInString(text) > maxLen {
+		from = backRunes(text, spans[0].start, maxLen/4)
+		to = forwardRunes(text, from, maxLen)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.start < from || s.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:s.start]))
+		b.WriteString("<mark>" + html.EscapeString(text[s.start:s.end]) + "</mark>")
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+func backRunes(s string, i, n int) int {
+	for ; n > 0 && i > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	return i
+}
+
+func forwardRunes(s string, i, n int) int {
+	for ; n > 0 && i < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return i
+}
+
+// NormalizeSearchLimit 0 → 預設，上限 MaxSearchLimit
+func NormalizeSearchLimit(n int) int {
+	switch {
+	case n <= 0:
+		return DefaultSearchLimit
+	case n > MaxSearchLimit:
+		return MaxSearchLimit
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// memorySearchIndex 直接掃 EventRepository.GetAll 的結果打分數；
+// 給 mock / 單元測試，以及沒設定搜尋索引時的後備
+type memorySearchIndex struct {
+	events EventRepository
+}
+
+func NewMemorySearchIndex(events EventRepository) SearchIndex {
+	return &memorySearchIndex{events: events}
+}
+
+func (m *memorySearchIndex) InOrg(orgID int64) SearchIndex {
+	return &memorySearchIndex{events: m.events.InOrg(orgID)}
+}
+
+// Search 與 Mongo $text 相同的語意：任一字詞命中即列入（OR），分數 = Σ 權重 × 出現次數
+func (m *memorySearchIndex) Search(q SearchQuery) ([]SearchHit, error) {
+	terms := SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+	events, err := m.events.GetAll(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []SearchHit{}
+	for _, e := range events {
+		score := 0.0
+		for field, text := range map[string]string{"name": e.Name, "description": e.Description, "location": e.Location} {
+			lower := strings.ToLower(text)
+			for _, t := range terms {
+				score += float64(searchWeights[field] * strings.Count(lower, t))
+			}
+		}
+		if score > 0 {
+			hits = append(hits, SearchHit{Event: e, Score: score, Highlights: Highlights(e, terms)})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Event.DateTime.Before(hits[j].Event.DateTime)
+	})
+	if limit := NormalizeSearchLimit(q.Limit); len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
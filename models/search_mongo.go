@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSearchIndex struct {
+	col *mongo.Collection
+	org int64
+}
+
+// NewMongoSearchIndex 在 events collection 上建 text index（name / description / location，含權重）
+func NewMongoSearchIndex(col *mongo.Collection) SearchIndex {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	weights := bson.D{}
+	for _, f := range []string{"name", "location", "description"} {
+		weights = append(weights, bson.E{Key: f, Value: searchWeights[f]})
+	}
+	_, _ = col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}, {Key: "location", Value: "text"}},
+		// default_language none：不做英文詞幹 / stop words，中文、混合語言比較好預期
+		Options: options.Index().SetName("event_text").SetWeights(weights).SetDefaultLanguage("none"),
+	})
+	return &mongoSearchIndex{col: col}
+}
+
+func (s *mongoSearchIndex) InOrg(orgID int64) SearchIndex {
+	return &mongoSearchIndex{col: s.col, org: orgID}
+}
+
+func (s *mongoSearchIndex) Search(q SearchQuery) ([]SearchHit, error) {
+	terms := SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := orgScope(listedFilter(EventFilter{From: q.Filter.From, To: q.Filter.To}), s.org)
+	filter["$text"] = bson.M{"$search": q.Text}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(int64(NormalizeSearchLimit(q.Limit)))
+
+	cur, err := s.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, mapMongoErr(err)
+	}
+	defer cur.Close(ctx)
+
+	hits := []SearchHit{}
+	for cur.Next(ctx) {
+		var doc struct {
+			Event `bson:",inline"`
+			Score float64 `bson:"score"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		hits = append(hits, SearchHit{Event: doc.Event, Score: doc.Score, Highlights: Highlights(doc.Event, terms)})
+	}
+	return hits, cur.Err()
+}
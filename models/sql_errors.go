@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Postgres 錯誤碼（SQLSTATE）
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+// mapSQLErr 把 database/sql / pq 的錯誤轉成領域錯誤；其他錯誤原樣回傳（→ 5xx）
+func mapSQLErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return fmt.Errorf("%w: %s", ErrConflict, pqErr.Constraint)
+		case pqForeignKeyViolation:
+			return fmt.Errorf("%w: %s", ErrNotFound, pqErr.Constraint)
+		}
+	}
+	return err
+}
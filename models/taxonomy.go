@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 分類與標籤：一律存小寫、去頭尾空白；標籤去重
+const (
+	MaxEventTags   = 20
+	MaxTagLen      = 30
+	MaxCategoryLen = 50
+)
+
+// FacetCount 某個標籤 / 分類的事件數
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type Facets struct {
+	Tags       []FacetCount `json:"tags"`
+	Categories []FacetCount `json:"categories"`
+}
+
+// NormalizeTag 小寫、去空白，內部空白換成 "-"
+func NormalizeTag(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "-")
+}
+
+// NormalizeTaxonomy 整理 Category / Tags（建立、更新前呼叫）
+func (e *Event) NormalizeTaxonomy() {
+	e.Category = NormalizeTag(e.Category)
+	if len(e.Tags) == 0 {
+		e.Tags = nil
+		return
+	}
+	seen := map[string]bool{}
+	tags := make([]string, 0, len(e.Tags))
+	for _, t := range e.Tags {
+		if t = NormalizeTag(t); t != "" && !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	e.Tags = tags
+}
+
+func validSlug(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Event) validateTaxonomy(v *ValidationError) {
+	if utf8.RuneCountInString(e.Category) > MaxCategoryLen || !validSlug(e.Category) {
+		v.Add("category", fmt.Sprintf("must be at most %d letters, digits, '-' or '_'", MaxCategoryLen))
+	}
+	if len(e.Tags) > MaxEventTags {
+		v.Add("tags", fmt.Sprintf("must have at most %d tags", MaxEventTags))
+	}
+	for _, t := range e.Tags {
+		if t == "" || utf8.RuneCountInString(t) > MaxTagLen || !validSlug(t) {
+			v.Add("tags", fmt.Sprintf("each tag must be 1-%d letters, digits, '-' or '_'", MaxTagLen))
+			break
+		}
+	}
+}
+
+// HasTags 是否包含全部 tags
+func (e *Event) HasTags(tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, t := range e.Tags {
+			if t == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// CountFacets 在記憶體裡算 facet（給 mock；Mongo 用 aggregation），排序同 Mongo：數量多到少、同數量依字母
+func CountFacets(events []Event) Facets {
+	tags, cats := map[string]int64{}, map[string]int64{}
+	for _, e := range events {
+		for _, t := range e.Tags {
+			tags[t]++
+		}
+		if e.Category != "" {
+			cats[e.Category]++
+		}
+	}
+	return Facets{Tags: sortedFacets(tags), Categories: sortedFacets(cats)}
+}
+
+func sortedFacets(m map[string]int64) []FacetCount {
+	out := make([]FacetCount, 0, len(m))
+	for v, n := range m {
+		out = append(out, FacetCount{Value: v, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
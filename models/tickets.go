@@ -0,0 +1,126 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 票種：每個事件可以有多個票種（早鳥、一般、學生…），存在事件文件裡。
+// 沒有票種的事件維持舊行為：免費報名，不建立訂單。
+const (
+	MaxTicketTypes       = 20
+	MaxTicketTypeNameLen = 60
+)
+
+type TicketType struct {
+	ID         string     `json:"id"` // 建立時自動產生；更新時帶回原 id 才會沿用已售數量
+	Name       string     `json:"name"`
+	Price      int64      `json:"price"`              // 最小貨幣單位（USD 為 cents、TWD 為元）；0 = 免費
+	Currency   string     `json:"currency,omitempty"` // ISO 4217（大寫），price > 0 時必填
+	Quantity   int        `json:"quantity"`           // 每場（重複事件為每個單次）的數量上限；0 = 不限
+	SalesStart *time.Time `json:"salesStart,omitempty"`
+	SalesEnd   *time.Time `json:"salesEnd,omitempty"`
+}
+
+// OnSale 是否在販售期間內（沒設定的一端視為不限）
+func (t TicketType) OnSale(now time.Time) bool {
+	if t.SalesStart != nil && now.Before(*t.SalesStart) {
+		return false
+	}
+	if t.SalesEnd != nil && !now.Before(*t.SalesEnd) {
+		return false
+	}
+	return true
+}
+
+// HasTickets 事件是否走購票流程
+func (e *Event) HasTickets() bool { return len(e.TicketTypes) > 0 }
+
+// TicketType 依 id 找票種；id 為空且只有一種票時直接回傳那一種
+func (e *Event) TicketType(id string) (TicketType, bool) {
+	if id == "" && len(e.TicketTypes) == 1 {
+		return e.TicketTypes[0], true
+	}
+	for _, t := range e.TicketTypes {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return TicketType{}, false
+}
+
+// NormalizeTickets 補上票種 id、整理名稱與幣別、時間轉 UTC（建立、更新前呼叫）
+func (e *Event) NormalizeTickets() {
+	if len(e.TicketTypes) == 0 {
+		e.TicketTypes = nil
+		return
+	}
+	for i := range e.TicketTypes {
+		t := &e.TicketTypes[i]
+		if t.ID == "" {
+			t.ID = newTicketTypeID()
+		}
+		t.Name = strings.TrimSpace(t.Name)
+		t.Currency = strings.ToUpper(strings.TrimSpace(t.Currency))
+		t.SalesStart = utcPtr(t.SalesStart)
+		t.SalesEnd = utcPtr(t.SalesEnd)
+	}
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func newTicketTypeID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "tt_" + hex.EncodeToString(b)
+}
+
+func validCurrency(c string) bool {
+	if len(c) != 3 {
+		return false
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Event) validateTickets(v *ValidationError) {
+	if len(e.TicketTypes) > MaxTicketTypes {
+		v.Add("ticketTypes", fmt.Sprintf("must have at most %d ticket types", MaxTicketTypes))
+		return
+	}
+	seen := map[string]bool{}
+	for i, t := range e.TicketTypes {
+		field := fmt.Sprintf("ticketTypes[%d]", i)
+		switch {
+		case t.Name == "" || utf8.RuneCountInString(t.Name) > MaxTicketTypeNameLen:
+			v.Add(field+".name", fmt.Sprintf("is required and must be at most %d characters", MaxTicketTypeNameLen))
+		case t.Price < 0:
+			v.Add(field+".price", "must not be negative")
+		case t.Price > 0 && !validCurrency(t.Currency):
+			v.Add(field+".currency", "must be an ISO 4217 code such as USD")
+		case t.Currency != "" && !validCurrency(t.Currency):
+			v.Add(field+".currency", "must be an ISO 4217 code such as USD")
+		case t.Quantity < 0:
+			v.Add(field+".quantity", "must not be negative")
+		case t.SalesStart != nil && t.SalesEnd != nil && !t.SalesEnd.After(*t.SalesStart):
+			v.Add(field+".salesEnd", "must be after salesStart")
+		case seen[t.ID]:
+			v.Add(field+".id", "must be unique")
+		}
+		seen[t.ID] = true
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+	_ "time/tzdata" // distroless 映像不一定有 zoneinfo，內嵌一份
+)
+
+// 時間一律以 UTC 儲存與輸出；TimeZone（IANA，例如 Asia/Taipei）只決定
+// 「當地時間」的呈現與重複事件的展開（跨夏令時間維持當地時刻）。
+// 沒有 TimeZone 的舊資料視為 UTC，不受伺服器 TZ 環境變數影響。
+
+// LocalTimes 回應裡附帶的當地時間
+type LocalTimes struct {
+	TimeZone string     `json:"timeZone"`
+	DateTime time.Time  `json:"dateTime"`
+	EndTime  *time.Time `json:"endTime,omitempty"`
+}
+
+// Zone 事件的時區；未設定或無效 → UTC（Location 已是地點欄位）
+func (e *Event) Zone() *time.Location {
+	if e.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Duration EndTime - DateTime；沒有結束時間 → 0
+func (e *Event) Duration() time.Duration {
+	if e.EndTime == nil {
+		return 0
+	}
+	return e.EndTime.Sub(e.DateTime)
+}
+
+// NormalizeTimes 把時間轉成 UTC（輸入可以帶任何 offset）
+func (e *Event) NormalizeTimes() {
+	e.DateTime = e.DateTime.UTC()
+	if e.EndTime != nil {
+		t := e.EndTime.UTC()
+		e.EndTime = &t
+	}
+}
+
+func (e *Event) validateTimes(v *ValidationError) {
+	if e.TimeZone != "" {
+		if _, err := time.LoadLocation(e.TimeZone); err != nil || e.TimeZone == "Local" {
+			v.Add("timeZone", "must be an IANA time zone name, e.g. Asia/Taipei")
+		}
+	}
+	if e.EndTime != nil && !e.DateTime.IsZero() && !e.EndTime.After(e.DateTime) {
+		v.Add("endTime", "must be after dateTime")
+	}
+}
+
+// MarshalJSON 時間輸出為 UTC，另外附上 local（事件時區的當地時間）
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plain Event // 避免遞迴呼叫 MarshalJSON
+	e.NormalizeTimes()
+	loc := e.Zone()
+	local := &LocalTimes{TimeZone: loc.String(), DateTime: e.DateTime.In(loc)}
+	if e.EndTime != nil {
+		end := e.EndTime.In(loc)
+		local.EndTime = &end
+	}
+	return json.Marshal(struct {
+		plain
+		Local *LocalTimes `json:"local"`
+	}{plain(e), local})
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// 轉讓與團體報名：兩者都是「交給某個 email 的名額」，等對方接受
+//   transfer：把自己的報名讓給別人；接受後報名改到對方名下（原本的票因為 userId 不符而失效），
+//             有付款的訂單也一起轉過去，之後取消仍退款到原付款方式
+//   group：  團體報名時替還沒有帳號的人保留的名額（佔 capacity），對方註冊後接受就用這個名額報名
+// 已有帳號的人在團體報名時直接報名，不需要接受
+const (
+	TransferRegistration = "transfer"
+	TransferGroupSeat    = "group"
+)
+
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"  // 收件人拒絕
+	TransferCancelled = "cancelled" // 發出的人撤回
+)
+
+const MaxGroupSize = 20
+
+type Transfer struct {
+	ID             int64      `json:"id"`
+	OrgID          int64      `json:"-"` // 由 repository 設定
+	Kind           string     `json:"kind"`
+	EventID        string     `json:"eventId"`
+	Occurrence     string     `json:"occurrence,omitempty"`
+	RegistrationID int64      `json:"registrationId,omitempty"` // transfer：要轉出的報名
+	FromUserID     int64      `json:"fromUserId"`               // 轉出的人 / 團體報名的人
+	Email          string     `json:"email"`                    // 收件人（NormalizeEmail 過）
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+)
+
+// 轉讓與團體報名也是 sqlRegistrationRepo 的一部分：接受轉讓、團體報名都要跟 registrations 在同一個交易裡
+
+const transferColumns = `id, org_id, kind, event_id, occurrence, COALESCE(registration_id, 0), from_user_id, email, status, created_at, resolved_at`
+
+func scanTransfer(row interface{ Scan(...any) error }) (Transfer, error) {
+    var t Transfer
+    var resolved sql.NullTime
+    err := row.Scan(&t.ID, &t.OrgID, &t.Kind, &t.EventID, &t.Occurrence, &t.RegistrationID, &t.FromUserID, &t.Email, &t.Status, &t.CreatedAt, &resolved)
+    if err != nil { return Transfer{}, mapSQLErr(err) }
+    if resolved.Valid {
+        at := resolved.Time.UTC()
+        t.ResolvedAt = &at
+    }
+    return t, nil
+}
+
+// insertTransfer 寫入一筆 pending；q 是 *sql.DB 或 *sql.Tx
+func insertTransfer(q interface{ QueryRow(string, ...any) *sql.Row }, t *Transfer, org int64) error {
+    t.OrgID, t.Status = insertOrg(org), TransferPending
+    var regID any // group 沒有報名 → NULL
+    if t.RegistrationID != 0 { regID = t.RegistrationID }
+    // 部分唯一索引：同一筆報名、同一場同一個 email 只能有一筆 pending → ErrConflict
+    err := q.QueryRow(`INSERT INTO registration_transfers(org_id, kind, event_id, occurrence, registration_id, from_user_id, email, status)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id, created_at`,
+        t.OrgID, t.Kind, t.EventID, t.Occurrence, regID, t.FromUserID, t.Email, t.Status).Scan(&t.ID, &t.CreatedAt)
+    return mapSQLErr(err)
+}
+
+func (r *sqlRegistrationRepo) OfferTransfer(t *Transfer) error {
+    return insertTransfer(r.db, t, r.org)
+}
+
+func (r *sqlRegistrationRepo) GetTransfer(id int64) (Transfer, error) {
+    return scanTransfer(r.db.QueryRow(`SELECT `+transferColumns+` FROM registration_transfers WHERE id=$1 AND ($2::bigint = 0 OR org_id = $2)`, id, r.org))
+}
+
+func (r *sqlRegistrationRepo) PendingTransfers(email string) ([]Transfer, error) {
+    rows, err := r.db.Query(`SELECT `+transferColumns+` FROM registration_transfers
+        WHERE email=$1 AND status=$2 AND ($3::bigint = 0 OR org_id = $3) ORDER BY created_at DESC, id DESC`, email, TransferPending, r.org)
+    if err != nil { return nil, mapSQLErr(err) }
+    defer rows.Close()
+
+    out := []Transfer{}
+    for rows.Next() {
+        t, err := scanTransfer(rows)
+        if err != nil { return nil, err }
+        out = append(out, t)
+    }
+    return out, rows.Err()
+}
+
+func (r *sqlRegistrationRepo) AcceptTransfer(id, userID int64, answers Answers) (Registration, error) {
+    tx, err := r.db.Begin()
+    if err != nil { return Registration{}, err }
+    defer tx.Rollback()
+
+    // FOR UPDATE：同時接受與撤回只有一個會成功
+    t, err := scanTransfer(tx.QueryRow(`SELECT `+transferColumns+` FROM registration_transfers
+        WHERE id=$1 AND ($2::bigint = 0 OR org_id = $2) FOR UPDATE`, id, r.org))
+    if err != nil { return Registration{}, err }
+    if t.Status != TransferPending { return Registration{}, fmt.Errorf("%w: transfer is already %s", ErrConflict, t.Status) }
+
+    var reg Registration
+    switch t.Kind {
+    case TransferRegistration:
+        // 對方已報名 → UNIQUE 衝突 → ErrConflict
+        reg, err = scanRegistration(tx.QueryRow(`UPDATE registrations SET user_id=$3, answers=$4, responded_at=now()
+            WHERE id=$1 AND user_id=$2 AND status=$5 AND checked_in_at IS NULL RETURNING `+registrationColumns,
+            t.RegistrationID, t.FromUserID, userID, answers, RegistrationActive))
+        if errors.Is(err, ErrNotFound) { err = fmt.Errorf("%w: registration was cancelled or already checked in", ErrConflict) }
+        if err != nil { return Registration{}, err }
+        // 已付款的訂單跟著報名走：新的持有人取消時才找得到訂單、才會退款
+        if _, err := tx.Exec(`UPDATE orders SET user_id=$2, updated_at=now() WHERE user_id=$1 AND event_id=$3 AND occurrence=$4 AND status=$5`,
+            t.FromUserID, userID, t.EventID, t.Occurrence, OrderPaid); err != nil {
+            return Registration{}, mapSQLErr(err)
+        }
+    case TransferGroupSeat:
+        // 名額在團體報名時已經保留，這裡不再檢查 capacity
+        reg = Registration{UserID: userID, EventID: t.EventID, Occurrence: t.Occurrence, Answers: answers}
+        if err := insertRegistration(tx, &reg, t.OrgID); err != nil { return Registration{}, err }
+    default:
+        return Registration{}, fmt.Errorf("unknown transfer kind %q", t.Kind)
+    }
+    if _, err := tx.Exec(`UPDATE registration_transfers SET status=$2, resolved_at=now() WHERE id=$1`, id, TransferAccepted); err != nil {
+        return Registration{}, mapSQLErr(err)
+    }
+    return reg, mapSQLErr(tx.Commit())
+}
+
+func (r *sqlRegistrationRepo) ResolveTransfer(id int64, status string) error {
+    res, err := r.db.Exec(`UPDATE registration_transfers SET status=$2, resolved_at=now() WHERE id=$1 AND status=$3 AND ($4::bigint = 0 OR org_id = $4)`,
+        id, status, TransferPending, r.org)
+    if err != nil { return mapSQLErr(err) }
+    if n, err := res.RowsAffected(); err == nil && n == 1 { return nil }
+    t, err := r.GetTransfer(id)
+    if err != nil { return err }
+    return fmt.Errorf("%w: transfer is already %s", ErrConflict, t.Status)
+}
+
+func (r *sqlRegistrationRepo) RegisterGroup(regs []Registration, holds []Transfer, capacity int) error {
+    seats := len(holds)
+    for _, reg := range regs { seats += 1 + reg.Guests }
+    if seats == 0 { return nil }
+    eventID, occurrence := "", ""
+    if len(regs) > 0 {
+        eventID, occurrence = regs[0].EventID, regs[0].Occurrence
+    } else {
+        eventID, occurrence = holds[0].EventID, holds[0].Occurrence
+    }
+
+    tx, err := r.db.Begin()
+    if err != nil { return err }
+    defer tx.Rollback()
+
+    if err := reserveSeats(tx, eventID, occurrence, 0, seats, capacity); err != nil { return err }
+    for i := range regs {
+        if err := insertRegistration(tx, &regs[i], insertOrg(r.org)); err != nil {
+            if errors.Is(err, ErrConflict) { return fmt.Errorf("%w: user %d is already registered", ErrConflict, regs[i].UserID) }
+            return err
+        }
+    }
+    for i := range holds {
+        if err := insertTransfer(tx, &holds[i], r.org); err != nil {
+            if errors.Is(err, ErrConflict) { return fmt.Errorf("%w: a seat is already held for %s", ErrConflict, holds[i].Email) }
+            return err
+        }
+    }
+    return mapSQLErr(tx.Commit())
+}
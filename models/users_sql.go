@@ -6,9 +6,15 @@ import (
 	"restapi/utils" // 這裡假設你在 utils 裡有 HashPassword / CheckPasswordHash
 )
 
-type sqlUserRepo struct{ db *sql.DB } //真db 下面做他的與db的操作 //實現介面方法
+type sqlUserRepo struct{ db *sql.DB; org int64 } //真db 下面做他的與db的操作 //實現介面方法
 
-func NewSQLUserRepository(db *sql.DB) UserRepository { return &sqlUserRepo{db} }
+func NewSQLUserRepository(db *sql.DB) UserRepository { return &sqlUserRepo{db: db} }
+
+func (r *sqlUserRepo) InOrg(orgID int64) UserRepository { return &sqlUserRepo{db: r.db, org: orgID} }
+
+// inOrg 只看得到該組織的成員與全域 admin（org=0 → 不限）
+const inOrg = `($2::bigint = 0 OR u.role = 'admin' OR EXISTS (
+	SELECT 1 FROM org_memberships m WHERE m.user_id = u.id AND m.org_id = $2))`
 
 func (r *sqlUserRepo) Create(u *User) error {
 	// 假設 u.Password 目前是 plain text → 先雜湊
@@ -18,21 +24,40 @@ func (r *sqlUserRepo) Create(u *User) error {
 	}
 	u.Password = hashed
 
-	_, err = r.db.Exec(`INSERT INTO users(email, password) VALUES ($1,$2)`, u.Email, u.Password)
-	return err
+	// 使用者與組織成員資格一起寫入
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`INSERT INTO users(email, password) VALUES ($1,$2) RETURNING id, role`, u.Email, u.Password).
+		Scan(&u.ID, &u.Role)
+	if err != nil {
+		return mapSQLErr(err) // email 重複 → ErrConflict
+	}
+	if r.org != 0 {
+		if _, err := tx.Exec(`INSERT INTO org_memberships(org_id, user_id, role) VALUES ($1,$2,$3)`, r.org, u.ID, OrgMember); err != nil {
+			return mapSQLErr(err)
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *sqlUserRepo) ValidateCredentials(email, plain string) (User, error) {
 	var u User
-	err := r.db.QueryRow(`SELECT id, email, password FROM users WHERE email=$1`, email).
-		Scan(&u.ID, &u.Email, &u.Password)
+	err := r.db.QueryRow(`SELECT u.id, u.email, u.password, u.role FROM users u WHERE u.email=$1 AND `+inOrg, email, r.org).
+		Scan(&u.ID, &u.Email, &u.Password, &u.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrInvalidCredentials // 不透露帳號是否存在
+	}
 	if err != nil {
 		return User{}, err
 	}
 
 	// 用 bcrypt 比對 plain vs hashed
 	if !utils.CheckPasswordHash(plain, u.Password) {
-		return User{}, errors.New("invalid credentials")
+		return User{}, ErrInvalidCredentials
 	}
 
 	return u, nil
@@ -40,10 +65,20 @@ func (r *sqlUserRepo) ValidateCredentials(email, plain string) (User, error) {
 
 func (r *sqlUserRepo) GetByID(id int64) (User, error) {
 	var u User
-	err := r.db.QueryRow(`SELECT id, email FROM users WHERE id=$1`, id).
-		Scan(&u.ID, &u.Email)
+	err := r.db.QueryRow(`SELECT u.id, u.email, u.role FROM users u WHERE u.id=$1 AND `+inOrg, id, r.org).
+		Scan(&u.ID, &u.Email, &u.Role)
 	if err != nil {
-		return User{}, err
+		return User{}, mapSQLErr(err)
+	}
+	return u, nil
+}
+
+func (r *sqlUserRepo) GetByEmail(email string) (User, error) {
+	var u User
+	err := r.db.QueryRow(`SELECT u.id, u.email, u.role FROM users u WHERE lower(u.email)=lower($1) AND `+inOrg, email, r.org).
+		Scan(&u.ID, &u.Email, &u.Role)
+	if err != nil {
+		return User{}, mapSQLErr(err)
 	}
 	return u, nil
 }
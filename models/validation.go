@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 事件欄位長度上限（以字元數計，不是 byte）
+const (
+	MaxEventNameLen        = 100
+	MaxEventDescriptionLen = 2000
+	MaxEventLocationLen    = 200
+)
+
+// Validate 檢查事件欄位；create 與 update 共用同一套規則
+// now 由呼叫端傳入，方便測試固定時間
+func (e *Event) Validate(now time.Time) error {
+	v := NewValidationError()
+
+	name := strings.TrimSpace(e.Name)
+	switch {
+	case name == "":
+		v.Add("name", "is required")
+	case utf8.RuneCountInString(name) > MaxEventNameLen:
+		v.Add("name", fmt.Sprintf("must be at most %d characters", MaxEventNameLen))
+	}
+	if utf8.RuneCountInString(e.Description) > MaxEventDescriptionLen {
+		v.Add("description", fmt.Sprintf("must be at most %d characters", MaxEventDescriptionLen))
+	}
+	if utf8.RuneCountInString(e.Location) > MaxEventLocationLen {
+		v.Add("location", fmt.Sprintf("must be at most %d characters", MaxEventLocationLen))
+	}
+	switch {
+	case e.DateTime.IsZero():
+		v.Add("dateTime", "is required")
+	case !e.DateTime.After(now):
+		v.Add("dateTime", "must be in the future")
+	}
+	e.validateTimes(v)
+	e.validateGeo(v)
+	e.validateTaxonomy(v)
+	e.validateVisibility(v)
+	e.validateRecurrence(v)
+	e.validateTickets(v)
+	e.validateQuestions(v)
+	e.validateCapacity(v)
+
+	return v.OrNil()
+}
+
+// ValidateUpdate 同 Validate，但 dateTime 沒改時不要求「在未來」
+// （已經開始的活動仍可修改描述、地點）
+func (e *Event) ValidateUpdate(old Event, now time.Time) error {
+	err := e.Validate(now)
+	var v *ValidationError
+	if errors.As(err, &v) && e.DateTime.Equal(old.DateTime) && !e.DateTime.IsZero() {
+		delete(v.Fields, "dateTime")
+		return v.OrNil()
+	}
+	return err
+}
+
+// ValidateEmail 依 RFC 5322 解析，且只接受純位址（不接受 "Name <a@b.com>"）
+func ValidateEmail(email string) error {
+	v := NewValidationError()
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		v.Add("email", "must be a valid email address")
+	}
+	return v.OrNil()
+}
+
+// PasswordPolicy 密碼強度規則；由 main 依環境設定，未設定時用 DefaultPasswordPolicy
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+// Validate 回傳 field=password 的 ValidationError，訊息列出所有未達成的條件
+func (p PasswordPolicy) Validate(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		missing = append(missing, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+
+	v := NewValidationError()
+	if len(missing) > 0 {
+		v.Add("password", "must contain "+strings.Join(missing, ", "))
+	}
+	return v.OrNil()
+}
@@ -0,0 +1,34 @@
+package models
+
+import "strings"
+
+// 可見度：
+//   public   出現在列表 / 搜尋，任何人可讀
+//   unlisted 不出現在列表 / 搜尋，知道 id 的人可讀
+//   private  只有擁有者、被邀請的人、持有效分享 token 的人可讀
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+// EffectiveVisibility 舊資料沒有 visibility，視為 public
+func (e *Event) EffectiveVisibility() string {
+	if e.Visibility == "" {
+		return VisibilityPublic
+	}
+	return e.Visibility
+}
+
+func (e *Event) IsPrivate() bool { return e.EffectiveVisibility() == VisibilityPrivate }
+
+func (e *Event) validateVisibility(v *ValidationError) {
+	switch e.Visibility {
+	case "", VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+	default:
+		v.Add("visibility", "must be one of public, unlisted, private")
+	}
+}
+
+// NormalizeEmail 邀請以 email 比對，一律小寫
+func NormalizeEmail(email string) string { return strings.ToLower(strings.TrimSpace(email)) }
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// iCalendar 匯出（見 utils/ical.go）：
+//   GET    /events/:id.ics              單一事件；權限同 GET /events/:id（private 事件用 ?share=）
+//   POST   /users/me/calendar-feed      產生（或重新產生）個人訂閱網址，舊的立即失效
+//   DELETE /users/me/calendar-feed      撤銷
+//   GET    /calendar/:token.ics         個人訂閱：我報名的事件（含已取消的，標成 CANCELLED 讓 app 移除）
+// 行事曆 app 不會帶 Authorization header，訂閱網址裡的 token 就是憑證
+const (
+	icsSuffix          = ".ics"
+	calendarFeedName   = "My events"
+	calendarRefresh    = time.Hour
+	icsSeriesLookback  = 90 * 24 * time.Hour  // 重複事件：從最近 90 天（或系列開始）
+	icsSeriesLookahead = 365 * 24 * time.Hour // 到之後一年的每一次
+)
+
+func writeICS(c *gin.Context, cal utils.ICalendar) {
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", cal.Bytes(time.Now()))
+}
+
+// eventURL 事件在 API 上的網址，放在 VEVENT 的 URL
+func eventURL(c *gin.Context, id string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/events/" + id
+}
+
+// icalEvent 事件（或重複事件的某一次）轉成 VEVENT；cancelled 表示報名已作廢
+func icalEvent(c *gin.Context, ev models.Event, occ *models.Occurrence, cancelled bool) utils.ICalEvent {
+	ie := utils.ICalEvent{
+		UID:         ev.ID,
+		Sequence:    ev.Version,
+		Start:       ev.DateTime,
+		End:         ev.EndTime,
+		Summary:     ev.Name,
+		Description: ev.Description,
+		Location:    ev.Location,
+		URL:         eventURL(c, ev.ID),
+		Status:      utils.ICalConfirmed,
+	}
+	if occ != nil {
+		ie.UID += "-" + occ.ID
+		ie.Start, ie.End = occ.Start, occ.End
+		cancelled = cancelled || occ.Cancelled
+	}
+	if ev.Geo != nil {
+		ie.Geo = &[2]float64{ev.Geo.Coordinates[1], ev.Geo.Coordinates[0]} // GeoJSON 是 [lng, lat]，GEO 是 lat;lng
+	}
+	if ev.Category != "" {
+		ie.Categories = append(ie.Categories, ev.Category)
+	}
+	ie.Categories = append(ie.Categories, ev.Tags...)
+	switch status := ev.EffectiveStatus(); {
+	case cancelled || status == models.StatusCancelled:
+		ie.Status = utils.ICalCancelled
+	case status == models.StatusDraft || status == models.StatusScheduled:
+		ie.Status = utils.ICalTentative
+	}
+	return ie
+}
+
+// GET /events/:id.ics（getEvent 依副檔名轉過來）
+// 重複事件展開成一筆一筆的 VEVENT（最近 90 天到未來一年；還沒開始的系列從第一次起算一年）
+func (d *deps) getEventICS(c *gin.Context, id string) {
+	ev, err := d.events.GetByID(id)
+	if err == nil && !d.canView(c, ev) {
+		err = models.ErrNotFound
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+
+	cal := utils.ICalendar{Name: ev.Name}
+	if ev.IsRecurring() {
+		now := time.Now()
+		from := ev.DateTime
+		if lb := now.Add(-icsSeriesLookback); from.Before(lb) {
+			from = lb
+		}
+		to := now
+		if from.After(now) {
+			to = from
+		}
+		occs, err := ev.Occurrences(from, to.Add(icsSeriesLookahead), models.MaxOccurrences)
+		if err != nil {
+			respondError(c, err, "Could not expand occurrences.")
+			return
+		}
+		for i := range occs {
+			cal.Events = append(cal.Events, icalEvent(c, ev, &occs[i], false))
+		}
+	} else {
+		cal.Events = []utils.ICalEvent{icalEvent(c, ev, nil, false)}
+	}
+	noStoreIfPrivate(c, ev)
+	setETag(c, ev.Version)
+	c.Header("Content-Disposition", `attachment; filename="`+ev.ID+icsSuffix+`"`)
+	writeICS(c, cal)
+}
+
+// POST /users/me/calendar-feed
+// 回傳的 token 只會出現這一次（資料庫只存雜湊）
+func (d *deps) createCalendarFeed(c *gin.Context) {
+	token, err := models.NewCalendarToken()
+	if err != nil {
+		respondError(c, err, "Could not create calendar feed.")
+		return
+	}
+	feed, err := d.feeds.Rotate(c.GetInt64("userId"), models.CalendarTokenHash(token))
+	if err != nil {
+		respondError(c, err, "Could not create calendar feed.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     token,
+		"createdAt": feed.CreatedAt,
+		"path":      "/calendar/" + token + icsSuffix,
+	})
+}
+
+// DELETE /users/me/calendar-feed
+func (d *deps) revokeCalendarFeed(c *gin.Context) {
+	if err := d.feeds.Revoke(c.GetInt64("userId")); err != nil {
+		respondError(c, err, "Could not revoke calendar feed.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked."})
+}
+
+// GET /calendar/:token.ics
+// 不屬於任何 tenant 的請求也能用：以 token 所屬的組織為準（同 payment webhook）
+func (d *deps) calendarFeed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("token"), icsSuffix)
+	var feed models.CalendarFeed
+	err := models.ErrNotFound
+	if ok && token != "" {
+		feed, err = d.feeds.ByToken(models.CalendarTokenHash(token))
+	}
+	if org := c.GetInt64("orgId"); err == nil && org != 0 && org != models.OrgOf(feed.OrgID) {
+		err = models.ErrNotFound // 別的組織的網域
+	}
+	if err != nil {
+		respondError(c, err, "Calendar feed not found.")
+		return
+	}
+	sd := d.inOrg(models.OrgOf(feed.OrgID))
+
+	regs, err := sd.regs.ListByUser(feed.UserID)
+	if err != nil {
+		respondError(c, err, "Could not fetch registrations.")
+		return
+	}
+	cal := utils.ICalendar{Name: calendarFeedName, Refresh: calendarRefresh, Events: []utils.ICalEvent{}}
+	events := map[string]*models.Event{} // 同一系列的多場報名只查一次；nil = 已刪除
+	for _, r := range regs {
+		ev, seen := events[r.EventID]
+		if !seen {
+			got, err := sd.events.GetByID(r.EventID)
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+			case err != nil:
+				respondError(c, err, "Could not fetch events.")
+				return
+			default:
+				ev = &got
+			}
+			events[r.EventID] = ev
+		}
+		if ev == nil {
+			continue
+		}
+		voided := r.Status != models.RegistrationActive
+		if r.Occurrence == "" {
+			cal.Events = append(cal.Events, icalEvent(c, *ev, nil, voided))
+			continue
+		}
+		occ, err := ev.FindOccurrence(r.Occurrence)
+		if err != nil {
+			continue // 規則改過，這一場已經不存在
+		}
+		cal.Events = append(cal.Events, icalEvent(c, *ev, &occ, voided))
+	}
+	sort.SliceStable(cal.Events, func(i, j int) bool { return cal.Events[i].Start.Before(cal.Events[j].Start) })
+
+	c.Header("Cache-Control", "private, no-store") // 個人資料，也不能進 ResponseCache
+	writeICS(c, cal)
+}
@@ -0,0 +1,215 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// 票券與報到：
+//   每筆 active、RSVP going 的報名都有一張票，內容是簽章過的 token（報名 id + 使用者 + 事件 + 單次），以 QR code 呈現
+//   報到人員（PermCheckIn）掃票 → POST /events/:id/checkin，驗簽後以條件更新記錄報到時間，同一張票只能用一次
+// 取消後重新報名會拿到新的報名 id，舊票自然失效
+
+const ticketQRScale = 8 // PNG 每個模組的像素數
+
+// GET /users/me/registrations
+func (d *deps) listMyRegistrations(c *gin.Context) {
+	regs, err := d.regs.ListByUser(c.GetInt64("userId"))
+	if err != nil {
+		respondError(c, err, "Could not fetch registrations.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, regs)
+}
+
+// GET /users/me/registrations/:id/ticket?format=png|svg|json
+// 預設 PNG；json 回傳 token 本身（給自己畫 QR code 的 app）
+func (d *deps) getTicket(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid registration id.")
+		return
+	}
+	format := c.DefaultQuery("format", "png")
+	if format != "png" && format != "svg" && format != "json" {
+		respondBadRequest(c, "format must be png, svg or json.")
+		return
+	}
+	reg, err := d.regs.Get(id)
+	if err == nil && reg.UserID != c.GetInt64("userId") {
+		err = models.ErrNotFound // 別人的報名當作不存在
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch registration.")
+		return
+	}
+	if reg.Status != models.RegistrationActive {
+		respondError(c, models.ErrConflict, "Registration is no longer valid ("+reg.Status+").")
+		return
+	}
+	if reg.RSVP != models.RSVPGoing {
+		respondError(c, models.ErrConflict, "Tickets are only issued to attendees who are going (RSVP: "+reg.RSVP+").")
+		return
+	}
+
+	token, err := utils.GenerateTicketToken(utils.TicketClaims{
+		RegistrationID: reg.ID, UserID: reg.UserID, EventID: reg.EventID, Occurrence: reg.Occurrence,
+	})
+	if err != nil {
+		respondError(c, err, "Could not issue ticket.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"registration": reg, "token": token})
+		return
+	}
+	qr, err := utils.EncodeQR([]byte(token))
+	if err != nil {
+		respondError(c, err, "Could not render ticket.")
+		return
+	}
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", qr.SVG())
+		return
+	}
+	img, err := qr.PNG(ticketQRScale)
+	if err != nil {
+		respondError(c, err, "Could not render ticket.")
+		return
+	}
+	c.Data(http.StatusOK, "image/png", img)
+}
+
+// POST /events/:id/checkin?occurrence=  {"token": "..."}
+// 帶 occurrence 時，別的單次的票會被拒絕（重複事件在門口只收當天的票）
+func (d *deps) checkIn(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermCheckIn, "Not authorized to check in attendees.")
+	if !ok {
+		return
+	}
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+
+	invalid := models.NewValidationError()
+	claims, err := utils.VerifyTicketToken(req.Token)
+	switch {
+	case err != nil:
+		invalid.Add("token", "is not a valid ticket")
+	case claims.EventID != ev.ID:
+		invalid.Add("token", "is for a different event")
+	}
+	if err := invalid.OrNil(); err != nil {
+		respondError(c, err, "Invalid ticket.")
+		return
+	}
+	if occ, ok := c.GetQuery("occurrence"); ok && occ != claims.Occurrence {
+		respondError(c, models.ErrConflict, "Ticket is for a different occurrence ("+claims.Occurrence+").")
+		return
+	}
+
+	reg, err := d.regs.Get(claims.RegistrationID)
+	if errors.Is(err, models.ErrNotFound) || (err == nil && (reg.UserID != claims.UserID || reg.EventID != claims.EventID)) {
+		respondError(c, models.ErrConflict, "Ticket is no longer valid (registration cancelled).")
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch registration.")
+		return
+	}
+	if reg.Status != models.RegistrationActive {
+		respondError(c, models.ErrConflict, "Ticket is no longer valid ("+reg.Status+").")
+		return
+	}
+	if reg.RSVP != models.RSVPGoing {
+		respondError(c, models.ErrConflict, "Ticket is no longer valid (RSVP changed to "+reg.RSVP+").")
+		return
+	}
+	if reg.CheckedInAt != nil {
+		respondError(c, models.ErrConflict, "Ticket already used at "+reg.CheckedInAt.Format(time.RFC3339)+".")
+		return
+	}
+
+	now := time.Now().UTC()
+	uid := c.GetInt64("userId")
+	if err := d.regs.CheckIn(reg.ID, uid, now); err != nil {
+		respondError(c, err, "Ticket has already been used.") // 同時掃兩次：只有一個成功
+		return
+	}
+	reg.CheckedInAt, reg.CheckedInBy = &now, uid
+	c.JSON(http.StatusOK, gin.H{"message": "Checked in.", "registration": reg})
+}
+
+type checkInCounts struct {
+	Occurrence string `json:"occurrence,omitempty"`
+	Registered int    `json:"registered"` // going 的 active 報名數
+	CheckedIn  int    `json:"checkedIn"`
+	Remaining  int    `json:"remaining"` // 還沒到
+}
+
+func (s *checkInCounts) add(r models.Registration) {
+	s.Registered++
+	if r.CheckedInAt != nil {
+		s.CheckedIn++
+	} else {
+		s.Remaining++
+	}
+}
+
+// GET /events/:id/checkin/stats?occurrence=
+// 報到進度：總數、已到、未到、最後一次報到時間；重複事件另外依單次分開統計
+func (d *deps) checkInStats(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermViewAttendees, "Not authorized to view check-in stats.")
+	if !ok {
+		return
+	}
+	regs, err := d.regs.ListByEvent(ev.ID)
+	if err != nil {
+		respondError(c, err, "Could not fetch registrations.")
+		return
+	}
+	occ, filtered := c.GetQuery("occurrence")
+
+	var total checkInCounts
+	var last *time.Time
+	byOcc := map[string]*checkInCounts{}
+	for _, r := range regs {
+		if r.Seats() == 0 || (filtered && r.Occurrence != occ) { // 只算 going 的 active 報名
+			continue
+		}
+		total.add(r)
+		if r.CheckedInAt != nil && (last == nil || r.CheckedInAt.After(*last)) {
+			last = r.CheckedInAt
+		}
+		if byOcc[r.Occurrence] == nil {
+			byOcc[r.Occurrence] = &checkInCounts{Occurrence: r.Occurrence}
+		}
+		byOcc[r.Occurrence].add(r)
+	}
+
+	resp := gin.H{"registered": total.Registered, "checkedIn": total.CheckedIn, "remaining": total.Remaining, "lastCheckInAt": last}
+	if ev.IsRecurring() && !filtered {
+		occurrences := make([]checkInCounts, 0, len(byOcc))
+		for _, s := range byOcc {
+			occurrences = append(occurrences, *s)
+		}
+		sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Occurrence < occurrences[j].Occurrence })
+		resp["occurrences"] = occurrences
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, resp)
+}
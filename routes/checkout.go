@@ -0,0 +1,355 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// 購票流程（事件有 ticketTypes 時）：
+//   POST /events/:id/register → 建立 pending 訂單（佔名額）→ PaymentProvider.CreatePayment
+//     付款同步成功 → 訂單 paid、建立報名（201）
+//     需要使用者付款 → 202 + checkoutUrl，結果由 /payments/webhook 通知
+//   DELETE /events/:id/register → pending 訂單取消；已付款的退款後取消報名
+// 訂單狀態一律用 Transition 條件更新，同步回應與 webhook 誰先到都只會生效一次
+
+const maxWebhookBody = 1 << 20
+
+// checkout 由 registerForEvent 呼叫（事件狀態、可見度、occurrence 已檢查過）
+func (d *deps) checkout(c *gin.Context, ev models.Event, occurrence string) {
+	if d.orders == nil || d.payments == nil {
+		respondError(c, models.ErrConflict, "Ticket sales are not available.")
+		return
+	}
+	var req struct {
+		TicketTypeID string         `json:"ticketTypeId"`
+		PromoCode    string         `json:"promoCode"`
+		Answers      map[string]any `json:"answers"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Could not parse request data.")
+			return
+		}
+	}
+	tt, ok := ev.TicketType(req.TicketTypeID)
+	if !ok {
+		v := models.NewValidationError()
+		v.Add("ticketTypeId", "must be one of the event's ticket types")
+		respondError(c, v, "Invalid ticket type.")
+		return
+	}
+	now := time.Now()
+	if !tt.OnSale(now) {
+		respondError(c, models.ErrConflict, "Ticket type is not on sale.")
+		return
+	}
+	answers, err := ev.ValidateAnswers(req.Answers) // 先存在訂單，付款成功後帶到報名
+	if err != nil {
+		respondError(c, err, "Invalid answers.")
+		return
+	}
+
+	order := models.Order{
+		UserID:       c.GetInt64("userId"),
+		EventID:      ev.ID,
+		Occurrence:   occurrence,
+		TicketTypeID: tt.ID,
+		Amount:       tt.Price,
+		Currency:     tt.Currency,
+		Answers:      answers,
+		Provider:     d.payments.Name(),
+		ExpiresAt:    now.Add(models.OrderTTL).UTC(),
+	}
+	var promo models.PromoCode
+	if req.PromoCode != "" {
+		if promo, err = d.promoFor(req.PromoCode, ev.ID, tt, now); err != nil {
+			respondError(c, err, "Invalid promo code.")
+			return
+		}
+		order.Discount = promo.Discount(tt.Price)
+		order.Amount -= order.Discount
+		order.PromoCode = promo.Code
+	}
+	if order.Amount == 0 { // 免費（或全額折抵）不經過金流
+		order.Status, order.Provider = models.OrderPaid, ""
+	}
+	if err := d.orders.Create(&order, tt.Quantity); err != nil {
+		respondError(c, err, "Could not create order.") // 售完 / 已有訂單 → 409
+		return
+	}
+	if promo.ID != 0 {
+		// 次數上限在 Redeem 的交易裡檢查：同時結帳也不會超用
+		if err := d.promos.Redeem(promo.ID, order.UserID, order.ID); err != nil {
+			_ = d.orders.Transition(order.ID, models.OrderCancelled, models.OrderPending, models.OrderPaid)
+			respondError(c, err, "Promo code is no longer available.")
+			return
+		}
+	}
+	if order.Status == models.OrderPaid {
+		d.fulfilOrder(c, order)
+		return
+	}
+
+	pay, err := d.payments.CreatePayment(order)
+	if err != nil {
+		_ = d.orders.Transition(order.ID, models.OrderFailed, models.OrderPending) // 釋出名額
+		d.releasePromo(order)
+		respondError(c, err, "Could not start payment.")
+		return
+	}
+	if err := d.orders.SetPayment(order.ID, pay.ID); err != nil {
+		respondError(c, err, "Could not save payment.")
+		return
+	}
+	order.PaymentID = pay.ID
+
+	switch pay.Status {
+	case models.PaymentSucceeded:
+		if err := d.orders.Transition(order.ID, models.OrderPaid, models.OrderPending); err != nil && !errors.Is(err, models.ErrConflict) {
+			respondError(c, err, "Could not confirm order.")
+			return
+		}
+		order.Status = models.OrderPaid // ErrConflict：webhook 已先確認
+		d.fulfilOrder(c, order)
+	case models.PaymentFailed:
+		_ = d.orders.Transition(order.ID, models.OrderFailed, models.OrderPending)
+		d.releasePromo(order)
+		respondError(c, models.ErrPaymentFailed, "Payment was declined.")
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"message": "Payment pending.", "order": order, "checkoutUrl": pay.CheckoutURL})
+	}
+}
+
+// fulfilOrder 已付款的訂單 → 建立報名
+func (d *deps) fulfilOrder(c *gin.Context, o models.Order) {
+	if err := d.register(c, o); err != nil {
+		respondError(c, err, "Could not register for event.")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Registered!", "order": o})
+}
+
+// register 建立報名；已存在視為成功（webhook 與同步回應都會走到）
+func (d *deps) register(ctx context.Context, o models.Order) error {
+	reg := models.Registration{UserID: o.UserID, EventID: o.EventID, Occurrence: o.Occurrence, Answers: o.Answers}
+	if err := d.regs.Register(&reg, 0); err != nil && !errors.Is(err, models.ErrConflict) { // 名額由票種數量管
+		return err
+	}
+	if d.inv != nil {
+		d.inv.PurgeEventsList(ctx)
+		d.inv.PurgeEventItem(ctx, o.EventID)
+	}
+	return nil
+}
+
+// cancelOrder 由 cancelRegistration 呼叫：pending → cancelled；paid → 先標記 refunded 再向金流退款
+func (d *deps) cancelOrder(c *gin.Context, o models.Order) {
+	switch o.Status {
+	case models.OrderPending:
+		if err := d.orders.Transition(o.ID, models.OrderCancelled, models.OrderPending); err != nil {
+			respondError(c, err, "Could not cancel order.") // 剛好付款成功 → 409，重試會走退款
+			return
+		}
+		o.Status = models.OrderCancelled
+		d.releasePromo(o)
+		c.JSON(http.StatusOK, gin.H{"message": "Cancelled!", "order": o})
+		return
+	case models.OrderPaid:
+	default:
+		respondError(c, models.ErrConflict, "Order cannot be cancelled ("+o.Status+").")
+		return
+	}
+
+	to, err := d.refundOrder(o)
+	if err != nil {
+		respondError(c, err, "Could not refund the payment.")
+		return
+	}
+	if err := d.regs.Cancel(o.UserID, o.EventID, o.Occurrence); err != nil && !errors.Is(err, models.ErrNotFound) {
+		respondError(c, err, "Could not cancel registration.")
+		return
+	}
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, o.EventID)
+	}
+	o.Status = to
+	c.JSON(http.StatusOK, gin.H{"message": "Cancelled!", "order": o})
+}
+
+// refundOrder paid → refunded（免費的 → cancelled）並向金流退款、還回折扣碼；回傳新的狀態。
+// 先搶到狀態再退款：同時兩個取消請求只有一個會真的退款；退款失敗改回 paid，之後可以重試
+func (d *deps) refundOrder(o models.Order) (string, error) {
+	to := models.OrderRefunded
+	if o.Amount == 0 {
+		to = models.OrderCancelled
+	}
+	if err := d.orders.Transition(o.ID, to, models.OrderPaid); err != nil {
+		return "", err
+	}
+	if to == models.OrderRefunded {
+		if err := d.payments.Refund(o.PaymentID, o.Amount); err != nil {
+			_ = d.orders.Transition(o.ID, models.OrderPaid, models.OrderRefunded)
+			return "", err
+		}
+	}
+	d.releasePromo(o)
+	return to, nil
+}
+
+// POST /payments/webhook
+// 不需要登入（金流伺服器呼叫），由 PaymentProvider 驗簽；同一個事件 id 只處理一次
+func (d *deps) paymentWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		respondBadRequest(c, "Could not read request body.")
+		return
+	}
+	ev, err := d.payments.ParseWebhook(body, c.Request.Header)
+	if err != nil {
+		respondError(c, err, "Invalid webhook.")
+		return
+	}
+	first, err := d.orders.RecordWebhook(d.payments.Name(), ev.ID)
+	if err != nil {
+		respondError(c, err, "Could not record webhook.")
+		return
+	}
+	if !first {
+		c.JSON(http.StatusOK, gin.H{"message": "Already processed."})
+		return
+	}
+	if err := d.applyPaymentEvent(c, ev); err != nil {
+		_ = d.orders.ForgetWebhook(d.payments.Name(), ev.ID) // 回非 2xx，金流會重送
+		respondError(c, err, "Could not process webhook.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Processed."})
+}
+
+func (d *deps) applyPaymentEvent(ctx context.Context, ev models.PaymentEvent) error {
+	o, err := d.orders.GetByPayment(d.payments.Name(), ev.PaymentID)
+	if err != nil {
+		return err
+	}
+	sd := d.inOrg(models.OrgOf(o.OrgID)) // webhook 不屬於任何 tenant，以訂單的組織為準
+
+	switch ev.Type {
+	case models.WebhookPaymentSucceeded:
+		// 只接受還佔著名額的 pending 訂單。過期的名額與折扣碼次數可能已經給了別人，
+		// 接受會超賣 → 跟已取消 / 失敗的訂單一樣把錢退回去
+		if o.Status == models.OrderPending && !time.Now().Before(o.ExpiresAt) {
+			if err := sd.orders.Transition(o.ID, models.OrderExpired, models.OrderPending); err != nil && !errors.Is(err, models.ErrConflict) {
+				return err
+			}
+		}
+		err := sd.orders.Transition(o.ID, models.OrderPaid, models.OrderPending)
+		if errors.Is(err, models.ErrConflict) {
+			if o, err = sd.orders.Get(o.ID); err != nil {
+				return err
+			}
+			switch o.Status {
+			case models.OrderPaid:
+				return sd.register(ctx, o) // 同步回應已處理過
+			case models.OrderExpired:
+				sd.releasePromo(o) // Redeem 可能還沒清掉
+				return sd.payments.Refund(o.PaymentID, o.Amount)
+			case models.OrderCancelled, models.OrderFailed:
+				return sd.payments.Refund(o.PaymentID, o.Amount)
+			}
+			return nil // refunded：已經處理完
+		}
+		if err != nil {
+			return err
+		}
+		return sd.register(ctx, o)
+	case models.WebhookPaymentFailed:
+		err := sd.orders.Transition(o.ID, models.OrderFailed, models.OrderPending)
+		if errors.Is(err, models.ErrConflict) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sd.releasePromo(o)
+	case models.WebhookRefundSucceeded:
+		// 在金流後台直接退款：訂單改 refunded 並取消報名（由 API 取消的已經是 refunded）
+		err := sd.orders.Transition(o.ID, models.OrderRefunded, models.OrderPaid)
+		if errors.Is(err, models.ErrConflict) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sd.releasePromo(o)
+		if err := sd.regs.Cancel(o.UserID, o.EventID, o.Occurrence); err != nil && !errors.Is(err, models.ErrNotFound) {
+			return err
+		}
+	}
+	return nil // 不認得的事件種類直接確認，避免金流一直重送
+}
+
+// GET /orders/:id（只有下單的人看得到）
+func (d *deps) getOrder(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid order id.")
+		return
+	}
+	o, err := d.orders.Get(id)
+	if err == nil && o.UserID != c.GetInt64("userId") {
+		err = models.ErrNotFound // 別人的訂單當作不存在
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch order.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, o)
+}
+
+// GET /events/:id/tickets?occurrence=
+// 票種與剩餘數量（remaining 為 null 表示不限量）
+func (d *deps) listTicketTypes(c *gin.Context) {
+	ev, err := d.events.GetByID(c.Param("id"))
+	if err == nil && !d.canView(c, ev) {
+		err = models.ErrNotFound
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	sold := map[string]int{}
+	if d.orders != nil {
+		if sold, err = d.orders.Sold(ev.ID, c.Query("occurrence")); err != nil {
+			respondError(c, err, "Could not fetch ticket availability.")
+			return
+		}
+	}
+	type ticketView struct {
+		models.TicketType
+		Remaining *int `json:"remaining"`
+		OnSale    bool `json:"onSale"`
+	}
+	now := time.Now()
+	out := make([]ticketView, 0, len(ev.TicketTypes))
+	for _, t := range ev.TicketTypes {
+		tv := ticketView{TicketType: t, OnSale: t.OnSale(now)}
+		if t.Quantity > 0 {
+			left := max(t.Quantity-sold[t.ID], 0)
+			tv.Remaining = &left
+			tv.OnSale = tv.OnSale && left > 0
+		}
+		out = append(out, tv)
+	}
+	noStoreIfPrivate(c, ev)
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// problemFromError 集中把 models 的領域錯誤對應到 HTTP 狀態碼 + 錯誤碼
+// 認不得的錯誤（DB 斷線、timeout…）一律視為 500
+func problemFromError(err error) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrValidation):
+		return http.StatusBadRequest, utils.CodeValidation
+	case errors.Is(err, models.ErrInvalidCredentials):
+		return http.StatusUnauthorized, utils.CodeInvalidCredentials
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden, utils.CodeForbidden
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound, utils.CodeNotFound
+	case errors.Is(err, models.ErrConflict):
+		return http.StatusConflict, utils.CodeConflict
+	case errors.Is(err, models.ErrPaymentFailed):
+		return http.StatusPaymentRequired, utils.CodePaymentFailed
+	default:
+		return http.StatusInternalServerError, utils.CodeInternal
+	}
+}
+
+// respondError 依錯誤種類回 problem+json；detail 是給使用者看的訊息
+// 5xx 不把內部錯誤字串帶出去，只回 detail
+func respondError(c *gin.Context, err error, detail string) {
+	status, code := problemFromError(err)
+	p := utils.NewProblem(status, code, detail)
+	var ve *models.ValidationError
+	if errors.As(err, &ve) {
+		p.Errors = ve.Fields
+	}
+	var vc *models.VersionConflictError
+	if errors.As(err, &vc) {
+		p.Current = vc.Current // client 可以拿最新版本重新合併
+		setETag(c, vc.Current.Version)
+	}
+	utils.WriteProblem(c, p)
+}
+
+// respondBadRequest 用於 JSON 解析失敗等請求格式錯誤
+func respondBadRequest(c *gin.Context, detail string) {
+	utils.AbortWithProblem(c, http.StatusBadRequest, utils.CodeBadRequest, detail)
+}
@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// recordRevision 在事件寫入成功後補一筆歷程
+// 寫入已經生效，歷程失敗只記 log，不讓請求失敗
+func (d *deps) recordRevision(c *gin.Context, action string, before, after models.Event, fromRev int64) {
+	d.appendRevision(c.GetInt64("userId"), action, before, after, fromRev)
+}
+
+// appendRevision 不需要請求的版本（背景匯入用）
+func (d *deps) appendRevision(userID int64, action string, before, after models.Event, fromRev int64) {
+	if d.revisions == nil {
+		return
+	}
+	changes := models.DiffEvents(before, after)
+	delete(changes, "version") // 版本號本身就是 rev，不算欄位變更
+
+	snap := after
+	rev := models.Revision{
+		EventID:  after.ID,
+		Rev:      after.Version,
+		Action:   action,
+		UserID:   userID,
+		At:       time.Now().UTC(),
+		Changes:  changes,
+		Snapshot: &snap,
+		FromRev:  fromRev,
+	}
+	if err := d.revisions.Append(&rev); err != nil {
+		log.Printf("record revision %s@%d: %v", after.ID, after.Version, err)
+	}
+}
+
+// findEventForHistory 歷程也要能查已軟刪除的事件，並限事件成員或 admin
+func (d *deps) findEventForHistory(c *gin.Context) (models.Event, bool) {
+	id := c.Param("id")
+	userId := c.GetInt64("userId")
+
+	ev, err := d.events.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		ev, err = d.events.GetDeleted(id)
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch the event.")
+		return models.Event{}, false
+	}
+	if !ev.Can(userId, models.PermView) {
+		admin, err := d.isAdmin(userId)
+		if err != nil {
+			respondError(c, err, "Could not fetch history.")
+			return models.Event{}, false
+		}
+		if !admin {
+			respondError(c, models.ErrForbidden, "Not authorized to view history.")
+			return models.Event{}, false
+		}
+	}
+	return ev, true
+}
+
+func parseRev(c *gin.Context) (int64, bool) {
+	rev, err := strconv.ParseInt(c.Param("rev"), 10, 64)
+	if err != nil || rev <= 0 {
+		v := models.NewValidationError()
+		v.Add("rev", "must be a positive integer")
+		respondError(c, v, "Invalid revision.")
+		return 0, false
+	}
+	return rev, true
+}
+
+// GET /events/:id/history
+func (d *deps) getEventHistory(c *gin.Context) {
+	ev, ok := d.findEventForHistory(c)
+	if !ok {
+		return
+	}
+	revs, err := d.revisions.List(ev.ID)
+	if err != nil {
+		respondError(c, err, "Could not fetch history.")
+		return
+	}
+	c.JSON(http.StatusOK, revs)
+}
+
+// GET /events/:id/revisions/:rev
+func (d *deps) getEventRevision(c *gin.Context) {
+	ev, ok := d.findEventForHistory(c)
+	if !ok {
+		return
+	}
+	rev, ok := parseRev(c)
+	if !ok {
+		return
+	}
+	r, err := d.revisions.Get(ev.ID, rev)
+	if err != nil {
+		respondError(c, err, "Could not fetch revision.")
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// POST /events/:id/revisions/:rev/rollback
+// 把可編輯欄位還原成該版本的 snapshot（狀態不變），產生一個新版本（不改寫歷史）
+func (d *deps) rollbackEvent(c *gin.Context) {
+	id := c.Param("id")
+	userId := c.GetInt64("userId")
+
+	cur, err := d.events.GetByID(id)
+	if err != nil {
+		respondError(c, err, "Could not fetch the event.")
+		return
+	}
+	if !cur.Can(userId, models.PermEdit) {
+		respondError(c, models.ErrForbidden, "Not authorized to roll back the event.")
+		return
+	}
+	rev, ok := parseRev(c)
+	if !ok {
+		return
+	}
+	r, err := d.revisions.Get(id, rev)
+	if err != nil {
+		respondError(c, err, "Could not fetch revision.")
+		return
+	}
+	if r.Snapshot == nil {
+		respondError(c, models.ErrNotFound, "Revision has no snapshot.")
+		return
+	}
+
+	target := *r.Snapshot
+	target.ID, target.UserID, target.Members, target.DeletedAt = cur.ID, cur.UserID, cur.Members, nil
+	// 狀態只能走轉換端點：還原成 published 會讓取消（報名已作廢）的事件復活、草稿跳過發布檢查
+	target.Status, target.PublishAt = cur.Status, cur.PublishAt
+	if target.Version, err = expectedVersion(c, 0, cur.Version); err != nil {
+		respondError(c, err, "Invalid precondition.")
+		return
+	}
+	if err := target.ValidateUpdate(cur, time.Now()); err != nil {
+		respondError(c, err, "Revision can no longer be applied.")
+		return
+	}
+	if err := d.events.Update(&target); err != nil {
+		respondError(c, err, "Could not roll back the event.")
+		return
+	}
+	d.recordRevision(c, models.RevisionRollback, cur, target, rev)
+
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, id)
+	}
+
+	setETag(c, target.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Event rolled back.", "event": target})
+}
@@ -0,0 +1,236 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// 匯入事件（格式見 models/import.go）：
+//   POST /events/import?format=csv|jsonl&dryRun=true   格式也可以用 Content-Type（text/csv、application/x-ndjson）
+//   GET  /events/import/:jobId                          背景匯入的進度（只有發起的人看得到）
+// 每一列都用 createEvent 的規則驗證；有任何一列不合法就整份不匯入（dryRun 回傳逐列報告）。
+// 來源的 id 一律忽略、重新產生；匯入的事件屬於發起的人。
+// 不超過一批的匯入在請求內完成（201），更大的在背景分批寫入（202 + Location）
+const (
+	importBatchSize = 100
+	importJobTTL    = 24 * time.Hour // 結束的工作保留多久可以查
+	maxImportBytes  = 10 << 20
+)
+
+const (
+	importRunning = "running"
+	importDone    = "done"
+	importFailed  = "failed" // 寫到一半失敗；已寫入的不會還原
+)
+
+type importJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Imported   int        `json:"imported"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	org        int64
+	userID     int64
+}
+
+// importJobs 匯入進度放在記憶體（跟限速器一樣）：重啟後查不到，但已寫入的事件不受影響
+type importJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*importJob
+}
+
+func newImportJobs() *importJobs {
+	return &importJobs{jobs: map[string]*importJob{}}
+}
+
+func (s *importJobs) add(j *importJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, old := range s.jobs { // 順便清掉過期的
+		if old.FinishedAt != nil && time.Since(*old.FinishedAt) > importJobTTL {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[j.ID] = j
+}
+
+func (s *importJobs) get(id string, org, userID int64) (importJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok || j.org != org || j.userID != userID {
+		return importJob{}, false
+	}
+	return *j, true
+}
+
+func (s *importJobs) update(id string, fn func(*importJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[id]; ok {
+		fn(j)
+	}
+}
+
+type importRowError struct {
+	Line   int               `json:"line"`
+	Errors map[string]string `json:"errors"`
+}
+
+type importReport struct {
+	DryRun  bool             `json:"dryRun"`
+	Total   int              `json:"total"`
+	Valid   int              `json:"valid"`
+	Invalid int              `json:"invalid"`
+	Errors  []importRowError `json:"errors"`
+}
+
+func importFormat(c *gin.Context) string {
+	if f := c.Query("format"); f != "" {
+		return f
+	}
+	switch c.ContentType() {
+	case "text/csv":
+		return models.ImportCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return models.ImportJSONLines
+	}
+	return ""
+}
+
+// POST /events/import?format=csv|jsonl&dryRun=true
+func (d *deps) importEvents(c *gin.Context) {
+	dryRun := false
+	if s := c.Query("dryRun"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			respondBadRequest(c, "dryRun must be true or false.")
+			return
+		}
+		dryRun = b
+	}
+	rows, err := models.ParseImport(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes), importFormat(c))
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		utils.AbortWithProblem(c, http.StatusRequestEntityTooLarge, utils.CodePayloadTooLarge, fmt.Sprintf("Import files are limited to %d MB.", maxImportBytes>>20))
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Could not read the import file.")
+		return
+	}
+	if len(rows) == 0 {
+		respondBadRequest(c, "The import file has no events.")
+		return
+	}
+
+	uid, now := c.GetInt64("userId"), time.Now()
+	report := importReport{DryRun: dryRun, Total: len(rows), Errors: []importRowError{}}
+	events := make([]models.Event, 0, len(rows))
+	for _, row := range rows {
+		err := row.Err
+		if err == nil {
+			row.Event.ID = "" // 一律產生新的 id
+			err = prepareNewEvent(&row.Event, uid, now)
+		}
+		var ve *models.ValidationError
+		if errors.As(err, &ve) {
+			report.Errors = append(report.Errors, importRowError{Line: row.Line, Errors: ve.Fields})
+			continue
+		}
+		events = append(events, row.Event)
+	}
+	report.Valid, report.Invalid = len(events), len(report.Errors)
+
+	if dryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	if report.Invalid > 0 {
+		v := models.NewValidationError()
+		for _, r := range report.Errors {
+			fields := make([]string, 0, len(r.Errors))
+			for f := range r.Errors {
+				fields = append(fields, f)
+			}
+			sort.Strings(fields)
+			for _, f := range fields {
+				v.Add(fmt.Sprintf("lines[%d].%s", r.Line, f), r.Errors[f])
+			}
+		}
+		respondError(c, v, fmt.Sprintf("%d of %d rows are invalid; nothing was imported. Use ?dryRun=true for a per-row report.", report.Invalid, report.Total))
+		return
+	}
+
+	job := &importJob{ID: uuid.NewString(), Status: importRunning, Total: len(events), CreatedAt: now.UTC(), org: d.org, userID: uid}
+	d.imports.add(job)
+	if len(events) <= importBatchSize {
+		err := d.runImport(job.ID, uid, events)
+		j, _ := d.imports.get(job.ID, d.org, uid)
+		if err != nil {
+			respondError(c, err, j.Error) // 已寫入的不會還原，detail 說明寫到哪裡
+			return
+		}
+		c.JSON(http.StatusCreated, j)
+		return
+	}
+	j := *job // goroutine 開始前先複製，之後只能透過 importJobs 讀
+	go func() { _ = d.runImport(job.ID, uid, events) }()
+	c.Header("Location", "/events/import/"+job.ID)
+	c.JSON(http.StatusAccepted, j)
+}
+
+// runImport 分批寫入；d 是請求時 scoped 的副本，背景 goroutine 也只寫得進同一個組織。
+// 失敗時工作標成 failed 並回傳錯誤（背景執行時只看工作狀態）
+func (d *deps) runImport(jobID string, userID int64, events []models.Event) error {
+	finish := func(status, msg string) {
+		d.imports.update(jobID, func(j *importJob) {
+			t := time.Now().UTC()
+			j.Status, j.Error, j.FinishedAt = status, msg, &t
+		})
+	}
+	for start := 0; start < len(events); start += importBatchSize {
+		batch := events[start:min(start+importBatchSize, len(events))]
+		n, err := d.events.CreateMany(batch)
+		for _, e := range batch[:n] {
+			d.appendRevision(userID, models.RevisionCreate, models.Event{}, e, 0)
+		}
+		d.imports.update(jobID, func(j *importJob) { j.Imported += n })
+		if n > 0 && d.inv != nil {
+			d.inv.PurgeEventsList(context.Background()) // 新的 id 不會有單筆快取
+		}
+		if err != nil {
+			log.Printf("import %s: %v", jobID, err)
+			finish(importFailed, fmt.Sprintf("Stopped after %d of %d events: could not save the next batch.", start+n, len(events)))
+			return err
+		}
+	}
+	finish(importDone, "")
+	return nil
+}
+
+// GET /events/import/:jobId
+func (d *deps) getImportJob(c *gin.Context) {
+	j, ok := d.imports.get(c.Param("jobId"), d.org, c.GetInt64("userId"))
+	if !ok {
+		respondError(c, models.ErrNotFound, "Import job not found.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, j)
+}
@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// transitionTo 產生狀態轉換的 handler：
+// POST /events/:id/publish、/schedule、/draft、/cancel、/complete
+func transitionTo(target string) func(*deps, *gin.Context) {
+	return func(d *deps, c *gin.Context) {
+		id := c.Param("id")
+		userId := c.GetInt64("userId")
+
+		old, err := d.events.GetByID(id)
+		if err != nil {
+			respondError(c, err, "Could not fetch the event.")
+			return
+		}
+		if !old.Can(userId, models.PermEdit) {
+			respondError(c, models.ErrForbidden, "Not authorized to change event status.")
+			return
+		}
+
+		var req struct {
+			PublishAt *time.Time `json:"publishAt"` // 只有 schedule 需要
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondBadRequest(c, "Could not parse request data.")
+				return
+			}
+		}
+
+		// 已經取消的事件再 POST /cancel：上次退款或作廢沒做完，只重跑收尾
+		retry := target == models.StatusCancelled && old.EffectiveStatus() == models.StatusCancelled
+		updated := old
+		if !retry {
+			if err := updated.Transition(target, req.PublishAt, time.Now()); err != nil {
+				respondError(c, err, "Invalid status transition.")
+				return
+			}
+			if updated.Version, err = expectedVersion(c, 0, old.Version); err != nil {
+				respondError(c, err, "Invalid precondition.")
+				return
+			}
+			if err := d.events.Patch(&updated, []string{"status", "publishAt"}); err != nil {
+				respondError(c, err, "Could not change event status.")
+				return
+			}
+			d.recordRevision(c, models.RevisionUpdate, old, updated, 0)
+		}
+
+		resp := gin.H{"message": "Event is now " + target + ".", "event": updated}
+		var settleErr error
+		if target == models.StatusCancelled {
+			// 取消 → 作廢報名、已付款的訂單退款
+			var voided int64
+			var refunded int
+			voided, refunded, settleErr = d.settleCancelled(id, "")
+			resp["voidedRegistrations"], resp["refundedOrders"] = voided, refunded
+		}
+
+		if d.inv != nil {
+			d.inv.PurgeEventsList(c)
+			d.inv.PurgeEventItem(c, id)
+		}
+		if settleErr != nil {
+			respondError(c, settleErr, "The event is cancelled, but some registrations could not be voided or refunded. Retry POST /events/"+id+"/cancel.")
+			return
+		}
+
+		setETag(c, updated.Version)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// settleCancelled 取消事件（occurrence 空白）或重複事件某一次的收尾：作廢 Postgres 裡的報名、
+// 已付款的訂單退款（折扣碼一併還回）。已作廢 / 已退款的不會再處理，所以失敗後可以整個重跑；有任何一筆失敗就回傳錯誤
+func (d *deps) settleCancelled(id, occurrence string) (voided int64, refunded int, err error) {
+	if occurrence == "" {
+		voided, err = d.regs.VoidByEvent(id)
+	} else {
+		voided, err = d.regs.VoidByOccurrence(id, occurrence)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	if d.orders == nil || d.payments == nil {
+		return voided, 0, nil
+	}
+	orders, err := d.orders.ListByEvent(id, occurrence, models.OrderPaid)
+	if err != nil {
+		return voided, 0, err
+	}
+	for _, o := range orders {
+		if _, rerr := d.refundOrder(o); rerr != nil {
+			log.Printf("refund order %d of cancelled event %s: %v", o.ID, id, rerr)
+			err = rerr
+			continue
+		}
+		refunded++
+	}
+	return voided, refunded, err
+}
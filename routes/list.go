@@ -0,0 +1,180 @@
+package routes
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// eventListItem GET /events 的一筆：
+// 重複事件展開後的單次會帶 seriesId / occurrenceId（DateTime / EndTime 為該次的時間），
+// 附近搜尋會帶 distanceKm
+type eventListItem struct {
+	models.Event
+	SeriesID     string   `json:"seriesId,omitempty"`
+	OccurrenceID string   `json:"occurrenceId,omitempty"`
+	DistanceKm   *float64 `json:"distanceKm,omitempty"`
+}
+
+// MarshalJSON Event 有自己的 MarshalJSON（附 local），內嵌會被提升而吃掉額外欄位，這裡補回去
+func (o eventListItem) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(o.Event)
+	if err != nil || (o.SeriesID == "" && o.DistanceKm == nil) {
+		return b, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if o.SeriesID != "" {
+		m["seriesId"], _ = json.Marshal(o.SeriesID)
+		m["occurrenceId"], _ = json.Marshal(o.OccurrenceID)
+	}
+	if o.DistanceKm != nil {
+		m["distanceKm"], _ = json.Marshal(*o.DistanceKm)
+	}
+	return json.Marshal(m)
+}
+
+// eventFilterFromQuery 解析 GET /events 的篩選參數：
+// ?from=&to=&tz=（日期範圍）、?near=lat,lng&radius=km（附近）、?bbox=minLng,minLat,maxLng,maxLat（地圖視窗）、
+// ?category=、?tag=（可重複，全部符合）
+// ranged=true 表示有日期範圍（重複事件要展開）
+func eventFilterFromQuery(c *gin.Context) (f models.EventFilter, ranged bool, err error) {
+	v := models.NewValidationError()
+
+	from, to, ranged, err := dateRange(c)
+	v.Merge(err)
+	if ranged && err == nil {
+		f.From, f.To = &from, &to
+	}
+
+	if q := c.Query("near"); q != "" {
+		n := models.GeoNear{RadiusKm: models.DefaultNearRadiusKm}
+		ll, ok := parseFloats(q, 2)
+		if ok {
+			n.Lat, n.Lng = ll[0], ll[1]
+		}
+		if r := c.Query("radius"); r != "" {
+			if n.RadiusKm, err = strconv.ParseFloat(r, 64); err != nil {
+				n.RadiusKm = -1
+			}
+		}
+		if !ok {
+			v.Add("near", "must be lat,lng")
+		} else if err := n.Validate(); err != nil {
+			v.Merge(err)
+		} else {
+			f.Near = &n
+		}
+	}
+
+	if q := c.Query("bbox"); q != "" {
+		box, ok := parseFloats(q, 4)
+		if !ok {
+			v.Add("bbox", "must be minLng,minLat,maxLng,maxLat")
+		} else {
+			b := models.GeoBox{MinLng: box[0], MinLat: box[1], MaxLng: box[2], MaxLat: box[3]}
+			if err := b.Validate(); err != nil {
+				v.Merge(err)
+			} else {
+				f.Box = &b
+			}
+		}
+	}
+	f.Category, f.Tags = taxonomyFromQuery(c)
+	return f, ranged, v.OrNil()
+}
+
+// taxonomyFromQuery ?category= 與 ?tag=a&tag=b（也接受 tag=a,b）
+func taxonomyFromQuery(c *gin.Context) (category string, tags []string) {
+	for _, q := range c.QueryArray("tag") {
+		for _, t := range strings.Split(q, ",") {
+			if t = models.NormalizeTag(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return models.NormalizeTag(c.Query("category")), tags
+}
+
+// GET /events/facets?from=&to=&tz=&category=&tag=
+// 已發布事件的標籤 / 分類數量，給前端的篩選側欄
+func (d *deps) getFacets(c *gin.Context) {
+	var f models.EventFilter
+	from, to, ranged, err := dateRange(c)
+	if err != nil {
+		respondError(c, err, "Invalid date range.")
+		return
+	}
+	if ranged {
+		f.From, f.To = &from, &to
+	}
+	f.Category, f.Tags = taxonomyFromQuery(c)
+
+	facets, err := d.events.Facets(f)
+	if err != nil {
+		respondError(c, err, "Could not fetch facets.")
+		return
+	}
+	c.JSON(http.StatusOK, facets)
+}
+
+// parseFloats "1.5,2" → []float64；個數不符或不是數字 → ok=false
+func parseFloats(s string, n int) ([]float64, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, false
+	}
+	out := make([]float64, n)
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		out[i] = f
+	}
+	return out, true
+}
+
+// listItems 依篩選條件組出列表：有日期範圍 → 展開重複事件並依時間排序；
+// 有 near → 附上距離並依距離排序（同距離維持時間順序）
+func listItems(events []models.Event, f models.EventFilter, ranged bool) []eventListItem {
+	var items []eventListItem
+	if ranged {
+		items = expandEvents(events, *f.From, *f.To)
+	} else {
+		items = make([]eventListItem, 0, len(events))
+		for _, e := range events {
+			items = append(items, eventListItem{Event: e})
+		}
+	}
+	if f.Near == nil {
+		return items
+	}
+	for i := range items {
+		if d, ok := items[i].DistanceFrom(f.Near.Lat, f.Near.Lng); ok {
+			d = math.Round(d*1000) / 1000
+			items[i].DistanceKm = &d
+		}
+	}
+	sortByDistance(items)
+	return items
+}
+
+func sortByDistance(items []eventListItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].DistanceKm, items[j].DistanceKm
+		if (a == nil) != (b == nil) {
+			return a != nil // 沒有座標的排最後
+		}
+		return a != nil && *a < *b
+	})
+}
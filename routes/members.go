@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// 事件成員：owner（Event.UserID）+ co-organizer / checker（Event.Members）
+// 只改 userId / members 兩個欄位，走 Patch 做樂觀鎖
+
+// GET /events/:id/members
+func (d *deps) listMembers(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermView, "Not authorized to view members.")
+	if !ok {
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, ev.MemberList())
+}
+
+// POST /events/:id/members  {"userId": 2, "role": "co-organizer"}
+func (d *deps) addMember(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermManageMembers, "Only the event owner can manage members.")
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID int64  `json:"userId" binding:"required"`
+		Role   string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	if _, err := d.users.GetByID(req.UserID); err != nil {
+		respondError(c, err, "Could not find the user.")
+		return
+	}
+	updated := ev
+	if err := updated.AddMember(req.UserID, req.Role); err != nil {
+		respondError(c, err, "Could not add member.")
+		return
+	}
+	if !d.saveMembers(c, ev, updated, "Could not add member.") {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Member added.", "members": updated.MemberList()})
+}
+
+// DELETE /events/:id/members/:userId
+// owner 可移除任何成員；成員也可以自己退出
+func (d *deps) removeMember(c *gin.Context) {
+	target, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid user id.")
+		return
+	}
+	ev, err := d.events.GetByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Could not fetch the event.")
+		return
+	}
+	uid := c.GetInt64("userId")
+	if uid != target && !ev.Can(uid, models.PermManageMembers) {
+		respondError(c, models.ErrForbidden, "Only the event owner can manage members.")
+		return
+	}
+	updated := ev
+	if err := updated.RemoveMember(target); err != nil {
+		respondError(c, err, "Could not remove member.")
+		return
+	}
+	if !d.saveMembers(c, ev, updated, "Could not remove member.") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed.", "members": updated.MemberList()})
+}
+
+// POST /events/:id/transfer-ownership  {"userId": 2}
+// 新 owner 必須是既有使用者；原 owner 變成 co-organizer
+func (d *deps) transferOwnership(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermManageMembers, "Only the event owner can transfer ownership.")
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID int64 `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	if _, err := d.users.GetByID(req.UserID); err != nil {
+		respondError(c, err, "Could not find the user.")
+		return
+	}
+	updated := ev
+	if err := updated.TransferOwnership(req.UserID); err != nil {
+		respondError(c, err, "Could not transfer ownership.")
+		return
+	}
+	if !d.saveMembers(c, ev, updated, "Could not transfer ownership.") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred.", "event": updated})
+}
+
+// saveMembers 寫回 userId / members、記歷程、清快取；失敗時已回應錯誤
+func (d *deps) saveMembers(c *gin.Context, old, updated models.Event, detail string) bool {
+	var err error
+	if updated.Version, err = expectedVersion(c, 0, old.Version); err != nil {
+		respondError(c, err, "Invalid precondition.")
+		return false
+	}
+	if err := d.events.Patch(&updated, []string{"userId", "members"}); err != nil {
+		respondError(c, err, detail)
+		return false
+	}
+	d.recordRevision(c, models.RevisionUpdate, old, updated, 0)
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, updated.ID)
+	}
+	setETag(c, updated.Version)
+	return true
+}
+
+// GET /events/:id/registrations?occurrence=
+// 報名名單：owner / co-organizer / checker
+func (d *deps) listRegistrations(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermViewAttendees, "Not authorized to view registrations.")
+	if !ok {
+		return
+	}
+	regs, err := d.regs.ListByEvent(ev.ID)
+	if err != nil {
+		respondError(c, err, "Could not fetch registrations.")
+		return
+	}
+	if occ := c.Query("occurrence"); occ != "" {
+		out := regs[:0]
+		for _, r := range regs {
+			if r.Occurrence == occ {
+				out = append(out, r)
+			}
+		}
+		regs = out
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, regs)
+}
@@ -0,0 +1,273 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// 沒給 to 時預設往後展開多久；一次最多查多久
+const (
+	defaultOccurrenceWindow = 90 * 24 * time.Hour
+	maxOccurrenceWindow     = 366 * 24 * time.Hour
+)
+
+// dateRange 讀 ?from= & ?to=；兩個都沒給 → ok=false（不篩選、不展開）
+// 可帶 ?tz=（IANA，預設 UTC）：沒有 offset 的時間與純日期以該時區解讀，
+// 純日期的 to 代表當天結束（from=2030-01-07&to=2030-01-07 → 那一整天）
+func dateRange(c *gin.Context) (from, to time.Time, ok bool, err error) {
+	qFrom, qTo := c.Query("from"), c.Query("to")
+	if qFrom == "" && qTo == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	v := models.NewValidationError()
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil || tz == "Local" {
+			v.Add("tz", "must be an IANA time zone name")
+			return time.Time{}, time.Time{}, true, v
+		}
+	}
+
+	from = time.Now().UTC()
+	if qFrom != "" {
+		if from, err = parseRangeBound(qFrom, loc, false); err != nil {
+			v.Add("from", "must be an RFC 3339 timestamp or a date (2006-01-02)")
+		}
+	}
+	to = from.Add(defaultOccurrenceWindow)
+	if qTo != "" {
+		if to, err = parseRangeBound(qTo, loc, true); err != nil {
+			v.Add("to", "must be an RFC 3339 timestamp or a date (2006-01-02)")
+		}
+	}
+	if len(v.Fields) == 0 {
+		switch {
+		case to.Before(from):
+			v.Add("to", "must not be before from")
+		case to.Sub(from) > maxOccurrenceWindow:
+			v.Add("to", fmt.Sprintf("range must be at most %d days", int(maxOccurrenceWindow.Hours()/24)))
+		}
+	}
+	return from.UTC(), to.UTC(), true, v.OrNil()
+}
+
+func parseRangeBound(s string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", s, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond) // 以當地日曆算，夏令時間那天也正確
+	}
+	return t, nil
+}
+
+// expandEvents 把範圍內的重複事件展開成單次（略過已取消的），依開始時間排序
+func expandEvents(events []models.Event, from, to time.Time) []eventListItem {
+	out := make([]eventListItem, 0, len(events))
+	for _, e := range events {
+		if !e.IsRecurring() {
+			out = append(out, eventListItem{Event: e})
+			continue
+		}
+		occs, err := e.Occurrences(from, to, 0)
+		if err != nil {
+			continue // RRULE 壞掉的舊資料不影響整個列表
+		}
+		for _, o := range occs {
+			if o.Cancelled {
+				continue
+			}
+			item := eventListItem{Event: e, SeriesID: e.ID, OccurrenceID: o.ID}
+			item.DateTime, item.EndTime = o.Start, o.End
+			out = append(out, item)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].DateTime.Before(out[j].DateTime) })
+	return out
+}
+
+// checkOccurrence 報名前檢查：重複事件必須指定一個存在、未取消、尚未開始的單次
+func checkOccurrence(ev models.Event, occurrence string, now time.Time) error {
+	v := models.NewValidationError()
+	if !ev.IsRecurring() {
+		if occurrence != "" {
+			v.Add("occurrence", "event is not recurring")
+		}
+		return v.OrNil()
+	}
+	if occurrence == "" {
+		v.Add("occurrence", "is required for recurring events")
+		return v
+	}
+	o, err := ev.FindOccurrence(occurrence)
+	if err != nil {
+		return err
+	}
+	switch {
+	case o.Cancelled:
+		return fmt.Errorf("%w: occurrence %s is cancelled", models.ErrConflict, occurrence)
+	case !o.Start.After(now):
+		return fmt.Errorf("%w: occurrence %s has already started", models.ErrConflict, occurrence)
+	}
+	return nil
+}
+
+// GET /events/:id/occurrences?from=&to=
+// 列出系列在範圍內的每一次（含已取消，cancelled=true）
+func (d *deps) listOccurrences(c *gin.Context) {
+	ev, err := d.events.GetByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if !d.canView(c, ev) {
+		respondError(c, models.ErrNotFound, "Could not fetch event.")
+		return
+	}
+	noStoreIfPrivate(c, ev)
+	if !ev.IsRecurring() {
+		respondBadRequest(c, "Event is not recurring.")
+		return
+	}
+	from, to, ok, err := dateRange(c)
+	if err != nil {
+		respondError(c, err, "Invalid date range.")
+		return
+	}
+	if !ok {
+		from = time.Now().UTC()
+		to = from.Add(defaultOccurrenceWindow)
+	}
+	occs, err := ev.Occurrences(from, to, 0)
+	if err != nil {
+		respondError(c, err, "Could not expand occurrences.")
+		return
+	}
+	if occs == nil {
+		occs = []models.Occurrence{}
+	}
+	c.JSON(http.StatusOK, gin.H{"seriesId": ev.ID, "from": from, "to": to, "occurrences": occs})
+}
+
+// POST /events/:id/occurrences/:occurrence/cancel
+// 取消後作廢該次的報名、已付款的訂單退款；已經取消過再 POST：上次退款或作廢沒做完，只重跑收尾
+func (d *deps) cancelOccurrence(c *gin.Context) {
+	d.updateOccurrence(c, func(o models.Occurrence) (*models.OccurrenceException, error) {
+		if o.Cancelled {
+			return nil, nil
+		}
+		ex := models.OccurrenceException{Date: o.OriginalStart, Cancelled: true}
+		if o.Moved {
+			start := o.Start
+			ex.MovedTo = &start
+		}
+		return &ex, nil
+	}, func(o models.Occurrence, resp gin.H) error {
+		voided, refunded, err := d.settleCancelled(c.Param("id"), o.ID)
+		resp["voidedRegistrations"], resp["refundedOrders"] = voided, refunded
+		return err
+	})
+}
+
+// POST /events/:id/occurrences/:occurrence/move  {"dateTime": "..."}
+func (d *deps) moveOccurrence(c *gin.Context) {
+	var req struct {
+		DateTime time.Time `json:"dateTime" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	d.updateOccurrence(c, func(o models.Occurrence) (*models.OccurrenceException, error) {
+		if o.Cancelled {
+			return nil, fmt.Errorf("%w: occurrence is cancelled", models.ErrConflict)
+		}
+		if !req.DateTime.After(time.Now()) {
+			v := models.NewValidationError()
+			v.Add("dateTime", "must be in the future")
+			return nil, v
+		}
+		to := req.DateTime.UTC()
+		return &models.OccurrenceException{Date: o.OriginalStart, MovedTo: &to}, nil
+	}, nil)
+}
+
+// updateOccurrence 擁有者 / 共同主辦修改單次例外：讀系列 → 算出新的例外 → 只寫回 recurrence。
+// change 回傳 nil → 不用改（只重跑 settle）；settle 不是 nil 時在寫入後做收尾，結果放進 resp，失敗時回錯誤讓呼叫端重試
+func (d *deps) updateOccurrence(c *gin.Context, change func(models.Occurrence) (*models.OccurrenceException, error),
+	settle func(o models.Occurrence, resp gin.H) error) {
+	id := c.Param("id")
+	old, err := d.events.GetByID(id)
+	if err != nil {
+		respondError(c, err, "Could not fetch the event.")
+		return
+	}
+	if !old.Can(c.GetInt64("userId"), models.PermEdit) {
+		respondError(c, models.ErrForbidden, "Not authorized to update event.")
+		return
+	}
+	if !old.IsRecurring() {
+		respondBadRequest(c, "Event is not recurring.")
+		return
+	}
+	o, err := old.FindOccurrence(c.Param("occurrence"))
+	if err != nil {
+		respondError(c, err, "Could not find occurrence.")
+		return
+	}
+	ex, err := change(o)
+	if err != nil {
+		respondError(c, err, "Could not update occurrence.")
+		return
+	}
+
+	updated := old
+	if ex != nil {
+		rec := *old.Recurrence
+		rec.Exceptions = append([]models.OccurrenceException(nil), old.Recurrence.Exceptions...)
+		updated.Recurrence = &rec
+		updated.SetException(*ex)
+		if updated.Version, err = expectedVersion(c, 0, old.Version); err != nil {
+			respondError(c, err, "Invalid precondition.")
+			return
+		}
+		if err := d.events.Patch(&updated, []string{"recurrence"}); err != nil {
+			respondError(c, err, "Could not update occurrence.")
+			return
+		}
+		d.recordRevision(c, models.RevisionUpdate, old, updated, 0)
+	}
+
+	resp := gin.H{"message": "Occurrence updated."}
+	var settleErr error
+	if settle != nil {
+		settleErr = settle(o, resp)
+	}
+
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, id)
+	}
+	if settleErr != nil {
+		respondError(c, settleErr, "The occurrence is updated, but some registrations could not be voided or refunded. Retry "+c.Request.Method+" "+c.Request.URL.Path+".")
+		return
+	}
+
+	o, _ = updated.FindOccurrence(o.ID)
+	resp["occurrence"], resp["event"] = o, updated
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,49 @@
+package routes
+
+import "restapi/models"
+
+// Option 讓 main 在不改 RegisterRoutes 參數列的情況下調整設定
+type Option func(*deps)
+
+// WithPasswordPolicy 覆蓋 signup 的密碼強度規則（預設 models.DefaultPasswordPolicy）
+func WithPasswordPolicy(p models.PasswordPolicy) Option {
+	return func(d *deps) { d.passwordPolicy = p }
+}
+
+// WithRevisions 啟用事件修改歷程（/events/:id/history、rollback）
+func WithRevisions(r models.RevisionRepository) Option {
+	return func(d *deps) { d.revisions = r }
+}
+
+// WithSearchIndex 設定全文搜尋的實作（預設掃 EventRepository 的記憶體版）
+func WithSearchIndex(s models.SearchIndex) Option {
+	return func(d *deps) { d.search = s }
+}
+
+// WithOrganizations 啟用組織管理（/orgs）與組織層級的 admin；
+// 請求屬於哪個組織由 middlewares.Tenant 決定
+func WithOrganizations(r models.OrganizationRepository) Option {
+	return func(d *deps) { d.orgs = r }
+}
+
+// WithInvitations 啟用 private 事件的邀請（/events/:id/invitations）
+func WithInvitations(r models.InvitationRepository) Option {
+	return func(d *deps) { d.invitations = r }
+}
+
+// WithPayments 啟用購票：有 ticketTypes 的事件報名時建立訂單並透過 provider 收款，
+// 並註冊 /payments/webhook 與 /orders/:id
+func WithPayments(orders models.OrderRepository, provider models.PaymentProvider) Option {
+	return func(d *deps) { d.orders, d.payments = orders, provider }
+}
+
+// WithPromoCodes 啟用折扣碼（/promo-codes，checkout 的 promoCode）
+func WithPromoCodes(r models.PromoRepository) Option {
+	return func(d *deps) { d.promos = r }
+}
+
+// WithCalendarFeeds 啟用個人行事曆訂閱（/users/me/calendar-feed、/calendar/:token.ics）；
+// 單一事件的 /events/:id.ics 不需要
+func WithCalendarFeeds(r models.CalendarFeedRepository) Option {
+	return func(d *deps) { d.feeds = r }
+}
@@ -0,0 +1,246 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+/* -------------------- 多租戶 -------------------- */
+
+// scoped 以目前請求的組織（Tenant middleware 放的 orgId）換一份 deps 再呼叫 handler，
+// handler 裡用到的 repository 都只看得到這個組織的資料
+func (d *deps) scoped(h func(*deps, *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) { h(d.inOrg(c.GetInt64("orgId")), c) }
+}
+
+func (d *deps) inOrg(org int64) *deps {
+	if org == 0 {
+		return d
+	}
+	s := *d
+	s.org = org
+	s.users = d.users.InOrg(org)
+	s.regs = d.regs.InOrg(org)
+	s.events = d.events.InOrg(org)
+	s.search = d.search.InOrg(org)
+	if d.revisions != nil {
+		s.revisions = d.revisions.InOrg(org)
+	}
+	if d.invitations != nil {
+		s.invitations = d.invitations.InOrg(org)
+	}
+	if d.orders != nil {
+		s.orders = d.orders.InOrg(org)
+	}
+	if d.promos != nil {
+		s.promos = d.promos.InOrg(org)
+	}
+	if d.feeds != nil {
+		s.feeds = d.feeds.InOrg(org)
+	}
+	return &s
+}
+
+// isAdmin 全域 admin（users.role），或目前組織的 owner / admin；使用者不存在視為非 admin
+func (d *deps) isAdmin(userId int64) (bool, error) {
+	u, err := d.users.GetByID(userId)
+	if errors.Is(err, models.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if u.Role == models.RoleAdmin {
+		return true, nil
+	}
+	role, err := d.orgRole(userId)
+	return models.IsOrgAdmin(role), err
+}
+
+// orgRole 使用者在目前組織的角色；沒設定組織 → ""
+func (d *deps) orgRole(userId int64) (string, error) {
+	if d.orgs == nil || d.org == 0 {
+		return "", nil
+	}
+	return d.orgs.RoleOf(d.org, userId)
+}
+
+func (d *deps) isGlobalAdmin(userId int64) (bool, error) {
+	u, err := d.allUsers.GetByID(userId)
+	if errors.Is(err, models.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil && u.Role == models.RoleAdmin, err
+}
+
+func (d *deps) currentOrgID() int64 {
+	if d.org == 0 {
+		return models.DefaultOrgID
+	}
+	return d.org
+}
+
+// POST /orgs  {"slug": "acme", "name": "Acme", "ownerId": 2}
+// 只有全域 admin 能建立組織；ownerId（選填）成為第一位 owner
+func (d *deps) createOrg(c *gin.Context) {
+	admin, err := d.isGlobalAdmin(c.GetInt64("userId"))
+	if err != nil {
+		respondError(c, err, "Could not create organization.")
+		return
+	}
+	if !admin {
+		respondError(c, models.ErrForbidden, "Only administrators can create organizations.")
+		return
+	}
+	var req struct {
+		Slug    string `json:"slug" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		OwnerID int64  `json:"ownerId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	org := models.Organization{Slug: req.Slug, Name: req.Name}
+	if err := org.Validate(); err != nil {
+		respondError(c, err, "Invalid organization.")
+		return
+	}
+	if req.OwnerID != 0 {
+		if _, err := d.allUsers.GetByID(req.OwnerID); err != nil {
+			respondError(c, err, "Could not find the owner.")
+			return
+		}
+	}
+	if err := d.orgs.Create(&org); err != nil {
+		respondError(c, err, "Could not create organization.") // slug 重複 → 409
+		return
+	}
+	if req.OwnerID != 0 {
+		if err := d.orgs.AddMember(&models.OrgMembership{OrgID: org.ID, UserID: req.OwnerID, Role: models.OrgOwner}); err != nil {
+			respondError(c, err, "Could not add the owner.")
+			return
+		}
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Organization created.", "organization": org})
+}
+
+// GET /orgs/current
+func (d *deps) getCurrentOrg(c *gin.Context) {
+	org, err := d.orgs.GetByID(d.currentOrgID())
+	if err != nil {
+		respondError(c, err, "Could not fetch organization.")
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// requireOrgAdmin 目前組織的 owner / admin 或全域 admin；失敗時已回應錯誤
+// owner=true 表示呼叫者可以指派 / 移除 owner、admin（組織 owner 或全域 admin）
+func (d *deps) requireOrgAdmin(c *gin.Context) (owner, ok bool) {
+	uid := c.GetInt64("userId")
+	global, err := d.isGlobalAdmin(uid)
+	if err != nil {
+		respondError(c, err, "Could not check permissions.")
+		return false, false
+	}
+	if global {
+		return true, true
+	}
+	role, err := d.orgs.RoleOf(d.currentOrgID(), uid)
+	if err != nil {
+		respondError(c, err, "Could not check permissions.")
+		return false, false
+	}
+	if !models.IsOrgAdmin(role) {
+		respondError(c, models.ErrForbidden, "Only organization admins can manage members.")
+		return false, false
+	}
+	return role == models.OrgOwner, true
+}
+
+// GET /orgs/current/members
+func (d *deps) listOrgMembers(c *gin.Context) {
+	if _, ok := d.requireOrgAdmin(c); !ok {
+		return
+	}
+	members, err := d.orgs.ListMembers(d.currentOrgID())
+	if err != nil {
+		respondError(c, err, "Could not fetch members.")
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// POST /orgs/current/members  {"userId": 3, "role": "member"}
+// 使用者可以同時屬於多個組織；owner / admin 只能由 owner 指派
+func (d *deps) addOrgMember(c *gin.Context) {
+	owner, ok := d.requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID int64  `json:"userId" binding:"required"`
+		Role   string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.OrgMember
+	}
+	if !models.ValidOrgRole(req.Role) {
+		v := models.NewValidationError()
+		v.Add("role", "must be owner, admin or member")
+		respondError(c, v, "Invalid member.")
+		return
+	}
+	if models.IsOrgAdmin(req.Role) && !owner {
+		respondError(c, models.ErrForbidden, "Only organization owners can grant admin roles.")
+		return
+	}
+	if _, err := d.allUsers.GetByID(req.UserID); err != nil {
+		respondError(c, err, "Could not find the user.")
+		return
+	}
+	m := models.OrgMembership{OrgID: d.currentOrgID(), UserID: req.UserID, Role: req.Role}
+	if err := d.orgs.AddMember(&m); err != nil {
+		respondError(c, err, "Could not add member.") // 已是成員 → 409
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Member added.", "member": m})
+}
+
+// DELETE /orgs/current/members/:userId
+// 移除後該使用者在這個組織就登入不了（已簽發的 token 到期前仍可用）
+func (d *deps) removeOrgMember(c *gin.Context) {
+	owner, ok := d.requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+	target, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid user id.")
+		return
+	}
+	role, err := d.orgs.RoleOf(d.currentOrgID(), target)
+	if err != nil {
+		respondError(c, err, "Could not remove member.")
+		return
+	}
+	if models.IsOrgAdmin(role) && !owner {
+		respondError(c, models.ErrForbidden, "Only organization owners can remove admins.")
+		return
+	}
+	if err := d.orgs.RemoveMember(d.currentOrgID(), target); err != nil {
+		respondError(c, err, "Could not remove member.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed."})
+}
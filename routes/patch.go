@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// 不允許透過 PATCH 修改的欄位
+// status / publishAt 只能走生命週期端點，members 只能走 /members 端點，orgId 不能改
+var immutableEventFields = []string{"id", "orgId", "userId", "version", "deletedAt", "status", "publishAt", "members"}
+
+// PATCH /events/:id
+// Content-Type: application/merge-patch+json（或 application/json）→ RFC 7396
+// Content-Type: application/json-patch+json → RFC 6902
+// 只把有變動的欄位寫回 Mongo
+func (d *deps) patchEvent(c *gin.Context) {
+	id := c.Param("id")
+	userId := c.GetInt64("userId")
+
+	old, err := d.events.GetByID(id)
+	if err != nil {
+		respondError(c, err, "Could not fetch the event.")
+		return
+	}
+	if !old.Can(userId, models.PermEdit) {
+		respondError(c, models.ErrForbidden, "Not authorized to update event.")
+		return
+	}
+
+	expected, err := expectedVersion(c, 0, old.Version)
+	if err != nil {
+		respondError(c, err, "Invalid precondition.")
+		return
+	}
+	if expected != old.Version { // patch 的基準已過期，不套用
+		respondError(c, &models.VersionConflictError{Current: old}, "Event was modified by someone else.")
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil || len(patch) == 0 {
+		respondBadRequest(c, "Could not read patch document.")
+		return
+	}
+
+	updated, err := applyEventPatch(old, c.GetHeader("Content-Type"), patch)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrPatchTestFailed):
+			respondError(c, fmt.Errorf("%w: %v", models.ErrConflict, err), "Patch test operation failed.")
+		case errors.Is(err, models.ErrValidation):
+			respondError(c, err, "Invalid patch.")
+		default:
+			respondBadRequest(c, "Invalid patch document.")
+		}
+		return
+	}
+
+	changes := models.DiffEvents(old, updated)
+	v := models.NewValidationError()
+	for _, f := range immutableEventFields {
+		if _, ok := changes[f]; ok {
+			v.Add(f, "is read-only")
+		}
+	}
+	v.Merge(updated.ValidateUpdate(old, time.Now()))
+	if err := v.OrNil(); err != nil {
+		respondError(c, err, "Invalid event data.")
+		return
+	}
+
+	if len(changes) > 0 {
+		fields := make([]string, 0, len(changes))
+		for f := range changes {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		updated.Version = expected
+		if err := d.events.Patch(&updated, fields); err != nil {
+			respondError(c, err, "Could not update event.")
+			return
+		}
+		d.recordRevision(c, models.RevisionUpdate, old, updated, 0)
+
+		// 事件後：清快取
+		if d.inv != nil {
+			d.inv.PurgeEventsList(c)
+			d.inv.PurgeEventItem(c, id)
+		}
+	}
+
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully!", "event": updated})
+}
+
+// applyEventPatch 把事件轉成 JSON 套用 patch，再轉回 Event
+func applyEventPatch(old models.Event, contentType string, patch []byte) (models.Event, error) {
+	doc, err := json.Marshal(old)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var out []byte
+	switch mediaType {
+	case utils.JSONPatchContentType:
+		out, err = utils.ApplyJSONPatch(doc, patch)
+	case utils.MergePatchContentType, "application/json", "":
+		out, err = utils.MergePatch(doc, patch)
+	default:
+		return models.Event{}, fmt.Errorf("%w: unsupported content type %q", utils.ErrInvalidPatch, mediaType)
+	}
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	var updated models.Event
+	if err := json.Unmarshal(out, &updated); err != nil {
+		// 型別不符（例如 dateTime 不是時間字串）
+		v := models.NewValidationError()
+		var te *json.UnmarshalTypeError
+		var pe *time.ParseError
+		switch {
+		case errors.As(err, &te) && te.Field != "":
+			v.Add(te.Field, "has the wrong type")
+		case errors.As(err, &pe):
+			v.Add("dateTime", "must be an RFC 3339 timestamp")
+		default:
+			return models.Event{}, fmt.Errorf("%w: %v", utils.ErrInvalidPatch, err)
+		}
+		return models.Event{}, v
+	}
+	updated.NormalizeTimes()
+	updated.NormalizeTaxonomy()
+	updated.NormalizeTickets()
+	updated.NormalizeQuestions()
+	return updated, nil
+}
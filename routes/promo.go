@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// 折扣碼：組織 admin 可建立全組織適用的；事件主辦（PermEdit）只能建立限定自己事件的。
+// 兌換在 checkout 裡做（見 checkout.go），這裡只有管理端點
+
+// POST /promo-codes
+func (d *deps) createPromoCode(c *gin.Context) {
+	var p models.PromoCode
+	if err := c.ShouldBindJSON(&p); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	p.ID, p.Redemptions, p.CreatedBy = 0, 0, c.GetInt64("userId")
+	p.Normalize()
+	if err := p.Validate(time.Now()); err != nil {
+		respondError(c, err, "Invalid promo code.")
+		return
+	}
+	if !d.canManagePromo(c, p.EventIDs, "Not authorized to create promo codes for these events.") {
+		return
+	}
+	if err := d.promos.Create(&p); err != nil {
+		respondError(c, err, "Could not create promo code.") // code 重複 → 409
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Promo code created.", "promoCode": p})
+}
+
+// GET /promo-codes?eventId=
+// 不帶 eventId（全部）限組織 admin；帶 eventId 時該事件的主辦也能看
+func (d *deps) listPromoCodes(c *gin.Context) {
+	eventID := c.Query("eventId")
+	var scope []string
+	if eventID != "" {
+		scope = []string{eventID}
+	}
+	if !d.canManagePromo(c, scope, "Not authorized to view promo codes.") {
+		return
+	}
+	codes, err := d.promos.List(eventID)
+	if err != nil {
+		respondError(c, err, "Could not fetch promo codes.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, codes)
+}
+
+// DELETE /promo-codes/:id → 停用（保留使用紀錄）
+func (d *deps) deactivatePromoCode(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid promo code id.")
+		return
+	}
+	p, err := d.promos.Get(id)
+	if err != nil {
+		respondError(c, err, "Could not fetch promo code.")
+		return
+	}
+	if !d.canManagePromo(c, p.EventIDs, "Not authorized to manage this promo code.") {
+		return
+	}
+	if err := d.promos.Deactivate(id); err != nil {
+		respondError(c, err, "Could not deactivate promo code.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deactivated."})
+}
+
+// canManagePromo 組織 admin 都可以；否則 eventIDs 不能為空且每個事件都要有編輯權限
+// 不行時已經寫好回應
+func (d *deps) canManagePromo(c *gin.Context, eventIDs []string, detail string) bool {
+	uid := c.GetInt64("userId")
+	admin, err := d.isAdmin(uid)
+	if err != nil {
+		respondError(c, err, "Could not check permissions.")
+		return false
+	}
+	if admin {
+		return true
+	}
+	if len(eventIDs) == 0 {
+		respondError(c, models.ErrForbidden, "Only organization admins can manage organization-wide promo codes.")
+		return false
+	}
+	for _, id := range eventIDs {
+		ev, err := d.events.GetByID(id)
+		if err != nil {
+			respondError(c, err, "Could not fetch event "+id+".")
+			return false
+		}
+		if !ev.Can(uid, models.PermEdit) {
+			respondError(c, models.ErrForbidden, detail)
+			return false
+		}
+	}
+	return true
+}
+
+// promoFor 找出折扣碼並確認可以用在這張票；找不到與不適用一律回 400（不洩漏其他事件的折扣碼）
+func (d *deps) promoFor(code, eventID string, t models.TicketType, now time.Time) (models.PromoCode, error) {
+	invalid := models.NewValidationError()
+	invalid.Add("promoCode", "is not valid")
+	if d.promos == nil {
+		return models.PromoCode{}, invalid
+	}
+	p, err := d.promos.GetByCode(code)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return models.PromoCode{}, invalid
+		}
+		return models.PromoCode{}, err
+	}
+	return p, p.AppliesTo(eventID, t, now)
+}
+
+// releasePromo 訂單不再佔名額時歸還折扣碼次數；失敗也沒關係，下次兌換會清掉不佔名額的紀錄
+func (d *deps) releasePromo(o models.Order) {
+	if d.promos != nil && o.PromoCode != "" {
+		_ = d.promos.Release(o.ID)
+	}
+}
@@ -2,9 +2,11 @@
 package routes
 
 import (
+	"errors"
 	"fmt" // 🔥 for quota key
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,6 +24,24 @@ type deps struct {
 	regs   models.RegistrationRepository
 	events models.EventRepository
 	inv    *utils.CacheInvalidator // 🔥 新增：快取失效器
+
+	passwordPolicy models.PasswordPolicy
+	revisions      models.RevisionRepository // 可為 nil（不記歷程）
+	search         models.SearchIndex
+	invitations    models.InvitationRepository // 可為 nil（private 事件只剩擁有者與分享 token）
+
+	// 多租戶（見 orgs.go）：每個請求由 scoped 換成只看得到該組織的 repositories
+	orgs     models.OrganizationRepository // 可為 nil（單一組織，不註冊 /orgs）
+	org      int64                         // 目前請求的組織；0 → 沒裝 Tenant middleware，不限
+	allUsers models.UserRepository         // 不分組織（把別的組織的使用者加進來時用）
+
+	// 購票（見 checkout.go）；任一為 nil → 有票種的事件不開放報名
+	orders   models.OrderRepository
+	payments models.PaymentProvider
+	promos   models.PromoRepository // 可為 nil（不接受折扣碼）
+
+	imports *importJobs // 背景匯入的進度（見 import.go）
+	feeds   models.CalendarFeedRepository // 可為 nil（不提供個人行事曆訂閱，見 calendar.go）
 }
 
 // 由 main 傳入各 Repository + Redis + Invalidator
@@ -32,8 +52,15 @@ func RegisterRoutes(
 	e models.EventRepository,
 	rdb *redis.Client,              // 🔥 新增：給 Quota 用
 	inv *utils.CacheInvalidator,    // 🔥 新增：事件後清快取
+	opts ...Option,                 // 其他可選設定（密碼規則…）
 ) {
-	d := &deps{users: u, regs: r, events: e, inv: inv}
+	d := &deps{users: u, regs: r, events: e, inv: inv, passwordPolicy: models.DefaultPasswordPolicy, allUsers: u, imports: newImportJobs()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.search == nil {
+		d.search = models.NewMemorySearchIndex(e)
+	}
 
 	// ===== ① 全域 IP 限速（20 rps / 40 burst）=====
 	globalLimiter := middlewares.NewRateLimiter(middlewares.LimiterConfig{
@@ -53,11 +80,11 @@ func RegisterRoutes(
 	})
 	server.POST("/signup",
 		authLimiter.Middleware(func(c *gin.Context) string { return "signup:" + c.ClientIP() }),
-		d.signup,
+		d.scoped((*deps).signup),
 	)
 	server.POST("/login",
 		authLimiter.Middleware(func(c *gin.Context) string { return "login:" + c.ClientIP() }),
-		d.login,
+		d.scoped((*deps).login),
 	)
 
 	// ===== ③ 受保護群組：先驗證，再以 userId 限速 + 每日配額 =====
@@ -81,63 +108,169 @@ func RegisterRoutes(
 		KeyFn: func(c *gin.Context) string {
 			uid := c.GetInt64("userId")
 			if uid == 0 { return "" }
-			// 若想細分每個端點，改成 fmt.Sprintf("quota:user:%d:day:%s", uid, c.FullPath())
-			return fmt.Sprintf("quota:user:%d:day", uid)
+			// 若想細分每個端點，改成 fmt.Sprintf("quota:org:%d:user:%d:day:%s", org, uid, c.FullPath())
+			// 同一個人在不同組織各自計算
+			return fmt.Sprintf("quota:org:%d:user:%d:day", c.GetInt64("orgId"), uid)
 		},
 	}))
 
 	// 公開 endpoints（未登入）→ 只有全域 IP 限速與回應快取
-	server.GET("/events", d.getEvents)
-	server.GET("/events/:id", d.getEvent)
+	server.GET("/events", d.scoped((*deps).getEvents))
+	server.GET("/events/search", d.scoped((*deps).searchEvents))
+	server.GET("/events/facets", d.scoped((*deps).getFacets))
+	server.GET("/events/:id", middlewares.OptionalAuthenticate, d.scoped((*deps).getEvent)) // 事件成員可看草稿；private 看邀請 / ?share=
+	server.GET("/events/:id/occurrences", middlewares.OptionalAuthenticate, d.scoped((*deps).listOccurrences))
+	server.GET("/events/:id/tickets", middlewares.OptionalAuthenticate, d.scoped((*deps).listTicketTypes))
 
 	// 登入後 endpoints → 全域 IP + 使用者限速 + 每日配額
-	auth.POST("/events", d.createEvent)
-	auth.PUT("/events/:id", d.updateEvent)
-	auth.DELETE("/events/:id", d.deleteEvent)
-	auth.POST("/events/:id/register", d.registerForEvent)
-	auth.DELETE("/events/:id/register", d.cancelRegistration)
+	auth.POST("/events", d.scoped((*deps).createEvent))
+	auth.POST("/events/import", d.scoped((*deps).importEvents)) // CSV / JSON Lines，?dryRun=true
+	auth.GET("/events/import/:jobId", d.scoped((*deps).getImportJob))
+	auth.PUT("/events/:id", d.scoped((*deps).updateEvent))
+	auth.PATCH("/events/:id", d.scoped((*deps).patchEvent))
+	auth.DELETE("/events/:id", d.scoped((*deps).deleteEvent)) // 軟刪除 → 進垃圾桶
+	auth.GET("/events/trash", d.scoped((*deps).listTrash))
+	auth.POST("/events/:id/restore", d.scoped((*deps).restoreEvent))
+
+	// 生命週期：draft → scheduled → published → completed / cancelled
+	auth.POST("/events/:id/publish", d.scoped(transitionTo(models.StatusPublished)))
+	auth.POST("/events/:id/schedule", d.scoped(transitionTo(models.StatusScheduled)))
+	auth.POST("/events/:id/draft", d.scoped(transitionTo(models.StatusDraft)))
+	auth.POST("/events/:id/cancel", d.scoped(transitionTo(models.StatusCancelled)))
+	auth.POST("/events/:id/complete", d.scoped(transitionTo(models.StatusCompleted)))
+	// private 事件：邀請與分享連結
+	if d.invitations != nil {
+		auth.POST("/events/:id/invitations", d.scoped((*deps).createInvitation))
+		auth.GET("/events/:id/invitations", d.scoped((*deps).listInvitations))
+		auth.DELETE("/events/:id/invitations/:invitationId", d.scoped((*deps).deleteInvitation))
+	}
+	auth.POST("/events/:id/share-links", d.scoped((*deps).createShareLink))
+
+	// 事件成員（owner / co-organizer / checker）與報名名單
+	auth.GET("/events/:id/members", d.scoped((*deps).listMembers))
+	auth.POST("/events/:id/members", d.scoped((*deps).addMember))
+	auth.DELETE("/events/:id/members/:userId", d.scoped((*deps).removeMember))
+	auth.POST("/events/:id/transfer-ownership", d.scoped((*deps).transferOwnership))
+	auth.GET("/events/:id/registrations", d.scoped((*deps).listRegistrations))
+	auth.GET("/events/:id/attendees/export", d.scoped((*deps).exportAttendees)) // CSV / XLSX
+
+	// 票券（QR code）與報到（owner / co-organizer / checker 掃票）
+	auth.GET("/users/me/registrations", d.scoped((*deps).listMyRegistrations))
+	auth.GET("/users/me/registrations/:id/ticket", d.scoped((*deps).getTicket))
+	auth.POST("/events/:id/checkin", d.scoped((*deps).checkIn))
+	auth.GET("/events/:id/checkin/stats", d.scoped((*deps).checkInStats))
+
+	// 重複事件的單次例外
+	auth.POST("/events/:id/occurrences/:occurrence/cancel", d.scoped((*deps).cancelOccurrence))
+	auth.POST("/events/:id/occurrences/:occurrence/move", d.scoped((*deps).moveOccurrence))
+	if d.revisions != nil {
+		auth.GET("/events/:id/history", d.scoped((*deps).getEventHistory))
+		auth.GET("/events/:id/revisions/:rev", d.scoped((*deps).getEventRevision))
+		auth.POST("/events/:id/revisions/:rev/rollback", d.scoped((*deps).rollbackEvent))
+	}
+	auth.POST("/events/:id/register", d.scoped((*deps).registerForEvent))
+	auth.DELETE("/events/:id/register", d.scoped((*deps).cancelRegistration))
+	auth.PUT("/events/:id/rsvp", d.scoped((*deps).updateRSVP)) // 改回覆 / 同行人數
+	auth.POST("/events/:id/register/group", d.scoped((*deps).registerGroup))
+	auth.POST("/events/:id/register/transfer", d.scoped((*deps).offerTransfer))
+	auth.POST("/events/:id/register/transfer/:transferId/accept", d.scoped((*deps).acceptTransfer))
+	auth.POST("/events/:id/register/transfer/:transferId/decline", d.scoped((*deps).declineTransfer))
+	auth.DELETE("/events/:id/register/transfer/:transferId", d.scoped((*deps).cancelTransfer))
+	auth.GET("/users/me/transfers", d.scoped((*deps).listMyTransfers))
+
+	// 購票：訂單查詢與金流 webhook（webhook 不登入，由 provider 驗簽）
+	if d.orders != nil && d.payments != nil {
+		auth.GET("/orders/:id", d.scoped((*deps).getOrder))
+		server.POST("/payments/webhook", d.paymentWebhook)
+	}
+	if d.promos != nil {
+		auth.POST("/promo-codes", d.scoped((*deps).createPromoCode))
+		auth.GET("/promo-codes", d.scoped((*deps).listPromoCodes))
+		auth.DELETE("/promo-codes/:id", d.scoped((*deps).deactivatePromoCode))
+	}
+
+	// 行事曆訂閱：/calendar 由網址裡的 token 驗證，不經過 Authenticate
+	if d.feeds != nil {
+		auth.POST("/users/me/calendar-feed", d.scoped((*deps).createCalendarFeed))
+		auth.DELETE("/users/me/calendar-feed", d.scoped((*deps).revokeCalendarFeed))
+		server.GET("/calendar/:token", d.calendarFeed) // :token 含 .ics
+	}
+
+	// 組織（tenant）與組織成員
+	if d.orgs != nil {
+		server.GET("/orgs/current", d.scoped((*deps).getCurrentOrg))
+		auth.POST("/orgs", d.scoped((*deps).createOrg))
+		auth.GET("/orgs/current/members", d.scoped((*deps).listOrgMembers))
+		auth.POST("/orgs/current/members", d.scoped((*deps).addOrgMember))
+		auth.DELETE("/orgs/current/members/:userId", d.scoped((*deps).removeOrgMember))
+	}
 }
 
 /* -------------------- Events -------------------- */
 
 // GET /events
+// 帶 ?from= / ?to= 時只回範圍內的事件，重複事件展開成單次；
+// ?near=lat,lng&radius=km 依距離排序，?bbox= 限地圖視窗，?category= / ?tag=（可多個）（見 list.go）
 func (d *deps) getEvents(c *gin.Context) {
-	events, err := d.events.GetAll()
+	f, ranged, err := eventFilterFromQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch events. Try again later."})
+		respondError(c, err, "Invalid query.")
 		return
 	}
-	c.JSON(http.StatusOK, events)
+
+	events, err := d.events.GetAll(f)
+	if err != nil {
+		respondError(c, err, "Could not fetch events. Try again later.")
+		return
+	}
+	c.JSON(http.StatusOK, listItems(events, f, ranged))
 }
 
-// GET /events/:id
+// GET /events/:id（/events/:id.ics → iCalendar，見 calendar.go）
 func (d *deps) getEvent(c *gin.Context) {
 	id := c.Param("id") // UUID 字串
+	if id, ok := strings.CutSuffix(id, icsSuffix); ok {
+		d.getEventICS(c, id)
+		return
+	}
 	event, err := d.events.GetByID(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch event. Try again later."})
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	// 看不到的（草稿、別人的 private）當作不存在，不洩漏是否有這個 id
+	if !d.canView(c, event) {
+		respondError(c, models.ErrNotFound, "Could not fetch event.")
 		return
 	}
-	c.JSON(http.StatusOK, event)
+	summary, err := d.rsvpSummary(c, event)
+	if err != nil {
+		respondError(c, err, "Could not fetch RSVP counts.")
+		return
+	}
+	noStoreIfPrivate(c, event)
+	setETag(c, event.Version)
+	c.JSON(http.StatusOK, eventDetail{Event: event, RSVP: summary})
 }
 
 // POST /events
 func (d *deps) createEvent(c *gin.Context) {
 	var event models.Event
 	if err := c.ShouldBindJSON(&event); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		respondBadRequest(c, "Could not parse request data.")
 		return
 	}
 
-	event.UserID = c.GetInt64("userId") // 由 middleware 注入
-	if event.ID == "" {
-		event.ID = uuid.NewString() // 與 SQL 的 registrations(event_id UUID) 對齊
+	if err := prepareNewEvent(&event, c.GetInt64("userId"), time.Now()); err != nil { // userId 由 middleware 注入
+		respondError(c, err, "Invalid event data.")
+		return
 	}
 
 	if err := d.events.Create(&event); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create event. Try again later."})
+		respondError(c, err, "Could not create event.")
 		return
 	}
+	d.recordRevision(c, models.RevisionCreate, models.Event{}, event, 0)
 
 	// 🔥 事件後：清除列表與單筆快取
 	if d.inv != nil {
@@ -145,9 +278,30 @@ func (d *deps) createEvent(c *gin.Context) {
 		d.inv.PurgeEventItem(c, event.ID)
 	}
 
+	setETag(c, event.Version)
 	c.JSON(http.StatusCreated, gin.H{"message": "event created!", "event": event})
 }
 
+// prepareNewEvent 新事件的預設值、正規化與驗證；匯入（import.go）的每一列也走這裡
+func prepareNewEvent(event *models.Event, userID int64, now time.Time) error {
+	event.UserID = userID
+	event.Members = nil // 成員建立後再用 /members 加
+	event.OrgID = 0     // 由 repository 設為目前組織
+	event.Version = 0   // 由 repository 設為 1
+	if event.ID == "" {
+		event.ID = uuid.NewString() // 與 SQL 的 registrations(event_id UUID) 對齊
+	}
+	event.DeletedAt = nil
+	event.NormalizeTimes() // 一律存 UTC
+	event.NormalizeTaxonomy()
+	event.NormalizeTickets()
+	event.NormalizeQuestions()
+	v := models.NewValidationError()
+	v.Merge(event.Validate(now))
+	v.Merge(event.ValidateInitialStatus(now)) // 預設 draft
+	return v.OrNil()
+}
+
 // PUT /events/:id
 func (d *deps) updateEvent(c *gin.Context) {
 	id := c.Param("id")
@@ -155,26 +309,41 @@ func (d *deps) updateEvent(c *gin.Context) {
 
 	old, err := d.events.GetByID(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the event. Try again later."})
+		respondError(c, err, "Could not fetch the event.")
 		return
 	}
-	if old.UserID != userId {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "Not authorized to update event."})
+	if !old.Can(userId, models.PermEdit) {
+		respondError(c, models.ErrForbidden, "Not authorized to update event.")
 		return
 	}
 
 	var incoming models.Event
 	if err := c.ShouldBindJSON(&incoming); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	incoming.ID, incoming.OrgID = id, old.OrgID
+	incoming.UserID, incoming.Members = old.UserID, old.Members     // 成員只能走 /members 端點
+	incoming.Status, incoming.PublishAt = old.Status, old.PublishAt // 狀態只能走轉換端點
+	incoming.DeletedAt = nil
+	incoming.NormalizeTimes()
+	incoming.NormalizeTaxonomy()
+	incoming.NormalizeTickets()
+	incoming.NormalizeQuestions()
+	if incoming.Version, err = expectedVersion(c, incoming.Version, old.Version); err != nil {
+		respondError(c, err, "Invalid precondition.")
+		return
+	}
+	if err := incoming.ValidateUpdate(old, time.Now()); err != nil {
+		respondError(c, err, "Invalid event data.")
 		return
 	}
-	incoming.ID = id
-	incoming.UserID = old.UserID
 
 	if err := d.events.Update(&incoming); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update event. Try again later."})
+		respondError(c, err, "Could not update event.") // 版本不符 → 409 + current
 		return
 	}
+	d.recordRevision(c, models.RevisionUpdate, old, incoming, 0)
 
 	// 事件後：清快取
 	if d.inv != nil {
@@ -182,7 +351,8 @@ func (d *deps) updateEvent(c *gin.Context) {
 		d.inv.PurgeEventItem(c, incoming.ID)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully!"})
+	setETag(c, incoming.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully!", "event": incoming})
 }
 
 // DELETE /events/:id
@@ -192,18 +362,29 @@ func (d *deps) deleteEvent(c *gin.Context) {
 
 	ev, err := d.events.GetByID(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the event. Try again later."})
+		respondError(c, err, "Could not fetch the event.")
 		return
 	}
-	if ev.UserID != userId {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "Not authorized to delete event."})
+	if !ev.Can(userId, models.PermDelete) {
+		respondError(c, models.ErrForbidden, "Not authorized to delete event.")
 		return
 	}
 
-	if err := d.events.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the event."})
+	// 預期版本：If-Match 或 ?version=
+	given, _ := strconv.ParseInt(c.Query("version"), 10, 64)
+	version, err := expectedVersion(c, given, ev.Version)
+	if err != nil {
+		respondError(c, err, "Invalid precondition.")
+		return
+	}
+	if err := d.events.Delete(id, version); err != nil {
+		respondError(c, err, "Could not delete the event.")
 		return
 	}
+	deleted := ev
+	now := time.Now().UTC()
+	deleted.DeletedAt, deleted.Version = &now, version+1
+	d.recordRevision(c, models.RevisionDelete, ev, deleted, 0)
 
 	// 事件後：清快取
 	if d.inv != nil {
@@ -211,52 +392,144 @@ func (d *deps) deleteEvent(c *gin.Context) {
 		d.inv.PurgeEventItem(c, id)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully!"})
+	c.JSON(http.StatusOK, gin.H{"message": "Event moved to trash."})
 }
 
 /* --------------- Registrations ------------------ */
 
-// POST /events/:id/register
+// POST /events/:id/register?occurrence=
+// 重複事件按單次報名（occurrence 為單次 id）
 func (d *deps) registerForEvent(c *gin.Context) {
 	userId := c.GetInt64("userId")
 	eventId := c.Param("id")
+	occurrence := c.Query("occurrence")
+
+	ev, err := d.events.GetByID(eventId)
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if ev.IsPrivate() && !d.canView(c, ev) { // 沒受邀的 private 事件當作不存在
+		respondError(c, models.ErrNotFound, "Could not fetch event.")
+		return
+	}
+	if !openForRegistration(c, ev, occurrence) {
+		return
+	}
+	if ev.HasTickets() { // 有票種 → 走訂單 / 付款
+		d.checkout(c, ev, occurrence)
+		return
+	}
 
-	if _, err := d.events.GetByID(eventId); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch event."})
+	// 報名表單的答案（見 models/questions.go）與 RSVP（見 models/rsvp.go）；沒有 body 時視為沒填、going
+	var req struct {
+		Answers map[string]any `json:"answers"`
+		RSVP    string         `json:"rsvp"`
+		Guests  int            `json:"guests"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Could not parse request data.")
+			return
+		}
+	}
+	v := models.NewValidationError()
+	answers, err := ev.ValidateAnswers(req.Answers)
+	v.Merge(err)
+	rsvp := models.NormalizeRSVP(req.RSVP)
+	v.Merge(models.ValidateRSVP(rsvp, req.Guests))
+	if err := v.OrNil(); err != nil {
+		respondError(c, err, "Invalid registration data.")
 		return
 	}
 
-	if err := d.regs.Register(userId, eventId); err != nil {
-		c.JSON(http.StatusConflict, gin.H{"message": "Already registered or failed."})
+	reg := models.Registration{UserID: userId, EventID: eventId, Occurrence: occurrence, Answers: answers, RSVP: rsvp, Guests: req.Guests}
+	if err := d.regs.Register(&reg, ev.Capacity); err != nil {
+		respondError(c, err, "Could not register for event.") // 重複報名、額滿 → 409
 		return
 	}
 
-	// （視需求決定是否清列表快取，避免報名數顯示延遲）
+	// 報名數顯示在列表，RSVP 統計顯示在單筆（見 getEvent）
 	if d.inv != nil {
 		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, eventId)
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Registered!"})
+	c.JSON(http.StatusCreated, gin.H{"message": "Registered!", "registration": reg})
 }
 
-// DELETE /events/:id/register
+// DELETE /events/:id/register?occurrence=
+// 有訂單的報名：未付款 → 取消訂單；已付款 → 退款（見 checkout.go）
+// 已報到、事件（或該次）已開始或已結束 → 409，不能取消也不退款
 func (d *deps) cancelRegistration(c *gin.Context) {
 	userId := c.GetInt64("userId")
 	eventId := c.Param("id")
 
-	if err := d.regs.Cancel(userId, eventId); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not cancel registration."})
+	ev, err := d.events.GetByID(eventId)
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if err := d.checkCancellable(ev, userId, c.Query("occurrence"), time.Now()); err != nil {
+		respondError(c, err, "Registration can no longer be cancelled.")
+		return
+	}
+
+	if d.orders != nil && d.payments != nil {
+		o, err := d.orders.FindOpen(userId, eventId, c.Query("occurrence"))
+		if err == nil {
+			d.cancelOrder(c, o)
+			return
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			respondError(c, err, "Could not cancel registration.")
+			return
+		}
+	}
+
+	if err := d.regs.Cancel(userId, eventId, c.Query("occurrence")); err != nil {
+		respondError(c, err, "Could not cancel registration.")
 		return
 	}
 
-	// （視需求決定是否清列表快取）
+	// （視需求決定是否清列表快取）；單筆有 RSVP 統計
 	if d.inv != nil {
 		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, eventId)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Cancelled!"})
 }
 
+// checkCancellable 報名還能不能取消：事件已結束、開始時間（重複事件看該次）已過、或已經報到 → ErrConflict
+func (d *deps) checkCancellable(ev models.Event, userID int64, occurrence string, now time.Time) error {
+	if ev.EffectiveStatus() == models.StatusCompleted {
+		return fmt.Errorf("%w: event is completed", models.ErrConflict)
+	}
+	start := ev.DateTime
+	if ev.IsRecurring() && occurrence != "" {
+		o, err := ev.FindOccurrence(occurrence)
+		if err != nil {
+			return err
+		}
+		start = o.Start
+	}
+	if !start.After(now) {
+		return fmt.Errorf("%w: event has already started", models.ErrConflict)
+	}
+
+	regs, err := d.regs.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range regs {
+		if r.EventID == ev.ID && r.Occurrence == occurrence && r.Status == models.RegistrationActive && r.CheckedInAt != nil {
+			return fmt.Errorf("%w: already checked in", models.ErrConflict)
+		}
+	}
+	return nil
+}
+
 /* --------------------- Auth --------------------- */
 
 // POST /signup
@@ -266,13 +539,21 @@ func (d *deps) signup(c *gin.Context) {
 		Password string `json:"password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+
+	v := models.NewValidationError()
+	v.Merge(models.ValidateEmail(req.Email))
+	v.Merge(d.passwordPolicy.Validate(req.Password))
+	if err := v.OrNil(); err != nil {
+		respondError(c, err, "Invalid signup data.")
 		return
 	}
 
 	u := models.User{Email: req.Email, Password: req.Password}
 	if err := d.users.Create(&u); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save user."})
+		respondError(c, err, "Could not save user.") // email 已存在 → 409
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"message": "user created successfully"})
@@ -285,19 +566,19 @@ func (d *deps) login(c *gin.Context) {
 		Password string `json:"password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse request data."})
+		respondBadRequest(c, "Could not parse request data.")
 		return
 	}
 
 	user, err := d.users.ValidateCredentials(req.Email, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "Could not authenticate user1."})
+		respondError(c, err, "Invalid email or password.")
 		return
 	}
 
-	token, err := utils.GenerateToken(user.Email, user.ID)
+	token, err := utils.GenerateToken(user.Email, user.ID, d.org) // 只在目前組織有效
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not authenticate user2."})
+		respondError(c, err, "Could not issue token.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Login successful!", "token": token})
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// RSVP（見 models/rsvp.go）：
+//   POST /events/:id/register 可以帶 {"rsvp", "guests"}，預設 going、不帶同行者
+//   PUT  /events/:id/rsvp 之後再改；going 且事件有 capacity 時，超過名額 → 409
+//   GET  /events/:id 附上回覆統計（rsvp 區塊），所以報名異動要清單筆快取
+// 有票種的事件名額由票種數量管，回覆固定是 going
+
+// PUT /events/:id/rsvp?occurrence=  {"rsvp": "maybe", "guests": 0}
+func (d *deps) updateRSVP(c *gin.Context) {
+	eventId := c.Param("id")
+	ev, err := d.events.GetByID(eventId)
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if ev.IsPrivate() && !d.canView(c, ev) {
+		respondError(c, models.ErrNotFound, "Could not fetch event.")
+		return
+	}
+	if ev.HasTickets() {
+		respondError(c, models.ErrConflict, "RSVP of ticketed events cannot be changed; cancel the registration instead.")
+		return
+	}
+
+	var req struct {
+		RSVP   string `json:"rsvp" binding:"required"`
+		Guests int    `json:"guests"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	rsvp := models.NormalizeRSVP(req.RSVP)
+	if err := models.ValidateRSVP(rsvp, req.Guests); err != nil {
+		respondError(c, err, "Invalid RSVP.")
+		return
+	}
+
+	reg := models.Registration{UserID: c.GetInt64("userId"), EventID: eventId, Occurrence: c.Query("occurrence"), RSVP: rsvp, Guests: req.Guests}
+	if err := d.regs.UpdateRSVP(&reg, ev.Capacity); err != nil {
+		respondError(c, err, "Could not update RSVP.") // 沒報名 → 404、額滿 → 409
+		return
+	}
+	if d.inv != nil {
+		d.inv.PurgeEventItem(c, eventId)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "RSVP updated.", "registration": reg})
+}
+
+// rsvpSummary GET /events/:id 的 rsvp 區塊。
+// 重複事件沒指定 ?occurrence= 時是所有單次的合計，這時沒有 remaining（名額是每一單次各自算）
+type rsvpSummary struct {
+	models.RSVPCounts
+	Attending int  `json:"attending"` // going + 同行者
+	Capacity  int  `json:"capacity,omitempty"`
+	Remaining *int `json:"remaining,omitempty"`
+}
+
+func (d *deps) rsvpSummary(c *gin.Context, ev models.Event) (rsvpSummary, error) {
+	counts, err := d.regs.RSVPCounts(ev.ID)
+	if err != nil {
+		return rsvpSummary{}, err
+	}
+	var s rsvpSummary
+	occ, single := c.GetQuery("occurrence")
+	if !ev.IsRecurring() {
+		occ, single = "", true
+	}
+	if single {
+		s.RSVPCounts = counts[occ]
+	} else {
+		for _, n := range counts {
+			s.RSVPCounts.Add(n)
+		}
+	}
+	s.Attending, s.Capacity = s.RSVPCounts.Attending(), ev.Capacity
+	if ev.Capacity > 0 && single {
+		left := max(ev.Capacity-s.Attending, 0)
+		s.Remaining = &left
+	}
+	return s, nil
+}
+
+// eventDetail 單筆事件加上 rsvp 區塊。
+// models.Event 有自己的 MarshalJSON（附 local），嵌入的話會被提升而丟掉 rsvp，所以接在事件 JSON 的最後
+type eventDetail struct {
+	Event models.Event
+	RSVP  rsvpSummary
+}
+
+func (e eventDetail) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(e.Event)
+	if err != nil {
+		return nil, err
+	}
+	rsvp, err := json.Marshal(e.RSVP)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b[:len(b)-1], `,"rsvp":`...)
+	b = append(b, rsvp...)
+	return append(b, '}'), nil
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+const maxSearchQueryLen = 200
+
+// GET /events/search?q=&from=&to=&tz=&limit=
+// 依相關度排序，highlights 以 <mark> 標出命中字詞
+func (d *deps) searchEvents(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	v := models.NewValidationError()
+	switch {
+	case q == "":
+		v.Add("q", "is required")
+	case utf8.RuneCountInString(q) > maxSearchQueryLen:
+		v.Add("q", "is too long")
+	}
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			v.Add("limit", "must be a positive integer")
+		}
+		limit = n
+	}
+	from, to, ranged, err := dateRange(c)
+	v.Merge(err)
+	if err := v.OrNil(); err != nil {
+		respondError(c, err, "Invalid search query.")
+		return
+	}
+
+	sq := models.SearchQuery{Text: q, Limit: limit}
+	if ranged {
+		sq.Filter.From, sq.Filter.To = &from, &to
+	}
+	hits, err := d.search.Search(sq)
+	if err != nil {
+		respondError(c, err, "Could not search events.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"query": q, "results": hits})
+}
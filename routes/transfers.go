@@ -0,0 +1,356 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// 轉讓與團體報名（見 models/transfers.go）：
+//   POST   /events/:id/register/transfer                  把自己的報名讓給某個 email，對方接受後才生效
+//   POST   /events/:id/register/transfer/:transferId/accept|decline   收件人回覆（以登入帳號的 email 比對）
+//   DELETE /events/:id/register/transfer/:transferId       發出的人撤回
+//   GET    /users/me/transfers                             寄給我的、還沒回覆的轉讓與團體名額
+//   POST   /events/:id/register/group                      一次幫多個 email 報名（全有全無）；沒有帳號的人保留名額等他註冊後接受
+// 收件人還沒有帳號也可以：註冊後在 /users/me/transfers 看得到
+
+// openForRegistration 事件與單次是否還能報名；不行時已回應錯誤
+func openForRegistration(c *gin.Context, ev models.Event, occurrence string) bool {
+	if !ev.AcceptsRegistrations() {
+		respondError(c, models.ErrConflict, "Event is not open for registration ("+ev.EffectiveStatus()+").")
+		return false
+	}
+	if err := checkOccurrence(ev, occurrence, time.Now()); err != nil {
+		respondError(c, err, "Invalid occurrence.")
+		return false
+	}
+	return true
+}
+
+// myEmail 目前使用者的 email（NormalizeEmail 過），轉讓以 email 比對收件人
+func (d *deps) myEmail(c *gin.Context) (string, error) {
+	u, err := d.users.GetByID(c.GetInt64("userId"))
+	if err != nil {
+		return "", err
+	}
+	return models.NormalizeEmail(u.Email), nil
+}
+
+// transferFor 讀 :transferId 並確認屬於 :id 這個事件；失敗時已回應錯誤
+func (d *deps) transferFor(c *gin.Context) (models.Transfer, bool) {
+	id, err := strconv.ParseInt(c.Param("transferId"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid transfer id.")
+		return models.Transfer{}, false
+	}
+	t, err := d.regs.GetTransfer(id)
+	if err == nil && t.EventID != c.Param("id") {
+		err = models.ErrNotFound
+	}
+	if err != nil {
+		respondError(c, err, "Could not fetch the transfer.")
+		return models.Transfer{}, false
+	}
+	return t, true
+}
+
+// POST /events/:id/register/transfer?occurrence=  {"email": "..."}
+func (d *deps) offerTransfer(c *gin.Context) {
+	userId := c.GetInt64("userId")
+	occurrence := c.Query("occurrence")
+	ev, err := d.events.GetByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if !openForRegistration(c, ev, occurrence) {
+		return
+	}
+
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	email := models.NormalizeEmail(req.Email)
+	v := models.NewValidationError()
+	v.Merge(models.ValidateEmail(email))
+	if me, err := d.myEmail(c); err == nil && me == email {
+		v.Add("email", "must be someone else")
+	}
+	if err := v.OrNil(); err != nil {
+		respondError(c, err, "Invalid transfer.")
+		return
+	}
+
+	mine, err := d.regs.ListByUser(userId)
+	if err != nil {
+		respondError(c, err, "Could not fetch registrations.")
+		return
+	}
+	var reg *models.Registration
+	for i, r := range mine {
+		if r.EventID == ev.ID && r.Occurrence == occurrence && r.Status == models.RegistrationActive {
+			reg = &mine[i]
+		}
+	}
+	switch {
+	case reg == nil:
+		respondError(c, models.ErrNotFound, "You are not registered for this event.")
+		return
+	case reg.CheckedInAt != nil:
+		respondError(c, models.ErrConflict, "Checked-in registrations cannot be transferred.")
+		return
+	}
+
+	t := models.Transfer{Kind: models.TransferRegistration, EventID: ev.ID, Occurrence: occurrence, RegistrationID: reg.ID, FromUserID: userId, Email: email}
+	if err := d.regs.OfferTransfer(&t); err != nil {
+		respondError(c, err, "Could not offer the transfer.") // 已有 pending 的轉讓 → 409
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Transfer offered. The recipient has to accept it.", "transfer": t})
+}
+
+// POST /events/:id/register/transfer/:transferId/accept  {"answers": {...}}
+// 答案是收件人自己的（原本的答案不跟著轉）；團體名額也在這裡填
+func (d *deps) acceptTransfer(c *gin.Context) {
+	t, ok := d.transferFor(c)
+	if !ok {
+		return
+	}
+	if me, err := d.myEmail(c); err != nil || me != t.Email {
+		respondError(c, models.ErrNotFound, "Could not fetch the transfer.") // 不是寄給我的當作不存在
+		return
+	}
+	ev, err := d.events.GetByID(t.EventID)
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if !openForRegistration(c, ev, t.Occurrence) {
+		return
+	}
+	if ev.IsPrivate() && !d.canView(c, ev) {
+		respondError(c, models.ErrForbidden, "This private event requires an invitation; ask an organizer.")
+		return
+	}
+
+	var req struct {
+		Answers map[string]any `json:"answers"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Could not parse request data.")
+			return
+		}
+	}
+	answers, err := ev.ValidateAnswers(req.Answers)
+	if err != nil {
+		respondError(c, err, "Invalid answers.")
+		return
+	}
+
+	reg, err := d.regs.AcceptTransfer(t.ID, c.GetInt64("userId"), answers)
+	if err != nil {
+		respondError(c, err, "Could not accept the transfer.") // 已處理過、已報名、報名已取消 → 409
+		return
+	}
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, ev.ID)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer accepted.", "registration": reg})
+}
+
+// POST /events/:id/register/transfer/:transferId/decline
+func (d *deps) declineTransfer(c *gin.Context) {
+	t, ok := d.transferFor(c)
+	if !ok {
+		return
+	}
+	if me, err := d.myEmail(c); err != nil || me != t.Email {
+		respondError(c, models.ErrNotFound, "Could not fetch the transfer.")
+		return
+	}
+	d.resolveTransfer(c, t, models.TransferDeclined, "Transfer declined.")
+}
+
+// DELETE /events/:id/register/transfer/:transferId
+func (d *deps) cancelTransfer(c *gin.Context) {
+	t, ok := d.transferFor(c)
+	if !ok {
+		return
+	}
+	if t.FromUserID != c.GetInt64("userId") {
+		respondError(c, models.ErrNotFound, "Could not fetch the transfer.")
+		return
+	}
+	d.resolveTransfer(c, t, models.TransferCancelled, "Transfer cancelled.")
+}
+
+func (d *deps) resolveTransfer(c *gin.Context, t models.Transfer, status, message string) {
+	if err := d.regs.ResolveTransfer(t.ID, status); err != nil {
+		respondError(c, err, "Could not update the transfer.") // 已接受 / 已處理 → 409
+		return
+	}
+	if d.inv != nil && t.Kind == models.TransferGroupSeat {
+		d.inv.PurgeEventItem(c, t.EventID) // 保留的名額釋出
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// GET /users/me/transfers
+func (d *deps) listMyTransfers(c *gin.Context) {
+	me, err := d.myEmail(c)
+	if err != nil {
+		respondError(c, err, "Could not fetch user.")
+		return
+	}
+	ts, err := d.regs.PendingTransfers(me)
+	if err != nil {
+		respondError(c, err, "Could not fetch transfers.")
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, ts)
+}
+
+// POST /events/:id/register/group?occurrence=
+// {"emails": ["a@example.com", ...], "answers": {"a@example.com": {"<questionId>": "M"}}}
+// 已有帳號的人直接報名（答案依 email 帶），沒有帳號的保留名額；只開放免費事件，private 事件只限主辦方
+func (d *deps) registerGroup(c *gin.Context) {
+	userId := c.GetInt64("userId")
+	occurrence := c.Query("occurrence")
+	ev, err := d.events.GetByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Could not fetch event.")
+		return
+	}
+	if ev.IsPrivate() && !d.canView(c, ev) {
+		respondError(c, models.ErrNotFound, "Could not fetch event.")
+		return
+	}
+	if ev.IsPrivate() && !ev.Can(userId, models.PermEdit) {
+		respondError(c, models.ErrForbidden, "Only organizers can register groups for private events.")
+		return
+	}
+	if ev.HasTickets() {
+		respondError(c, models.ErrConflict, "Group registration is only available for free events; buy tickets individually.")
+		return
+	}
+	if !openForRegistration(c, ev, occurrence) {
+		return
+	}
+
+	var req struct {
+		Emails  []string                  `json:"emails" binding:"required"`
+		Answers map[string]map[string]any `json:"answers"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	v := models.NewValidationError()
+	if len(req.Emails) == 0 || len(req.Emails) > models.MaxGroupSize {
+		v.Add("emails", fmt.Sprintf("must list 1 to %d emails", models.MaxGroupSize))
+	}
+	answers := map[string]map[string]any{}
+	for email, a := range req.Answers {
+		answers[models.NormalizeEmail(email)] = a
+	}
+	emails := make([]string, 0, len(req.Emails))
+	for i, raw := range req.Emails {
+		email, field := models.NormalizeEmail(raw), fmt.Sprintf("emails[%d]", i)
+		switch {
+		case models.ValidateEmail(email) != nil:
+			v.Add(field, "must be a valid email address")
+		case slices.Contains(emails, email):
+			v.Add(field, "is listed twice")
+		default:
+			emails = append(emails, email)
+		}
+	}
+	for email := range answers {
+		if !slices.Contains(emails, email) {
+			v.Add("answers."+email, "is not one of the emails")
+		}
+	}
+
+	// 有帳號 → 報名；沒有 → 保留名額
+	var regs []models.Registration
+	var holds []models.Transfer
+	byUser := map[int64]string{}
+	for _, email := range emails {
+		u, err := d.users.GetByEmail(email)
+		if errors.Is(err, models.ErrNotFound) {
+			holds = append(holds, models.Transfer{Kind: models.TransferGroupSeat, EventID: ev.ID, Occurrence: occurrence, FromUserID: userId, Email: email})
+			continue
+		}
+		if err != nil {
+			respondError(c, err, "Could not look up users.")
+			return
+		}
+		a, err := ev.ValidateAnswers(answers[email])
+		var ve *models.ValidationError
+		if errors.As(err, &ve) {
+			for f, msg := range ve.Fields {
+				v.Add("answers."+email+strings.TrimPrefix(f, "answers"), msg)
+			}
+		}
+		regs = append(regs, models.Registration{UserID: u.ID, EventID: ev.ID, Occurrence: occurrence, Answers: a})
+		byUser[u.ID] = email
+	}
+	if err := v.OrNil(); err != nil {
+		respondError(c, err, "Invalid group registration.")
+		return
+	}
+
+	// 先找出已報名的人，錯誤訊息才說得出是誰（真正的保證在 RegisterGroup 的交易）
+	existing, err := d.regs.ListByEvent(ev.ID)
+	if err != nil {
+		respondError(c, err, "Could not fetch registrations.")
+		return
+	}
+	for _, r := range existing {
+		if email, ok := byUser[r.UserID]; ok && r.Occurrence == occurrence && r.Status == models.RegistrationActive {
+			respondError(c, models.ErrConflict, email+" is already registered.")
+			return
+		}
+	}
+
+	if err := d.regs.RegisterGroup(regs, holds, ev.Capacity); err != nil {
+		respondError(c, err, "Could not register the group.") // 額滿、同時有人報名 → 409
+		return
+	}
+	// private 事件：順便邀請，被報名的人才看得到事件
+	if ev.IsPrivate() && d.invitations != nil {
+		for _, email := range emails {
+			inv := models.Invitation{EventID: ev.ID, Email: email, InvitedBy: userId}
+			if err := d.invitations.Create(&inv); err != nil && !errors.Is(err, models.ErrConflict) {
+				log.Printf("invite %s to %s: %v", email, ev.ID, err)
+			}
+		}
+	}
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, ev.ID)
+	}
+	if regs == nil {
+		regs = []models.Registration{}
+	}
+	if holds == nil {
+		holds = []models.Transfer{}
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Group registered.", "registrations": regs, "invited": holds})
+}
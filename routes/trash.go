@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// GET /events/trash
+// 一般使用者只看到自己刪掉的事件；admin 看到全部
+func (d *deps) listTrash(c *gin.Context) {
+	userId := c.GetInt64("userId")
+
+	admin, err := d.isAdmin(userId)
+	if err != nil {
+		respondError(c, err, "Could not fetch trash.")
+		return
+	}
+	owner := userId
+	if admin {
+		owner = 0
+	}
+
+	events, err := d.events.ListDeleted(owner)
+	if err != nil {
+		respondError(c, err, "Could not fetch trash.")
+		return
+	}
+	if events == nil {
+		events = []models.Event{}
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// POST /events/:id/restore
+func (d *deps) restoreEvent(c *gin.Context) {
+	id := c.Param("id")
+	userId := c.GetInt64("userId")
+
+	ev, err := d.events.GetDeleted(id)
+	if err != nil {
+		respondError(c, err, "Could not find the event in trash.")
+		return
+	}
+	if !ev.Can(userId, models.PermDelete) {
+		admin, err := d.isAdmin(userId)
+		if err != nil {
+			respondError(c, err, "Could not restore the event.")
+			return
+		}
+		if !admin {
+			respondError(c, models.ErrForbidden, "Not authorized to restore event.")
+			return
+		}
+	}
+
+	version, err := expectedVersion(c, 0, ev.Version)
+	if err != nil {
+		respondError(c, err, "Invalid precondition.")
+		return
+	}
+	if err := d.events.Restore(id, version); err != nil {
+		respondError(c, err, "Could not restore the event.")
+		return
+	}
+	restored := ev
+	restored.DeletedAt = nil
+	restored.Version = version + 1
+	d.recordRevision(c, models.RevisionRestore, ev, restored, 0)
+
+	// 還原後重新出現在列表
+	if d.inv != nil {
+		d.inv.PurgeEventsList(c)
+		d.inv.PurgeEventItem(c, id)
+	}
+
+	setETag(c, restored.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Event restored!", "event": restored})
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+)
+
+// setETag 以事件版本當 ETag（強驗證）
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// expectedVersion 決定條件寫入的預期版本，優先順序：
+// If-Match header → body / query 帶的 version → 剛讀到的 current
+// If-Match: * 代表不檢查，直接用 current
+func expectedVersion(c *gin.Context, given, current int64) (int64, error) {
+	if im := strings.TrimSpace(c.GetHeader("If-Match")); im != "" {
+		if im == "*" {
+			return current, nil
+		}
+		tag := strings.Trim(strings.TrimPrefix(im, "W/"), `"`)
+		v, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil || v < 0 {
+			ve := models.NewValidationError()
+			ve.Add("If-Match", "must be an event version ETag")
+			return 0, ve
+		}
+		return v, nil
+	}
+	if given > 0 {
+		return given, nil
+	}
+	return current, nil
+}
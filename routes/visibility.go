@@ -0,0 +1,180 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+// 分享連結的有效期限
+const (
+	defaultShareTTL = 7 * 24 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+)
+
+// shareToken 分享 token 可放 ?share= 或 X-Share-Token header
+func shareToken(c *gin.Context) string {
+	if t := c.Query("share"); t != "" {
+		return t
+	}
+	return c.GetHeader("X-Share-Token")
+}
+
+// canView 目前的呼叫者能不能讀這個事件：
+// 草稿 / 排程中只有事件成員；private 另外允許受邀者與持有效分享 token 的人
+func (d *deps) canView(c *gin.Context, ev models.Event) bool {
+	uid := c.GetInt64("userId")
+	if ev.Can(uid, models.PermView) {
+		return true
+	}
+	if !ev.IsPublic() {
+		return false
+	}
+	if !ev.IsPrivate() {
+		return true
+	}
+	if t := shareToken(c); t != "" && utils.VerifyShareToken(t, ev.ID) == nil {
+		return true
+	}
+	if uid == 0 || d.invitations == nil {
+		return false
+	}
+	u, err := d.users.GetByID(uid)
+	if err != nil {
+		return false
+	}
+	ok, err := d.invitations.IsInvited(ev.ID, models.NormalizeEmail(u.Email))
+	if err != nil {
+		log.Printf("check invitation for %s: %v", ev.ID, err)
+	}
+	return ok
+}
+
+// noStoreIfPrivate 非 public 的回應因人而異，告訴 ResponseCache（與瀏覽器、CDN）不要存
+func noStoreIfPrivate(c *gin.Context, ev models.Event) {
+	if ev.EffectiveVisibility() != models.VisibilityPublic || !ev.IsPublic() {
+		c.Header("Cache-Control", "private, no-store")
+	}
+}
+
+// eventWith 讀事件並確認呼叫者在這個事件有權限 p；失敗時已回應錯誤，ok=false
+func (d *deps) eventWith(c *gin.Context, p models.Permission, detail string) (models.Event, bool) {
+	ev, err := d.events.GetByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Could not fetch the event.")
+		return models.Event{}, false
+	}
+	if !ev.Can(c.GetInt64("userId"), p) {
+		respondError(c, models.ErrForbidden, detail)
+		return models.Event{}, false
+	}
+	return ev, true
+}
+
+/* -------------------- Invitations -------------------- */
+
+// POST /events/:id/invitations  {"email": "..."}
+func (d *deps) createInvitation(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermEdit, "Not authorized to invite.")
+	if !ok {
+		return
+	}
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Could not parse request data.")
+		return
+	}
+	email := models.NormalizeEmail(req.Email)
+	if err := models.ValidateEmail(email); err != nil {
+		respondError(c, err, "Invalid invitation.")
+		return
+	}
+
+	inv := models.Invitation{EventID: ev.ID, Email: email, InvitedBy: c.GetInt64("userId")}
+	if err := d.invitations.Create(&inv); err != nil {
+		respondError(c, err, "Could not create invitation.") // 已邀請過 → 409
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Invitation created.", "invitation": inv})
+}
+
+// GET /events/:id/invitations
+func (d *deps) listInvitations(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermEdit, "Not authorized to view invitations.")
+	if !ok {
+		return
+	}
+	invs, err := d.invitations.List(ev.ID)
+	if err != nil {
+		respondError(c, err, "Could not fetch invitations.")
+		return
+	}
+	c.JSON(http.StatusOK, invs)
+}
+
+// DELETE /events/:id/invitations/:invitationId
+func (d *deps) deleteInvitation(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermEdit, "Not authorized to revoke invitations.")
+	if !ok {
+		return
+	}
+	invID, err := strconv.ParseInt(c.Param("invitationId"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid invitation id.")
+		return
+	}
+	if err := d.invitations.Delete(ev.ID, invID); err != nil {
+		respondError(c, err, "Could not revoke invitation.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked."})
+}
+
+/* -------------------- Share links -------------------- */
+
+// POST /events/:id/share-links  {"expiresIn": "72h"}（預設 7 天，最多 30 天）
+// 回傳簽章 token，持有者在到期前可用 ?share= 讀 private 事件
+func (d *deps) createShareLink(c *gin.Context) {
+	ev, ok := d.eventWith(c, models.PermEdit, "Not authorized to share the event.")
+	if !ok {
+		return
+	}
+	var req struct {
+		ExpiresIn string `json:"expiresIn"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Could not parse request data.")
+			return
+		}
+	}
+	ttl := defaultShareTTL
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 || ttl > maxShareTTL {
+			v := models.NewValidationError()
+			v.Add("expiresIn", "must be a positive duration up to 720h")
+			respondError(c, v, "Invalid share link.")
+			return
+		}
+	}
+
+	token, exp, err := utils.GenerateShareToken(ev.ID, ttl)
+	if err != nil {
+		respondError(c, err, "Could not create share link.")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     token,
+		"expiresAt": exp,
+		"path":      "/events/" + ev.ID + "?share=" + token,
+	})
+}
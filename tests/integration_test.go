@@ -11,15 +11,19 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
 
@@ -119,6 +123,7 @@ func newIntegrationServer(t *testing.T) itDeps {
 	// 失效器 + 回應快取（跟 main 類似）
 	inv := utils.NewCacheInvalidator(rdb)
 	s := gin.New()
+	s.Use(middlewares.RequestID())
 	s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
 	routes.RegisterRoutes(s, ur, rr, er, rdb, inv)
 
@@ -151,14 +156,14 @@ func TestIntegration_FullFlow(t *testing.T) {
 	// 1) signup
 	email := "it_user_" + time.Now().Format("150405") + "@ex.com"
 	w := req(deps.s, http.MethodPost, "/signup",
-		`{"email":"`+email+`","password":"p"}`, "")
+		`{"email":"`+email+`","password":"Passw0rd"}`, "")
 	if w.Code != http.StatusCreated && w.Code != http.StatusOK {
 		t.Fatalf("signup code=%d body=%s", w.Code, w.Body.String())
 	}
 
 	// 2) login -> token
 	w = req(deps.s, http.MethodPost, "/login",
-		`{"email":"`+email+`","password":"p"}`, "")
+		`{"email":"`+email+`","password":"Passw0rd"}`, "")
 	if w.Code != http.StatusOK {
 		t.Fatalf("login code=%d body=%s", w.Code, w.Body.String())
 	}
@@ -179,7 +184,7 @@ func TestIntegration_FullFlow(t *testing.T) {
 	}
 
 	// 5) 建立事件（Mongo 寫入 + 清單快取失效）
-	body := `{"name":"IT Demo","description":"d","location":"L","dateTime":"2025-01-01T00:00:00Z"}`
+	body := `{"name":"IT Demo","description":"d","location":"L","dateTime":"2030-01-01T00:00:00Z","status":"published"}`
 	w = req(deps.s, http.MethodPost, "/events", body, loginResp.Token)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("create event code=%d body=%s", w.Code, w.Body.String())
@@ -203,7 +208,7 @@ func TestIntegration_FullFlow(t *testing.T) {
 	}
 
 	// 8) 更新事件（必須為擁有者）
-	upd := `{"name":"IT Demo v2","description":"changed","location":"Room 2","dateTime":"2025-01-02T03:04:05Z"}`
+	upd := `{"name":"IT Demo v2","description":"changed","location":"Room 2","dateTime":"2030-01-02T03:04:05Z"}`
 	w = req(deps.s, http.MethodPut, "/events/"+created.Event.ID, upd, loginResp.Token)
 	if w.Code != http.StatusOK {
 		t.Fatalf("update code=%d body=%s", w.Code, w.Body.String())
@@ -229,3 +234,258 @@ func TestIntegration_FullFlow(t *testing.T) {
 		t.Fatalf("delete event code=%d body=%s", w.Code, w.Body.String())
 	}
 }
+
+// 折扣碼同時兌換：10 個人搶 3 次，只能成功 3 次（promo_codes 那一列 FOR UPDATE）
+func TestIntegration_PromoRedeemIsAtomic(t *testing.T) {
+	deps := newIntegrationServer(t)
+	orders := models.NewSQLOrderRepository(deps.sqlDB)
+	promos := models.NewSQLPromoRepository(deps.sqlDB)
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	eventID := uuid.NewString()
+
+	const buyers = 10
+	orderIDs := make([]int64, buyers)
+	userIDs := make([]int64, buyers)
+	for i := range orderIDs {
+		if err := deps.sqlDB.QueryRow(`INSERT INTO users(email, password) VALUES ($1, 'x') RETURNING id`,
+			fmt.Sprintf("promo-%d-%s@example.com", i, suffix)).Scan(&userIDs[i]); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+		o := models.Order{UserID: userIDs[i], EventID: eventID, TicketTypeID: "ga", Amount: 900, Currency: "USD", ExpiresAt: time.Now().Add(time.Hour)}
+		if err := orders.Create(&o, 0); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+		orderIDs[i] = o.ID
+	}
+	p := models.PromoCode{Code: "RACE" + strings.ToUpper(suffix), Kind: models.PromoPercent, Value: 10, MaxRedemptions: 3, CreatedBy: userIDs[0]}
+	if err := promos.Create(&p); err != nil {
+		t.Fatalf("create promo: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ok := 0
+	for i := range orderIDs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := promos.Redeem(p.ID, userIDs[i], orderIDs[i])
+			if err != nil && !errors.Is(err, models.ErrConflict) {
+				t.Errorf("redeem: %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				ok++
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got, _ := promos.Get(p.ID); ok != 3 || got.Redemptions != 3 {
+		t.Fatalf("want exactly 3 redemptions, got ok=%d stored=%d", ok, got.Redemptions)
+	}
+}
+
+// 同一張票同時掃好幾次：條件更新只會讓一次成功
+func TestIntegration_CheckInIsOnce(t *testing.T) {
+	deps := newIntegrationServer(t)
+	regs := models.NewSQLRegistrationRepository(deps.sqlDB)
+	var uid int64
+	if err := deps.sqlDB.QueryRow(`INSERT INTO users(email, password) VALUES ($1, 'x') RETURNING id`,
+		"checkin-"+strconv.FormatInt(time.Now().UnixNano(), 36)+"@example.com").Scan(&uid); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := regs.Register(&models.Registration{UserID: uid, EventID: uuid.NewString()}, 0); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	mine, err := regs.ListByUser(uid)
+	if err != nil || len(mine) != 1 {
+		t.Fatalf("list by user: %v %+v", err, mine)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ok := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := regs.CheckIn(mine[0].ID, uid, time.Now())
+			if err != nil && !errors.Is(err, models.ErrConflict) {
+				t.Errorf("check in: %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				ok++
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := regs.Get(mine[0].ID); ok != 1 || got.CheckedInAt == nil || got.CheckedInBy != uid {
+		t.Fatalf("want exactly one check-in, got ok=%d reg=%+v", ok, got)
+	}
+}
+
+// 同時搶最後幾個名額：advisory lock 讓 going + 同行者不會超過 capacity
+func TestIntegration_RSVPCapacity(t *testing.T) {
+	deps := newIntegrationServer(t)
+	regs := models.NewSQLRegistrationRepository(deps.sqlDB)
+	eventID := uuid.NewString()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seats := 0
+	for i := 0; i < 6; i++ {
+		var uid int64
+		if err := deps.sqlDB.QueryRow(`INSERT INTO users(email, password) VALUES ($1, 'x') RETURNING id`,
+			fmt.Sprintf("rsvp-%d-%s@example.com", i, strconv.FormatInt(time.Now().UnixNano(), 36))).Scan(&uid); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reg := &models.Registration{UserID: uid, EventID: eventID, RSVP: models.RSVPGoing, Guests: 1}
+			err := regs.Register(reg, 5)
+			if err != nil && !errors.Is(err, models.ErrConflict) {
+				t.Errorf("register: %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				seats += reg.Seats()
+			}
+		}()
+	}
+	wg.Wait()
+	counts, err := regs.RSVPCounts(eventID)
+	if err != nil || seats != 4 || counts[""].Attending() != 4 {
+		t.Fatalf("want 2 registrations with 4 seats, got seats=%d counts=%+v err=%v", seats, counts, err)
+	}
+}
+
+// 接受轉讓：報名換人（id 不變），已付款的訂單一起轉過去
+func TestIntegration_TransferMovesPaidOrder(t *testing.T) {
+	deps := newIntegrationServer(t)
+	regs := models.NewSQLRegistrationRepository(deps.sqlDB)
+	orders := models.NewSQLOrderRepository(deps.sqlDB)
+	eventID := uuid.NewString()
+
+	ids := make([]int64, 2)
+	for i := range ids {
+		if err := deps.sqlDB.QueryRow(`INSERT INTO users(email, password) VALUES ($1, 'x') RETURNING id`,
+			fmt.Sprintf("transfer-%d-%s@example.com", i, strconv.FormatInt(time.Now().UnixNano(), 36))).Scan(&ids[i]); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+	}
+	from, to := ids[0], ids[1]
+	o := models.Order{UserID: from, EventID: eventID, TicketTypeID: "ga", Amount: 1500, Currency: "USD", Status: models.OrderPaid,
+		Provider: "local", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := orders.Create(&o, 0); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	reg := models.Registration{UserID: from, EventID: eventID}
+	if err := regs.Register(&reg, 0); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	tr := models.Transfer{Kind: models.TransferRegistration, EventID: eventID, RegistrationID: reg.ID, FromUserID: from, Email: "to@example.com"}
+	if err := regs.OfferTransfer(&tr); err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+	got, err := regs.AcceptTransfer(tr.ID, to, nil)
+	if err != nil || got.ID != reg.ID || got.UserID != to {
+		t.Fatalf("accept = %+v, %v", got, err)
+	}
+	if moved, err := orders.FindOpen(to, eventID, ""); err != nil || moved.ID != o.ID {
+		t.Fatalf("order should follow the registration: %+v %v", moved, err)
+	}
+	if _, err := regs.AcceptTransfer(tr.ID, to, nil); !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("second accept want ErrConflict, got %v", err)
+	}
+}
+
+// 匯出名單：逐筆帶出 email；fn 回傳錯誤就停止
+func TestIntegration_StreamAttendees(t *testing.T) {
+	deps := newIntegrationServer(t)
+	regs := models.NewSQLRegistrationRepository(deps.sqlDB)
+	eventID := uuid.NewString()
+
+	emails := map[int64]string{}
+	for i := 0; i < 3; i++ {
+		email := fmt.Sprintf("export-%d-%s@example.com", i, strconv.FormatInt(time.Now().UnixNano(), 36))
+		var uid int64
+		if err := deps.sqlDB.QueryRow(`INSERT INTO users(email, password) VALUES ($1, 'x') RETURNING id`, email).Scan(&uid); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+		emails[uid] = email
+		reg := &models.Registration{UserID: uid, EventID: eventID, Occurrence: []string{"a", "b", "b"}[i], RSVP: models.RSVPGoing,
+			Answers: models.Answers{"diet": "vegan"}}
+		if err := regs.Register(reg, 0); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	var got []models.Attendee
+	err := regs.StreamAttendees(eventID, "b", func(a models.Attendee) error { got = append(got, a); return nil })
+	if err != nil || len(got) != 2 || got[0].Email != emails[got[0].UserID] || got[1].Answers.Text("diet") != "vegan" {
+		t.Fatalf("attendees = %+v, err = %v", got, err)
+	}
+	stop := errors.New("stop")
+	n := 0
+	if err := regs.StreamAttendees(eventID, "", func(models.Attendee) error { n++; return stop }); !errors.Is(err, stop) || n != 1 {
+		t.Fatalf("want stop after first row, got n=%d err=%v", n, err)
+	}
+}
+
+// 匯入的批次寫入：每筆都設好版本與組織，寫入後查得到
+func TestIntegration_CreateManyEvents(t *testing.T) {
+	deps := newIntegrationServer(t)
+	repo := models.NewMongoEventRepository(deps.mgoCli.Database("app").Collection("events")).InOrg(models.DefaultOrgID)
+
+	batch := make([]models.Event, 3)
+	for i := range batch {
+		batch[i] = models.Event{ID: uuid.NewString(), Name: fmt.Sprintf("Imported %d", i), Location: "Taipei",
+			DateTime: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), Status: models.StatusDraft, UserID: 1}
+	}
+	n, err := repo.CreateMany(batch)
+	if err != nil || n != 3 {
+		t.Fatalf("CreateMany = %d, %v", n, err)
+	}
+	for _, e := range batch {
+		got, err := repo.GetByID(e.ID)
+		if err != nil || got.Version != 1 || got.OrgID != models.DefaultOrgID || got.Name != e.Name {
+			t.Fatalf("GetByID(%s) = %+v, %v", e.ID, got, err)
+		}
+	}
+}
+
+// 訂閱網址：重新產生會取代舊的 token；撤銷後查不到
+func TestIntegration_CalendarFeeds(t *testing.T) {
+	deps := newIntegrationServer(t)
+	feeds := models.NewSQLCalendarFeedRepository(deps.sqlDB).InOrg(models.DefaultOrgID)
+	var uid int64
+	email := "feed-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "@example.com"
+	if err := deps.sqlDB.QueryRow(`INSERT INTO users(email, password) VALUES ($1, 'x') RETURNING id`, email).Scan(&uid); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+
+	old, cur := models.CalendarTokenHash(email+"-1"), models.CalendarTokenHash(email+"-2")
+	if _, err := feeds.Rotate(uid, old); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if _, err := feeds.Rotate(uid, cur); err != nil {
+		t.Fatalf("rotate again: %v", err)
+	}
+	if _, err := feeds.ByToken(old); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("old token want ErrNotFound, got %v", err)
+	}
+	if f, err := feeds.ByToken(cur); err != nil || f.UserID != uid || f.OrgID != models.DefaultOrgID {
+		t.Fatalf("ByToken = %+v, %v", f, err)
+	}
+	if err := feeds.Revoke(uid); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := feeds.Revoke(uid); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("revoke again want ErrNotFound, got %v", err)
+	}
+}
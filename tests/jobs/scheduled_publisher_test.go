@@ -0,0 +1,45 @@
+// 測試目的：ScheduledPublisher 只發布 publishAt 已到的 scheduled 事件，記一筆系統的修改歷程，並呼叫 onPublish
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"restapi/jobs"
+	"restapi/models"
+	"restapi/tests/mocks"
+)
+
+func TestScheduledPublisher_RunOnce(t *testing.T) {
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	er := &mocks.MockEventRepo{Items: map[string]models.Event{
+		"due":   {ID: "due", Status: models.StatusScheduled, PublishAt: &past, Version: 1},
+		"later": {ID: "later", Status: models.StatusScheduled, PublishAt: &future, Version: 1},
+		"draft": {ID: "draft", Status: models.StatusDraft, Version: 1},
+	}}
+
+	rv := &mocks.MockRevisionRepo{}
+	var notified []string
+	evs, err := jobs.NewScheduledPublisher(er, rv, time.Minute, func(e models.Event) {
+		notified = append(notified, e.ID)
+	}).RunOnce()
+	if err != nil { t.Fatalf("run: %v", err) }
+	if len(evs) != 1 || evs[0].ID != "due" || evs[0].Version != 2 || len(notified) != 1 {
+		t.Fatalf("want only 'due' published, got %+v notified=%v", evs, notified)
+	}
+	if got := er.Items["due"]; got.Status != models.StatusPublished || got.PublishAt != nil || got.Version != 2 {
+		t.Fatalf("unexpected due event: %+v", got)
+	}
+	if er.Items["later"].Status != models.StatusScheduled {
+		t.Fatalf("future event must stay scheduled")
+	}
+
+	// 歷程：rev 2、操作者 0（系統），status / publishAt 的 diff
+	revs, _ := rv.List("due")
+	if len(revs) != 1 || revs[0].Rev != 2 || revs[0].UserID != 0 || revs[0].Action != models.RevisionUpdate {
+		t.Fatalf("want one system update revision at rev 2, got %+v", revs)
+	}
+	if ch := revs[0].Changes; ch["status"].To != models.StatusPublished || ch["publishAt"].From == nil || len(ch) != 2 {
+		t.Fatalf("unexpected changes: %+v", ch)
+	}
+}
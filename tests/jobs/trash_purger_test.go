@@ -0,0 +1,28 @@
+// 測試目的：TrashPurger 只清掉超過保留期限的軟刪除事件
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"restapi/jobs"
+	"restapi/models"
+	"restapi/tests/mocks"
+)
+
+func TestTrashPurger_RunOnce(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	er := &mocks.MockEventRepo{Items: map[string]models.Event{
+		"old":    {ID: "old", DeletedAt: &old},
+		"recent": {ID: "recent", DeletedAt: &recent},
+		"live":   {ID: "live"},
+	}}
+
+	n, err := jobs.NewTrashPurger(er, 24*time.Hour, time.Minute).RunOnce()
+	if err != nil { t.Fatalf("purge: %v", err) }
+	if n != 1 { t.Fatalf("want 1 purged, got %d", n) }
+	if _, ok := er.Items["old"]; ok { t.Fatalf("old trashed event should be purged") }
+	if _, ok := er.Items["recent"]; !ok { t.Fatalf("recent trashed event should be kept") }
+	if _, ok := er.Items["live"]; !ok { t.Fatalf("live event must never be purged") }
+}
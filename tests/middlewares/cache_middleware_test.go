@@ -49,3 +49,142 @@ func TestResponseCache_MissThenHit(t *testing.T) {
 		t.Fatalf("want HIT, got %q", w2.Header().Get("X-Cache"))
 	}
 }
+
+//帶 Authorization 的 GET 不可寫入共用快取（回應因人而異）
+func TestResponseCache_SkipsAuthorizedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { mr.Close() })
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	s := gin.New()
+	s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
+	s.GET("/events", func(c *gin.Context) { c.JSON(200, gin.H{"ok": 1}) })
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/events", nil)
+		req.Header.Set("Authorization", "some-token")
+		s.ServeHTTP(w, req)
+		if got := w.Header().Get("X-Cache"); got != "" {
+			t.Fatalf("authorized request should bypass cache, got X-Cache=%q", got)
+		}
+	}
+	if len(mr.Keys()) != 0 {
+		t.Fatalf("nothing should be cached, got %v", mr.Keys())
+	}
+}
+
+//handler 標 Cache-Control: private, no-store（private 事件）→ 不寫入快取；帶分享 token 的請求不查也不存
+func TestResponseCache_SkipsPrivateResponsesAndShareTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { mr.Close() })
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	s := gin.New()
+	s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
+	s.GET("/events/:id", func(c *gin.Context) {
+		if c.Param("id") == "secret" {
+			c.Header("Cache-Control", "private, no-store")
+		}
+		c.JSON(200, gin.H{"id": c.Param("id")})
+	})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", "/events/secret", nil))
+		if w.Header().Get("X-Cache") != "" {
+			t.Fatalf("private response must not be cached, got X-Cache=%q", w.Header().Get("X-Cache"))
+		}
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Fatalf("nothing should be stored, got %v", keys)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/events/pub?share=tok", nil))
+	if w.Header().Get("X-Cache") != "" || len(mr.Keys()) != 0 {
+		t.Fatalf("share-token request must bypass cache")
+	}
+}
+
+//GET /events/:id?occurrence= 跟不帶 query 的是不同內容（RSVP 統計），不能互相命中
+func TestResponseCache_ItemKeyIncludesQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { mr.Close() })
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	s := gin.New()
+	s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
+	s.GET("/events/:id", func(c *gin.Context) { c.JSON(200, gin.H{"occurrence": c.Query("occurrence")}) })
+
+	for _, tc := range []struct{ url, want string }{
+		{"/events/ev", "MISS"},
+		{"/events/ev?occurrence=20300107T100000Z", "MISS"},
+		{"/events/ev?occurrence=20300107T100000Z", "HIT"},
+	} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", tc.url, nil))
+		if got := w.Header().Get("X-Cache"); got != tc.want {
+			t.Fatalf("%s: want %s, got %q", tc.url, tc.want, got)
+		}
+	}
+	if n := len(mr.Keys()); n != 2 {
+		t.Fatalf("want 2 item keys, got %v", mr.Keys())
+	}
+}
+
+// HIT：只回快取的 body（handler 不再執行）；X-Request-ID 是這次請求的，不是被快取那次的
+func TestResponseCache_HitKeepsRequestIDAndSkipsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { mr.Close() })
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	calls := 0
+	s := gin.New()
+	s.Use(middlewares.RequestID())
+	s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
+	s.GET("/events", func(c *gin.Context) { calls++; c.JSON(200, gin.H{"ok": 1}) })
+
+	for _, id := range []string{"first", "second"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/events", nil)
+		req.Header.Set(middlewares.RequestIDHeader, id)
+		s.ServeHTTP(w, req)
+		if got := w.Header().Values(middlewares.RequestIDHeader); len(got) != 1 || got[0] != id {
+			t.Fatalf("X-Request-ID = %v, want [%s]", got, id)
+		}
+		if w.Body.String() != `{"ok":1}` {
+			t.Fatalf("body = %q", w.Body.String())
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1 (second request is a HIT)", calls)
+	}
+}
+
+// 個人行事曆訂閱：就算 handler 忘了設 Cache-Control 也不能存（key 不含 token，會把別人的行事曆回給下一個人）
+func TestResponseCache_SkipsCalendarFeeds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { mr.Close() })
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	s := gin.New()
+	s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
+	s.GET("/calendar/:token", func(c *gin.Context) { c.String(200, c.Param("token")) })
+
+	for _, token := range []string{"alice.ics", "bob.ics"} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", "/calendar/"+token, nil))
+		if w.Header().Get("X-Cache") != "" || w.Body.String() != token {
+			t.Fatalf("%s: X-Cache=%q body=%q", token, w.Header().Get("X-Cache"), w.Body.String())
+		}
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Fatalf("nothing should be stored, got %v", keys)
+	}
+}
@@ -63,11 +63,11 @@ func TestCache_MissHitThenInvalidatedByCreate(t *testing.T) {
 	}
 
 	// 3) 建立事件 → 路由內會呼叫 CacheInvalidator 清掉列表快取
-	token, err := utils.GenerateToken("u@x.com", 1)
+	token, err := utils.GenerateToken("u@x.com", 1, 1)
 	if err != nil {
 		t.Fatalf("gen token: %v", err)
 	}
-	body := `{"name":"N","description":"D","location":"L","dateTime":"2025-01-01T00:00:00Z"}`
+	body := `{"name":"N","description":"D","location":"L","dateTime":"2030-01-01T00:00:00Z"}`
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
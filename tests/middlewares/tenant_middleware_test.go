@@ -0,0 +1,103 @@
+// 測試目的：Tenant 中介層決定請求的組織
+// 1) 子網域 → X-Tenant header → token 的 orgId → 預設組織
+// 2) 不存在的組織 404；token 與指定的組織不同 403
+// 3) 回應快取的 key 依組織分開
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/middlewares"
+	"restapi/models"
+	"restapi/tests/mocks"
+	"restapi/utils"
+)
+
+func tenantServer() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	orgs := &mocks.MockOrgRepo{Orgs: map[int64]models.Organization{
+		1: {ID: 1, Slug: "default"},
+		2: {ID: 2, Slug: "acme"},
+	}}
+	s := gin.New()
+	s.Use(middlewares.Tenant(middlewares.TenantConfig{Orgs: orgs, BaseDomain: "events.test"}))
+	s.GET("/org", func(c *gin.Context) {
+		key, _ := middlewares.CacheKeyFrom(c)
+		c.JSON(http.StatusOK, gin.H{"orgId": c.GetInt64("orgId"), "key": key})
+	})
+	return s
+}
+
+func TestTenant_Resolution(t *testing.T) {
+	s := tenantServer()
+	acmeTok, _ := utils.GenerateToken("a@x.com", 1, 2)
+	legacyTok, _ := utils.GenerateToken("a@x.com", 1, 0)
+
+	cases := []struct {
+		name, host, header, token string
+		wantCode                  int
+		wantOrg                   float64
+	}{
+		{"default", "localhost:8080", "", "", http.StatusOK, 1},
+		{"subdomain", "acme.events.test", "", "", http.StatusOK, 2},
+		{"subdomain with port", "ACME.events.test:8080", "", "", http.StatusOK, 2},
+		{"header", "localhost", "acme", "", http.StatusOK, 2},
+		{"token claim", "localhost", "", acmeTok, http.StatusOK, 2},
+		{"token matches header", "localhost", "acme", acmeTok, http.StatusOK, 2},
+		{"unknown org", "nope.events.test", "", "", http.StatusNotFound, 0},
+		{"token for other org", "localhost", "default", acmeTok, http.StatusForbidden, 0},
+		{"legacy token is default org", "acme.events.test", "", legacyTok, http.StatusForbidden, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/org", nil)
+			req.Host = tc.host
+			if tc.header != "" {
+				req.Header.Set(middlewares.TenantHeader, tc.header)
+			}
+			if tc.token != "" {
+				req.Header.Set("Authorization", tc.token)
+			}
+			s.ServeHTTP(w, req)
+			if w.Code != tc.wantCode {
+				t.Fatalf("code = %d, want %d (%s)", w.Code, tc.wantCode, w.Body.String())
+			}
+			if tc.wantCode == http.StatusOK {
+				var body struct{ OrgID float64 `json:"orgId"` }
+				decode(t, w, &body)
+				if body.OrgID != tc.wantOrg {
+					t.Fatalf("orgId = %v, want %v", body.OrgID, tc.wantOrg)
+				}
+			}
+		})
+	}
+}
+
+func TestTenant_CacheKeyPerOrg(t *testing.T) {
+	s := tenantServer()
+	keyFor := func(host string) string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/org", nil)
+		req.Host = host
+		s.ServeHTTP(w, req)
+		var body struct{ Key string `json:"key"` }
+		decode(t, w, &body)
+		return body.Key
+	}
+	if a, b := keyFor("acme.events.test"), keyFor("localhost"); a == "" || a == b {
+		t.Fatalf("cache keys must differ per org: %q vs %q", a, b)
+	}
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+}
@@ -1,54 +1,605 @@
 package mocks
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
 	"restapi/models"
 )
 
 type MockUserRepo struct {
 	Users map[string]models.User // key 是 email  //假db 下面做他的與db的操作 //實現介面方法
+	// Members orgId → userId → role（可與 MockOrgRepo.Members 共用同一個 map）
+	// 不屬於任何組織的使用者視為預設組織成員（對應 init.sql 的回填）
+	Members map[int64]map[int64]string
+	Org     int64
+}
+func (m *MockUserRepo) InOrg(org int64) models.UserRepository {
+	if m.Members == nil { m.Members = map[int64]map[int64]string{} }
+	return &MockUserRepo{Users: m.Users, Members: m.Members, Org: org}
+}
+func (m *MockUserRepo) visible(u models.User) bool {
+	if m.Org == 0 || u.Role == models.RoleAdmin || m.Members[m.Org][u.ID] != "" { return true }
+	if m.Org != models.DefaultOrgID { return false }
+	for _, ms := range m.Members { if ms[u.ID] != "" { return false } }
+	return true
 }
 func (m *MockUserRepo) Create(u *models.User) error {
-	if _, ok := m.Users[u.Email]; ok { return errors.New("dup") }
+	if _, ok := m.Users[u.Email]; ok { return models.ErrConflict }
 	u.ID = int64(len(m.Users) + 1)
+	if u.Role == "" { u.Role = models.RoleUser }
 	m.Users[u.Email] = *u
+	if m.Org != 0 {
+		if m.Members[m.Org] == nil { m.Members[m.Org] = map[int64]string{} }
+		m.Members[m.Org][u.ID] = models.OrgMember
+	}
 	return nil
 }
 func (m *MockUserRepo) ValidateCredentials(email, plain string) (models.User, error) {
-	u, ok := m.Users[email]; if !ok { return models.User{}, errors.New("not found") }
+	u, ok := m.Users[email]; if !ok || !m.visible(u) { return models.User{}, models.ErrInvalidCredentials }
 	// 測試先簡化：直接用明碼比對；之後可改成 utils.CheckPasswordHash
-	if u.Password != plain { return models.User{}, errors.New("bad") }
+	if u.Password != plain { return models.User{}, models.ErrInvalidCredentials }
 	return u, nil
 }
 func (m *MockUserRepo) GetByID(id int64) (models.User, error) {
-	for _, u := range m.Users { if u.ID == id { return u, nil } }
-	return models.User{}, errors.New("not found")
+	for _, u := range m.Users { if u.ID == id && m.visible(u) { return u, nil } }
+	return models.User{}, models.ErrNotFound
+}
+func (m *MockUserRepo) GetByEmail(email string) (models.User, error) {
+	for e, u := range m.Users { if strings.EqualFold(e, email) && m.visible(u) { return u, nil } }
+	return models.User{}, models.ErrNotFound
 }
 
-type MockEventRepo struct{ Items map[string]models.Event }
-func (m *MockEventRepo) GetAll() ([]models.Event, error) {
+type MockEventRepo struct{ Items map[string]models.Event; Org int64 }
+func (m *MockEventRepo) InOrg(org int64) models.EventRepository { return &MockEventRepo{Items: m.Items, Org: org} }
+// get 只回同組織的事件（跟 Mongo 的 orgScope 一樣）
+func (m *MockEventRepo) get(id string) (models.Event, bool) {
+	e, ok := m.Items[id]; return e, ok && e.InOrg(m.Org)
+}
+func (m *MockEventRepo) GetAll(f models.EventFilter) ([]models.Event, error) {
 	out := make([]models.Event, 0, len(m.Items))
-	for _, e := range m.Items { out = append(out, e) }
+	for _, e := range m.Items { if e.InOrg(m.Org) && e.DeletedAt == nil && e.IsListed() && f.Matches(e) { out = append(out, e) } }
 	return out, nil
 }
+func (m *MockEventRepo) Facets(f models.EventFilter) (models.Facets, error) {
+	f.Near, f.Box = nil, nil
+	events, _ := m.GetAll(f)
+	return models.CountFacets(events), nil
+}
 func (m *MockEventRepo) GetByID(id string) (models.Event, error) {
-	e, ok := m.Items[id]; if !ok { return models.Event{}, errors.New("nf") }
+	e, ok := m.get(id); if !ok || e.DeletedAt != nil { return models.Event{}, models.ErrNotFound }
 	return e, nil
 }
-func (m *MockEventRepo) Create(e *models.Event) error { m.Items[e.ID] = *e; return nil }
+func (m *MockEventRepo) Create(e *models.Event) error {
+	if e.Version == 0 { e.Version = 1 }
+	if m.Org != 0 { e.OrgID = m.Org }
+	m.Items[e.ID] = *e; return nil
+}
+func (m *MockEventRepo) CreateMany(es []models.Event) (int, error) {
+	for i := range es {
+		if _, dup := m.Items[es[i].ID]; dup { return i, models.ErrConflict }
+		if err := m.Create(&es[i]); err != nil { return i, err }
+	}
+	return len(es), nil
+}
+// checkVersion 模擬 Mongo 的條件寫入
+func (m *MockEventRepo) checkVersion(id string, version int64) error {
+	cur, ok := m.get(id); if !ok || cur.DeletedAt != nil { return models.ErrNotFound }
+	if cur.Version != version { return &models.VersionConflictError{Current: cur} }
+	return nil
+}
 func (m *MockEventRepo) Update(e *models.Event) error {
-	if _, ok := m.Items[e.ID]; !ok { return errors.New("nf") }
+	if err := m.checkVersion(e.ID, e.Version); err != nil { return err }
+	if e.OrgID == 0 { e.OrgID = m.Items[e.ID].OrgID }
+	e.Version++
 	m.Items[e.ID] = *e; return nil
 }
-func (m *MockEventRepo) Delete(id string) error { delete(m.Items, id); return nil }
+func (m *MockEventRepo) Patch(e *models.Event, fields []string) error {
+	if err := m.checkVersion(e.ID, e.Version); err != nil { return err }
+	cur := m.Items[e.ID]
+	// 跟 Mongo 一樣只覆蓋 fields 列出的欄位：透過 JSON map 逐欄搬
+	var dst, src map[string]json.RawMessage
+	b, _ := json.Marshal(cur); _ = json.Unmarshal(b, &dst)
+	b, _ = json.Marshal(e); _ = json.Unmarshal(b, &src)
+	for _, f := range fields { dst[f] = src[f] }
+	b, _ = json.Marshal(dst)
+	var out models.Event
+	if err := json.Unmarshal(b, &out); err != nil { return err }
+	out.Version++; e.Version = out.Version
+	m.Items[e.ID] = out; return nil
+}
+func (m *MockEventRepo) Delete(id string, version int64) error {
+	if err := m.checkVersion(id, version); err != nil { return err }
+	e := m.Items[id]; now := time.Now().UTC()
+	e.DeletedAt = &now; e.Version++
+	m.Items[id] = e; return nil
+}
+func (m *MockEventRepo) ListDeleted(ownerID int64) ([]models.Event, error) {
+	out := []models.Event{}
+	for _, e := range m.Items {
+		if e.InOrg(m.Org) && e.DeletedAt != nil && (ownerID == 0 || e.UserID == ownerID) { out = append(out, e) }
+	}
+	return out, nil
+}
+func (m *MockEventRepo) GetDeleted(id string) (models.Event, error) {
+	e, ok := m.get(id); if !ok || e.DeletedAt == nil { return models.Event{}, models.ErrNotFound }
+	return e, nil
+}
+func (m *MockEventRepo) Restore(id string, version int64) error {
+	e, err := m.GetDeleted(id); if err != nil { return err }
+	if e.Version != version { return &models.VersionConflictError{Current: e} }
+	e.DeletedAt = nil; e.Version++
+	m.Items[id] = e; return nil
+}
+func (m *MockEventRepo) PublishDue(now time.Time) ([]models.Event, error) {
+	var out []models.Event
+	for id, e := range m.Items {
+		if e.InOrg(m.Org) && e.DeletedAt == nil && e.Status == models.StatusScheduled && e.PublishAt != nil && !e.PublishAt.After(now) {
+			out = append(out, e) // 發布前的內容（同 Mongo）
+			e.Status, e.PublishAt = models.StatusPublished, nil; e.Version++
+			m.Items[id] = e
+		}
+	}
+	return out, nil
+}
+func (m *MockEventRepo) PurgeDeleted(before time.Time) (int64, error) {
+	var n int64
+	for id, e := range m.Items {
+		if e.InOrg(m.Org) && e.DeletedAt != nil && e.DeletedAt.Before(before) { delete(m.Items, id); n++ }
+	}
+	return n, nil
+}
+
+type MockRegRepo struct {
+	Pairs, Voided map[string]bool // "userId:eventId" 或 "userId:eventId@occurrence"
+	Orgs          map[string]int64 // 報名所屬組織；沒記錄 → 預設組織
+	Rows          map[string]*models.Registration // id、報到等其他欄位（Register 時建立；直接塞 Pairs 的沒有）
+	Transfers     map[int64]*models.Transfer
+	Users         *MockUserRepo // StreamAttendees 查 email 用；nil → email 空白
+	Org           int64
+}
+func (m *MockRegRepo) InOrg(org int64) models.RegistrationRepository {
+	if m.Voided == nil { m.Voided = map[string]bool{} }
+	if m.Orgs == nil { m.Orgs = map[string]int64{} }
+	if m.Rows == nil { m.Rows = map[string]*models.Registration{} }
+	if m.Transfers == nil { m.Transfers = map[int64]*models.Transfer{} }
+	return &MockRegRepo{Pairs: m.Pairs, Voided: m.Voided, Orgs: m.Orgs, Rows: m.Rows, Transfers: m.Transfers, Users: m.Users, Org: org}
+}
+func (m *MockRegRepo) inOrg(k string) bool { return m.Org == 0 || models.OrgOf(m.Orgs[k]) == m.Org }
+func (m *MockRegRepo) Register(reg *models.Registration, capacity int) error {
+	k := key(reg.UserID, reg.EventID, reg.Occurrence); if m.Pairs[k] { return models.ErrConflict }
+	if reg.RSVP == "" { reg.RSVP = models.RSVPGoing }
+	if err := m.checkSeats(*reg, capacity); err != nil { return err }
+	if m.Rows == nil { m.Rows = map[string]*models.Registration{} }
+	var next int64
+	for _, r := range m.Rows { next = max(next, r.ID) }
+	m.Pairs[k] = true
+	reg.ID, reg.Status, reg.CreatedAt = next+1, models.RegistrationActive, time.Now().UTC()
+	reg.RespondedAt = reg.CreatedAt
+	row := *reg; m.Rows[k] = &row
+	if m.Org != 0 { m.Orgs[k] = m.Org }
+	return nil
+}
+func (m *MockRegRepo) UpdateRSVP(reg *models.Registration, capacity int) error {
+	k := key(reg.UserID, reg.EventID, reg.Occurrence); if !m.Pairs[k] || !m.inOrg(k) { return models.ErrNotFound }
+	if err := m.checkSeats(*reg, capacity); err != nil { return err }
+	row := m.Rows[k]
+	if row == nil { row = &models.Registration{}; m.Rows[k] = row }
+	row.RSVP, row.Guests, row.RespondedAt = reg.RSVP, reg.Guests, time.Now().UTC()
+	for _, r := range m.all() { if key(r.UserID, r.EventID, r.Occurrence) == k { *reg = r } }
+	return nil
+}
+// checkSeats 同一場其他人（going）已佔的名額 + 這筆 > capacity → ErrConflict
+func (m *MockRegRepo) checkSeats(reg models.Registration, capacity int) error {
+	if reg.RSVP != models.RSVPGoing { return nil }
+	return m.reserveSeats(reg.EventID, reg.Occurrence, reg.UserID, 1+reg.Guests, capacity)
+}
+func (m *MockRegRepo) reserveSeats(eid, occ string, exclude int64, seats, capacity int) error {
+	if capacity <= 0 { return nil }
+	taken := 0
+	for _, r := range m.all() {
+		if r.EventID == eid && r.Occurrence == occ && r.UserID != exclude { taken += r.Seats() }
+	}
+	for _, t := range m.Transfers { if m.heldFor(t, eid) && t.Occurrence == occ { taken++ } }
+	if taken+seats > capacity { return fmt.Errorf("%w: event is full", models.ErrConflict) }
+	return nil
+}
+func (m *MockRegRepo) heldFor(t *models.Transfer, eid string) bool {
+	return t.EventID == eid && t.Kind == models.TransferGroupSeat && t.Status == models.TransferPending
+}
+func (m *MockRegRepo) RSVPCounts(eid string) (map[string]models.RSVPCounts, error) {
+	out := map[string]models.RSVPCounts{}
+	for _, r := range m.all() {
+		if r.EventID != eid || r.Status != models.RegistrationActive { continue }
+		n := out[r.Occurrence]; n.Count(r); out[r.Occurrence] = n
+	}
+	for _, t := range m.Transfers {
+		if m.heldFor(t, eid) && m.transferIn(t) { n := out[t.Occurrence]; n.Held++; out[t.Occurrence] = n }
+	}
+	return out, nil
+}
+func (m *MockRegRepo) transferIn(t *models.Transfer) bool { return m.Org == 0 || models.OrgOf(t.OrgID) == m.Org }
+func (m *MockRegRepo) OfferTransfer(t *models.Transfer) error {
+	if m.Transfers == nil { m.Transfers = map[int64]*models.Transfer{} }
+	for _, x := range m.Transfers {
+		if x.Status != models.TransferPending || x.Kind != t.Kind { continue }
+		if t.Kind == models.TransferRegistration && x.RegistrationID == t.RegistrationID { return models.ErrConflict }
+		if t.Kind == models.TransferGroupSeat && x.EventID == t.EventID && x.Occurrence == t.Occurrence && x.Email == t.Email { return models.ErrConflict }
+	}
+	t.ID, t.OrgID, t.Status, t.CreatedAt = int64(len(m.Transfers)+1), models.OrgOf(m.Org), models.TransferPending, time.Now().UTC()
+	row := *t; m.Transfers[t.ID] = &row
+	return nil
+}
+func (m *MockRegRepo) GetTransfer(id int64) (models.Transfer, error) {
+	t := m.Transfers[id]; if t == nil || !m.transferIn(t) { return models.Transfer{}, models.ErrNotFound }
+	return *t, nil
+}
+func (m *MockRegRepo) PendingTransfers(email string) ([]models.Transfer, error) {
+	out := []models.Transfer{}
+	for _, t := range m.Transfers { if t.Email == email && t.Status == models.TransferPending && m.transferIn(t) { out = append(out, *t) } }
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out, nil
+}
+// AcceptTransfer 同 SQL 版；已付款訂單的轉移只在 SQL 版（mock 的報名不知道訂單）
+func (m *MockRegRepo) AcceptTransfer(id, uid int64, answers models.Answers) (models.Registration, error) {
+	t, err := m.GetTransfer(id); if err != nil { return models.Registration{}, err }
+	if t.Status != models.TransferPending { return models.Registration{}, models.ErrConflict }
+	if m.Pairs[key(uid, t.EventID, t.Occurrence)] { return models.Registration{}, models.ErrConflict }
+	reg := models.Registration{UserID: uid, EventID: t.EventID, Occurrence: t.Occurrence, Answers: answers}
+	switch t.Kind {
+	case models.TransferRegistration:
+		from, err := m.Get(t.RegistrationID)
+		if err != nil || from.UserID != t.FromUserID || from.Status != models.RegistrationActive || from.CheckedInAt != nil { return models.Registration{}, models.ErrConflict }
+		k := key(from.UserID, from.EventID, from.Occurrence)
+		delete(m.Pairs, k); delete(m.Rows, k)
+		from.UserID, from.Answers, from.RespondedAt = uid, answers, time.Now().UTC()
+		nk := key(uid, from.EventID, from.Occurrence)
+		m.Pairs[nk], m.Rows[nk], reg = true, &from, from
+		if o, ok := m.Orgs[k]; ok { m.Orgs[nk] = o }
+	case models.TransferGroupSeat:
+		if err := m.Register(&reg, 0); err != nil { return models.Registration{}, err }
+	}
+	now := time.Now().UTC()
+	m.Transfers[id].Status, m.Transfers[id].ResolvedAt = models.TransferAccepted, &now
+	return reg, nil
+}
+func (m *MockRegRepo) ResolveTransfer(id int64, status string) error {
+	t, err := m.GetTransfer(id); if err != nil { return err }
+	if t.Status != models.TransferPending { return models.ErrConflict }
+	now := time.Now().UTC()
+	m.Transfers[id].Status, m.Transfers[id].ResolvedAt = status, &now
+	return nil
+}
+// RegisterGroup 先全部檢查再寫入，模擬交易的全有全無
+func (m *MockRegRepo) RegisterGroup(regs []models.Registration, holds []models.Transfer, capacity int) error {
+	seats := len(holds)
+	for _, r := range regs { seats += 1 + r.Guests }
+	if seats == 0 { return nil }
+	eid, occ := "", ""
+	if len(regs) > 0 { eid, occ = regs[0].EventID, regs[0].Occurrence } else { eid, occ = holds[0].EventID, holds[0].Occurrence }
+	if err := m.reserveSeats(eid, occ, 0, seats, capacity); err != nil { return err }
+	for _, r := range regs { if m.Pairs[key(r.UserID, r.EventID, r.Occurrence)] { return fmt.Errorf("%w: user %d is already registered", models.ErrConflict, r.UserID) } }
+	for _, h := range holds {
+		for _, x := range m.Transfers { if m.heldFor(x, h.EventID) && x.Occurrence == h.Occurrence && x.Email == h.Email { return models.ErrConflict } }
+	}
+	for i := range regs { if err := m.Register(&regs[i], 0); err != nil { return err } }
+	for i := range holds { if err := m.OfferTransfer(&holds[i]); err != nil { return err } }
+	return nil
+}
+func (m *MockRegRepo) Cancel(uid int64, eid, occ string) error {
+	k := key(uid, eid, occ); if !m.Pairs[k] || !m.inOrg(k) { return models.ErrNotFound }
+	delete(m.Pairs, k); return nil // Rows 留著，讓之後的 id 不會重複（同 BIGSERIAL）
+}
+func (m *MockRegRepo) VoidByEvent(eid string) (int64, error) {
+	if m.Voided == nil { m.Voided = map[string]bool{} }
+	var n int64
+	for k := range m.Pairs {
+		if !m.inOrg(k) { continue }
+		if strings.HasSuffix(k, ":"+eid) || strings.Contains(k, ":"+eid+"@") { delete(m.Pairs, k); m.Voided[k] = true; n++ }
+	}
+	return n, nil
+}
+func (m *MockRegRepo) VoidByOccurrence(eid, occ string) (int64, error) {
+	if m.Voided == nil { m.Voided = map[string]bool{} }
+	var n int64
+	for k := range m.Pairs {
+		if !m.inOrg(k) { continue }
+		if strings.HasSuffix(k, ":"+eid+"@"+occ) { delete(m.Pairs, k); m.Voided[k] = true; n++ }
+	}
+	return n, nil
+}
+// all 目前組織看得到的報名（Pairs + Voided），不排序
+func (m *MockRegRepo) all() []models.Registration {
+	out := []models.Registration{}
+	add := func(pairs map[string]bool, status string) {
+		for k := range pairs {
+			if !m.inOrg(k) { continue }
+			var uid int64; var rest string
+			if _, err := fmt.Sscanf(k, "%d:%s", &uid, &rest); err != nil { continue }
+			e, occ, _ := strings.Cut(rest, "@")
+			reg := models.Registration{}
+			if row := m.Rows[k]; row != nil { reg = *row }
+			reg.UserID, reg.EventID, reg.Occurrence, reg.Status = uid, e, occ, status
+			if reg.RSVP == "" { reg.RSVP = models.RSVPGoing } // 直接塞 Pairs 的視為 going（同欄位預設值）
+			out = append(out, reg)
+		}
+	}
+	add(m.Pairs, models.RegistrationActive)
+	add(m.Voided, models.RegistrationVoided)
+	return out
+}
+func (m *MockRegRepo) ListByEvent(eid string) ([]models.Registration, error) {
+	out := []models.Registration{}
+	for _, r := range m.all() { if r.EventID == eid { out = append(out, r) } }
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Occurrence != out[j].Occurrence { return out[i].Occurrence < out[j].Occurrence }
+		return out[i].UserID < out[j].UserID
+	})
+	return out, nil
+}
+func (m *MockRegRepo) StreamAttendees(eid, occ string, fn func(models.Attendee) error) error {
+	regs, _ := m.ListByEvent(eid)
+	for _, r := range regs {
+		if occ != "" && r.Occurrence != occ { continue }
+		a := models.Attendee{Registration: r}
+		if m.Users != nil { for _, u := range m.Users.Users { if u.ID == r.UserID { a.Email = u.Email } } }
+		if err := fn(a); err != nil { return err }
+	}
+	return nil
+}
+func (m *MockRegRepo) ListByUser(uid int64) ([]models.Registration, error) {
+	out := []models.Registration{}
+	for _, r := range m.all() { if r.UserID == uid { out = append(out, r) } }
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out, nil
+}
+func (m *MockRegRepo) Get(id int64) (models.Registration, error) {
+	for _, r := range m.all() { if id != 0 && r.ID == id { return r, nil } }
+	return models.Registration{}, models.ErrNotFound
+}
+func (m *MockRegRepo) CheckIn(id, by int64, at time.Time) error {
+	r, err := m.Get(id); if err != nil { return err }
+	row := m.Rows[key(r.UserID, r.EventID, r.Occurrence)]
+	if r.Status != models.RegistrationActive || row.CheckedInAt != nil { return models.ErrConflict }
+	t := at.UTC(); row.CheckedInAt, row.CheckedInBy = &t, by
+	return nil
+}
+func key(uid int64, eid, occ string) string {
+	if occ == "" { return fmt.Sprintf("%d:%s", uid, eid) }
+	return fmt.Sprintf("%d:%s@%s", uid, eid, occ)
+}
+
+// Items 是 slice，InOrg 回傳的 view 透過 root 寫回同一份
+type MockRevisionRepo struct{ Items []models.Revision; org int64; root *MockRevisionRepo }
+func (m *MockRevisionRepo) InOrg(org int64) models.RevisionRepository {
+	return &MockRevisionRepo{org: org, root: m.base()}
+}
+func (m *MockRevisionRepo) base() *MockRevisionRepo { if m.root != nil { return m.root }; return m }
+func (m *MockRevisionRepo) in(r models.Revision) bool { return m.org == 0 || models.OrgOf(r.OrgID) == m.org }
+func (m *MockRevisionRepo) Append(r *models.Revision) error {
+	b := m.base()
+	for _, x := range b.Items { if x.EventID == r.EventID && x.Rev == r.Rev { return models.ErrConflict } }
+	if m.org != 0 { r.OrgID = m.org }
+	b.Items = append(b.Items, *r); return nil
+}
+func (m *MockRevisionRepo) List(eventID string) ([]models.Revision, error) {
+	out := []models.Revision{}
+	for _, x := range m.base().Items { if x.EventID == eventID && m.in(x) { x.Snapshot = nil; out = append(out, x) } }
+	return out, nil
+}
+func (m *MockRevisionRepo) Get(eventID string, rev int64) (models.Revision, error) {
+	for _, x := range m.base().Items { if x.EventID == eventID && x.Rev == rev && m.in(x) { return x, nil } }
+	return models.Revision{}, models.ErrNotFound
+}
+
+type MockInvitationRepo struct{ Items []models.Invitation; org int64; root *MockInvitationRepo }
+func (m *MockInvitationRepo) InOrg(org int64) models.InvitationRepository {
+	return &MockInvitationRepo{org: org, root: m.base()}
+}
+func (m *MockInvitationRepo) base() *MockInvitationRepo { if m.root != nil { return m.root }; return m }
+func (m *MockInvitationRepo) in(x models.Invitation) bool { return m.org == 0 || models.OrgOf(x.OrgID) == m.org }
+func (m *MockInvitationRepo) Create(inv *models.Invitation) error {
+	b := m.base()
+	for _, x := range b.Items { if x.EventID == inv.EventID && x.Email == inv.Email { return models.ErrConflict } }
+	inv.ID = int64(len(b.Items) + 1); inv.CreatedAt = time.Now().UTC(); inv.OrgID = models.OrgOf(m.org)
+	b.Items = append(b.Items, *inv); return nil
+}
+func (m *MockInvitationRepo) List(eventID string) ([]models.Invitation, error) {
+	out := []models.Invitation{}
+	for _, x := range m.base().Items { if x.EventID == eventID && m.in(x) { out = append(out, x) } }
+	return out, nil
+}
+func (m *MockInvitationRepo) Delete(eventID string, id int64) error {
+	b := m.base()
+	for i, x := range b.Items {
+		if x.EventID == eventID && x.ID == id && m.in(x) { b.Items = append(b.Items[:i], b.Items[i+1:]...); return nil }
+	}
+	return models.ErrNotFound
+}
+func (m *MockInvitationRepo) IsInvited(eventID, email string) (bool, error) {
+	for _, x := range m.base().Items { if x.EventID == eventID && x.Email == email && m.in(x) { return true, nil } }
+	return false, nil
+}
+
+type MockOrgRepo struct {
+	Orgs    map[int64]models.Organization
+	Members map[int64]map[int64]string // orgId → userId → role
+}
+func (m *MockOrgRepo) Create(o *models.Organization) error {
+	for _, x := range m.Orgs { if x.Slug == o.Slug { return models.ErrConflict } }
+	o.ID = int64(len(m.Orgs) + 1)
+	for m.Orgs[o.ID].ID != 0 { o.ID++ }
+	o.CreatedAt = time.Now().UTC()
+	m.Orgs[o.ID] = *o; return nil
+}
+func (m *MockOrgRepo) GetByID(id int64) (models.Organization, error) {
+	o, ok := m.Orgs[id]; if !ok { return models.Organization{}, models.ErrNotFound }
+	return o, nil
+}
+func (m *MockOrgRepo) GetBySlug(slug string) (models.Organization, error) {
+	for _, o := range m.Orgs { if o.Slug == slug { return o, nil } }
+	return models.Organization{}, models.ErrNotFound
+}
+func (m *MockOrgRepo) AddMember(ms *models.OrgMembership) error {
+	if m.Members[ms.OrgID][ms.UserID] != "" { return models.ErrConflict }
+	if m.Members[ms.OrgID] == nil { m.Members[ms.OrgID] = map[int64]string{} }
+	ms.CreatedAt = time.Now().UTC()
+	m.Members[ms.OrgID][ms.UserID] = ms.Role; return nil
+}
+func (m *MockOrgRepo) RemoveMember(orgID, userID int64) error {
+	if m.Members[orgID][userID] == "" { return models.ErrNotFound }
+	delete(m.Members[orgID], userID); return nil
+}
+func (m *MockOrgRepo) ListMembers(orgID int64) ([]models.OrgMembership, error) {
+	out := []models.OrgMembership{}
+	for uid, role := range m.Members[orgID] { out = append(out, models.OrgMembership{OrgID: orgID, UserID: uid, Role: role}) }
+	sort.Slice(out, func(i, j int) bool { return out[i].UserID < out[j].UserID })
+	return out, nil
+}
+func (m *MockOrgRepo) RoleOf(orgID, userID int64) (string, error) { return m.Members[orgID][userID], nil }
 
-type MockRegRepo struct{ Pairs map[string]bool } // "userId:eventId"
-func (m *MockRegRepo) Register(uid int64, eid string) error {
-	k := key(uid, eid); if m.Pairs[k] { return errors.New("dup") }
-	m.Pairs[k] = true; return nil
+// Items 是 slice，InOrg 回傳的 view 透過 root 寫回同一份；Webhooks 記錄已處理的 "provider:eventId"
+type MockOrderRepo struct{ Items []models.Order; Webhooks map[string]bool; org int64; root *MockOrderRepo }
+func (m *MockOrderRepo) InOrg(org int64) models.OrderRepository { return &MockOrderRepo{org: org, root: m.base()} }
+func (m *MockOrderRepo) base() *MockOrderRepo { if m.root != nil { return m.root }; return m }
+func (m *MockOrderRepo) in(o models.Order) bool { return m.org == 0 || models.OrgOf(o.OrgID) == m.org }
+func (m *MockOrderRepo) find(match func(models.Order) bool) (*models.Order, error) {
+	b := m.base()
+	for i := range b.Items { if m.in(b.Items[i]) && match(b.Items[i]) { return &b.Items[i], nil } }
+	return nil, models.ErrNotFound
+}
+func (m *MockOrderRepo) Create(o *models.Order, capacity int) error {
+	b, now, sold := m.base(), time.Now(), 0
+	for _, x := range b.Items {
+		if x.EventID != o.EventID || x.Occurrence != o.Occurrence || !x.IsOpen(now) { continue }
+		if x.UserID == o.UserID { return models.ErrConflict }
+		if x.TicketTypeID == o.TicketTypeID { sold++ }
+	}
+	if capacity > 0 && sold >= capacity { return models.ErrConflict }
+	if o.Status == "" { o.Status = models.OrderPending }
+	o.ID, o.OrgID, o.CreatedAt, o.UpdatedAt = int64(len(b.Items)+1), models.OrgOf(m.org), now.UTC(), now.UTC()
+	b.Items = append(b.Items, *o); return nil
+}
+func (m *MockOrderRepo) Get(id int64) (models.Order, error) {
+	o, err := m.find(func(x models.Order) bool { return x.ID == id }); if err != nil { return models.Order{}, err }
+	return *o, nil
+}
+func (m *MockOrderRepo) GetByPayment(provider, paymentID string) (models.Order, error) {
+	o, err := m.find(func(x models.Order) bool { return x.Provider == provider && x.PaymentID == paymentID }); if err != nil { return models.Order{}, err }
+	return *o, nil
+}
+func (m *MockOrderRepo) FindOpen(uid int64, eid, occ string) (models.Order, error) {
+	o, err := m.find(func(x models.Order) bool {
+		return x.UserID == uid && x.EventID == eid && x.Occurrence == occ && (x.Status == models.OrderPending || x.Status == models.OrderPaid)
+	})
+	if err != nil { return models.Order{}, err }
+	return *o, nil
+}
+func (m *MockOrderRepo) SetPayment(id int64, paymentID string) error {
+	o, err := m.find(func(x models.Order) bool { return x.ID == id }); if err != nil { return err }
+	o.PaymentID = paymentID; return nil
+}
+func (m *MockOrderRepo) Transition(id int64, to string, from ...string) error {
+	o, err := m.find(func(x models.Order) bool { return x.ID == id }); if err != nil { return err }
+	for _, f := range from { if o.Status == f { o.Status, o.UpdatedAt = to, time.Now().UTC(); return nil } }
+	return models.ErrConflict
+}
+func (m *MockOrderRepo) ListByEvent(eid, occ, status string) ([]models.Order, error) {
+	var out []models.Order
+	for _, x := range m.base().Items { if m.in(x) && x.EventID == eid && (occ == "" || x.Occurrence == occ) && x.Status == status { out = append(out, x) } }
+	return out, nil
+}
+func (m *MockOrderRepo) Sold(eid, occ string) (map[string]int, error) {
+	out, now := map[string]int{}, time.Now()
+	for _, x := range m.base().Items { if m.in(x) && x.EventID == eid && x.Occurrence == occ && x.IsOpen(now) { out[x.TicketTypeID]++ } }
+	return out, nil
+}
+func (m *MockOrderRepo) RecordWebhook(provider, eventID string) (bool, error) {
+	b := m.base(); if b.Webhooks == nil { b.Webhooks = map[string]bool{} }
+	k := provider + ":" + eventID; if b.Webhooks[k] { return false, nil }
+	b.Webhooks[k] = true; return true, nil
+}
+func (m *MockOrderRepo) ForgetWebhook(provider, eventID string) error { delete(m.base().Webhooks, provider+":"+eventID); return nil }
+
+// Used：orderId → 使用紀錄；Redemptions 由 Used 即時計算
+type MockPromoRepo struct{ Items []models.PromoCode; Used map[int64][2]int64; org int64; root *MockPromoRepo }
+func (m *MockPromoRepo) InOrg(org int64) models.PromoRepository { return &MockPromoRepo{org: org, root: m.base()} }
+func (m *MockPromoRepo) base() *MockPromoRepo {
+	if m.root != nil { return m.root }
+	if m.Used == nil { m.Used = map[int64][2]int64{} }
+	return m
+}
+func (m *MockPromoRepo) in(p models.PromoCode) bool { return m.org == 0 || models.OrgOf(p.OrgID) == m.org }
+func (m *MockPromoRepo) withCount(p models.PromoCode) models.PromoCode {
+	p.Redemptions = 0
+	for _, u := range m.base().Used { if u[0] == p.ID { p.Redemptions++ } }
+	return p
+}
+func (m *MockPromoRepo) Create(p *models.PromoCode) error {
+	b := m.base()
+	for _, x := range b.Items { if x.Code == p.Code && models.OrgOf(x.OrgID) == models.OrgOf(m.org) { return models.ErrConflict } }
+	p.ID, p.OrgID, p.Active, p.CreatedAt = int64(len(b.Items)+1), models.OrgOf(m.org), true, time.Now().UTC()
+	b.Items = append(b.Items, *p); return nil
+}
+func (m *MockPromoRepo) Get(id int64) (models.PromoCode, error) {
+	for _, x := range m.base().Items { if x.ID == id && m.in(x) { return m.withCount(x), nil } }
+	return models.PromoCode{}, models.ErrNotFound
+}
+func (m *MockPromoRepo) GetByCode(code string) (models.PromoCode, error) {
+	for _, x := range m.base().Items {
+		if x.Code == models.NormalizePromoCode(code) && models.OrgOf(x.OrgID) == models.OrgOf(m.org) { return m.withCount(x), nil }
+	}
+	return models.PromoCode{}, models.ErrNotFound
+}
+func (m *MockPromoRepo) List(eventID string) ([]models.PromoCode, error) {
+	out := []models.PromoCode{}
+	for _, x := range m.base().Items {
+		if m.in(x) && (eventID == "" || len(x.EventIDs) == 0 || slices.Contains(x.EventIDs, eventID)) { out = append(out, m.withCount(x)) }
+	}
+	return out, nil
+}
+func (m *MockPromoRepo) Deactivate(id int64) error {
+	b := m.base()
+	for i := range b.Items { if b.Items[i].ID == id && m.in(b.Items[i]) { b.Items[i].Active = false; return nil } }
+	return models.ErrNotFound
+}
+func (m *MockPromoRepo) Redeem(codeID, userID, orderID int64) error {
+	p, err := m.Get(codeID); if err != nil { return err }
+	if !p.Active || (p.ExpiresAt != nil && !time.Now().Before(*p.ExpiresAt)) { return models.ErrConflict }
+	mine := 0
+	for _, u := range m.base().Used { if u[0] == codeID && u[1] == userID { mine++ } }
+	if (p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions) || (p.PerUserLimit > 0 && mine >= p.PerUserLimit) { return models.ErrConflict }
+	if _, ok := m.base().Used[orderID]; ok { return models.ErrConflict }
+	m.base().Used[orderID] = [2]int64{codeID, userID}; return nil
+}
+func (m *MockPromoRepo) Release(orderID int64) error { delete(m.base().Used, orderID); return nil }
+
+// MockCalendarFeedRepo key 是 token 雜湊；跟 SQL 一樣每個使用者在每個組織只有一個
+type MockCalendarFeedRepo struct{ Feeds map[string]models.CalendarFeed; org int64; root *MockCalendarFeedRepo }
+func (m *MockCalendarFeedRepo) InOrg(org int64) models.CalendarFeedRepository {
+	return &MockCalendarFeedRepo{org: org, root: m.base()}
+}
+func (m *MockCalendarFeedRepo) base() *MockCalendarFeedRepo {
+	if m.root != nil { return m.root }
+	if m.Feeds == nil { m.Feeds = map[string]models.CalendarFeed{} }
+	return m
+}
+func (m *MockCalendarFeedRepo) in(f models.CalendarFeed) bool { return m.org == 0 || models.OrgOf(f.OrgID) == m.org }
+func (m *MockCalendarFeedRepo) Rotate(uid int64, hash string) (models.CalendarFeed, error) {
+	f := models.CalendarFeed{UserID: uid, OrgID: models.OrgOf(m.org), CreatedAt: time.Now().UTC()}
+	for h, x := range m.base().Feeds { if x.UserID == uid && x.OrgID == f.OrgID { delete(m.base().Feeds, h) } }
+	m.base().Feeds[hash] = f; return f, nil
+}
+func (m *MockCalendarFeedRepo) Revoke(uid int64) error {
+	n := 0
+	for h, x := range m.base().Feeds { if x.UserID == uid && m.in(x) { delete(m.base().Feeds, h); n++ } }
+	if n == 0 { return models.ErrNotFound }; return nil
 }
-func (m *MockRegRepo) Cancel(uid int64, eid string) error {
-	delete(m.Pairs, key(uid, eid)); return nil
+func (m *MockCalendarFeedRepo) ByToken(hash string) (models.CalendarFeed, error) {
+	if f, ok := m.base().Feeds[hash]; ok && m.in(f) { return f, nil }
+	return models.CalendarFeed{}, models.ErrNotFound
 }
-func key(uid int64, eid string) string { return fmt.Sprintf("%d:%s", uid, eid) }
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"restapi/models"
+)
+
+// CSV：BOM 與欄位前後空白不影響欄位名稱；tags 以 ; 分隔；lat/lng 轉成 GeoJSON（[lng, lat]）
+func TestParseImport_CSV(t *testing.T) {
+	src := "\ufeffname, dateTime ,tags,lat,lng\n\"Go, Meetup\",2030-02-01T10:00:00+08:00,go;db,25.03,121.56\n"
+	rows, err := models.ParseImport(strings.NewReader(src), models.ImportCSV)
+	if err != nil || len(rows) != 1 || rows[0].Err != nil {
+		t.Fatalf("rows = %+v, err = %v", rows, err)
+	}
+	e := rows[0].Event
+	if rows[0].Line != 2 || e.Name != "Go, Meetup" || e.DateTime.UTC().Hour() != 2 || len(e.Tags) != 2 ||
+		e.Geo == nil || e.Geo.Coordinates != [2]float64{121.56, 25.03} {
+		t.Fatalf("row = %+v, geo = %+v", rows[0], e.Geo)
+	}
+
+	_, err = models.ParseImport(strings.NewReader("name,dateTime\nx\n"), models.ImportCSV)
+	if v, ok := err.(*models.ValidationError); !ok || v.Fields["csv"] == "" {
+		t.Fatalf("ragged CSV want validation error, got %v", err)
+	}
+	_, err = models.ParseImport(strings.NewReader("name,location\n"), models.ImportCSV)
+	if v, ok := err.(*models.ValidationError); !ok || v.Fields["header"] == "" {
+		t.Fatalf("missing dateTime column want validation error, got %v", err)
+	}
+}
+
+// JSON Lines：空白行略過但行號照算；壞掉的行只影響那一列
+func TestParseImport_JSONLines(t *testing.T) {
+	src := `{"name":"A","dateTime":"2030-02-01T10:00:00Z"}
+
+{"name":
+{"name":"C","dateTime":"2030-02-03T10:00:00Z"}`
+	rows, err := models.ParseImport(strings.NewReader(src), models.ImportJSONLines)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("rows = %+v, err = %v", rows, err)
+	}
+	if rows[1].Line != 3 || rows[1].Err == nil || rows[2].Line != 4 || rows[2].Event.Name != "C" {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	big := strings.Repeat(fmt.Sprintln(`{"name":"x"}`), models.MaxImportRows+1)
+	if _, err := models.ParseImport(strings.NewReader(big), models.ImportJSONLines); !errors.Is(err, models.ErrValidation) {
+		t.Fatalf("too many rows want validation error, got %v", err)
+	}
+}
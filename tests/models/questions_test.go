@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"testing"
+
+	"restapi/models"
+)
+
+// JSONB 來回：複選從 []any 轉回 []string，匯出時以 "; " 串接
+func TestAnswers_ValueScanRoundTrip(t *testing.T) {
+	in := models.Answers{"size": "M", "topics": []string{"go", "ops"}}
+	v, err := in.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out models.Answers
+	if err := out.Scan([]byte(v.(string))); err != nil {
+		t.Fatal(err)
+	}
+	if out.Text("size") != "M" || out.Text("topics") != "go; ops" || out.Text("missing") != "" {
+		t.Fatalf("round trip = %+v", out)
+	}
+	if v, _ := (models.Answers{}).Value(); v != nil {
+		t.Fatalf("empty answers should be stored as NULL, got %v", v)
+	}
+	if err := out.Scan(nil); err != nil || out != nil {
+		t.Fatalf("NULL should scan to nil, got %+v %v", out, err)
+	}
+}
+
+func TestEvent_ValidateAnswersWithoutQuestions(t *testing.T) {
+	ev := models.Event{}
+	if a, err := ev.ValidateAnswers(nil); err != nil || a != nil {
+		t.Fatalf("no questions, no answers: %+v %v", a, err)
+	}
+	if _, err := ev.ValidateAnswers(map[string]any{"x": "y"}); err == nil {
+		t.Fatalf("answers to unknown questions must be rejected")
+	}
+}
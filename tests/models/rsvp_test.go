@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"restapi/models"
+)
+
+// 只有 active 的 going 佔名額；寫法不同的回覆統一
+func TestRSVP_SeatsAndNormalize(t *testing.T) {
+	for _, tc := range []struct {
+		reg   models.Registration
+		seats int
+	}{
+		{models.Registration{Status: models.RegistrationActive, RSVP: models.RSVPGoing, Guests: 2}, 3},
+		{models.Registration{Status: models.RegistrationActive, RSVP: models.RSVPMaybe}, 0},
+		{models.Registration{Status: models.RegistrationVoided, RSVP: models.RSVPGoing, Guests: 1}, 0},
+	} {
+		if got := tc.reg.Seats(); got != tc.seats {
+			t.Fatalf("%+v: seats = %d, want %d", tc.reg, got, tc.seats)
+		}
+	}
+	for in, want := range map[string]string{"": "going", " Maybe ": "maybe", "Not Going": "not_going", "not-going": "not_going"} {
+		if got := models.NormalizeRSVP(in); got != want {
+			t.Fatalf("NormalizeRSVP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRSVP_CapacityValidation(t *testing.T) {
+	ev := models.Event{Name: "Conf", DateTime: time.Now().Add(time.Hour), Capacity: -1}
+	if v, ok := ev.Validate(time.Now()).(*models.ValidationError); !ok || v.Fields["capacity"] == "" {
+		t.Fatalf("negative capacity must fail")
+	}
+	ev.Capacity = 10
+	ev.TicketTypes = []models.TicketType{{ID: "ga", Name: "GA", Quantity: 10}}
+	if v, ok := ev.Validate(time.Now()).(*models.ValidationError); !ok || v.Fields["capacity"] == "" {
+		t.Fatalf("capacity with ticket types must fail")
+	}
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"restapi/models"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := models.SearchTerms("  Go, go!  Meetup-Taipei ")
+	want := []string{"go", "meetup", "taipei"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+//不分大小寫標記、其餘內容要 escape
+func TestHighlight_MarksAndEscapes(t *testing.T) {
+	got, ok := models.Highlight("Go <b>GO</b> gopher", []string{"go"}, 0)
+	want := "<mark>Go</mark> &lt;b&gt;<mark>GO</mark>&lt;/b&gt; <mark>go</mark>pher"
+	if !ok || got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	if _, ok := models.Highlight("nothing here", []string{"go"}, 0); ok {
+		t.Fatalf("no match should return ok=false")
+	}
+}
+
+//長文字只回命中附近的片段
+func TestHighlight_Snippet(t *testing.T) {
+	text := strings.Repeat("a", 300) + " golang " + strings.Repeat("b", 300)
+	got, ok := models.Highlight(text, []string{"golang"}, 40)
+	if !ok || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>golang</mark>") {
+		t.Fatalf("unexpected snippet %q", got)
+	}
+	if n := len([]rune(strings.NewReplacer("<mark>", "", "</mark>", "", "…", "").Replace(got))); n != 40 {
+		t.Fatalf("snippet should be 40 runes, got %d", n)
+	}
+}
@@ -11,7 +11,7 @@ func TestLogin_BadPassword_401(t *testing.T) {
 	deps := setupServerWithDeps(t)
 
 	// 先註冊
-	_ = doReq(deps.s, http.MethodPost, "/signup", `{"email":"a@b.com","password":"p"}`, "")
+	_ = doReq(deps.s, http.MethodPost, "/signup", `{"email":"a@b.com","password":"Passw0rd"}`, "")
 
 	// 再用錯密碼登入 → 401
 	w := doReq(deps.s, http.MethodPost, "/login", `{"email":"a@b.com","password":"wrong"}`, "")
@@ -13,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 
+	"restapi/middlewares"
 	"restapi/models"
 	"restapi/routes"
 	"restapi/tests/mocks"
@@ -26,9 +27,24 @@ type serverDeps struct {
 	ur *mocks.MockUserRepo
 	rr *mocks.MockRegRepo
 	er *mocks.MockEventRepo
+	rv *mocks.MockRevisionRepo
+	iv *mocks.MockInvitationRepo
+	or *mocks.MockOrderRepo
+	pp *models.LocalPaymentProvider
 }
 
-func setupServerWithDeps(t *testing.T) serverDeps {
+func setupServerWithDeps(t *testing.T, opts ...routes.Option) serverDeps {
+	t.Helper()
+	return newTestServer(t, false, opts...)
+}
+
+// setupCachedServer 跟 main.go 一樣先掛 ResponseCache
+func setupCachedServer(t *testing.T, opts ...routes.Option) serverDeps {
+	t.Helper()
+	return newTestServer(t, true, opts...)
+}
+
+func newTestServer(t *testing.T, cached bool, opts ...routes.Option) serverDeps {
 	t.Helper()
 	gin.SetMode(gin.TestMode)
 
@@ -39,18 +55,27 @@ func setupServerWithDeps(t *testing.T) serverDeps {
 	inv := utils.NewCacheInvalidator(rdb)
 
 	ur := &mocks.MockUserRepo{Users: map[string]models.User{}}   //介面 物件有實作丟進去
-	rr := &mocks.MockRegRepo{Pairs: map[string]bool{}}           //介面 物件有實作丟進去
+	rr := &mocks.MockRegRepo{Pairs: map[string]bool{}, Users: ur} //介面 物件有實作丟進去
 	er := &mocks.MockEventRepo{Items: map[string]models.Event{}} //介面 物件有實作丟進去
 
+	rv := &mocks.MockRevisionRepo{}
+	iv := &mocks.MockInvitationRepo{}
+	or := &mocks.MockOrderRepo{}
+	pp := models.NewLocalPaymentProvider("test-secret") // 付款立即成功
+
 	s := gin.New()
-	routes.RegisterRoutes(s, ur, rr, er, rdb, inv) // 會掛上 Authenticate / RateLimiter / Quota 等
-	return serverDeps{s: s, ur: ur, rr: rr, er: er}
+	if cached {
+		s.Use(middlewares.ResponseCache(rdb, 30*time.Second))
+	}
+	opts = append([]routes.Option{routes.WithRevisions(rv), routes.WithInvitations(iv), routes.WithPayments(or, pp)}, opts...)
+	routes.RegisterRoutes(s, ur, rr, er, rdb, inv, opts...) // 會掛上 Authenticate / RateLimiter / Quota 等
+	return serverDeps{s: s, ur: ur, rr: rr, er: er, rv: rv, iv: iv, or: or, pp: pp}
 }
 
 func authToken(t *testing.T, uid int64) string {
 	t.Helper()
 	// email 用不到驗證流程，只要 payload 有 userId 即可通過 middleware
-	token, err := utils.GenerateToken("tester@example.com", uid)
+	token, err := utils.GenerateToken("tester@example.com", uid, models.DefaultOrgID)
 	if err != nil {
 		t.Fatalf("gen token: %v", err)
 	}
@@ -85,7 +110,7 @@ func TestSignupAndLogin(t *testing.T) {
 	// POST /signup
 	w := httptest.NewRecorder() //模擬 HTTP Response  //使用者發送註冊請求
 	req := httptest.NewRequest(http.MethodPost, "/signup",
-		strings.NewReader(`{"email":"a@b.com","password":"p"}`)) //模擬 HTTP Request
+		strings.NewReader(`{"email":"a@b.com","password":"Passw0rd"}`)) //模擬 HTTP Request
 	req.Header.Set("Content-Type", "application/json")          //模擬傳 JSON 的請求頭
 
 	s.ServeHTTP(w, req) // 呼叫你的路由 & handler  //把這個假請求 (req) 丟進整個伺服器 (s) 處理，結果寫到假回應 (w) 裡
@@ -97,7 +122,7 @@ func TestSignupAndLogin(t *testing.T) {
 	// POST /login
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest(http.MethodPost, "/login",
-		strings.NewReader(`{"email":"a@b.com","password":"p"}`))
+		strings.NewReader(`{"email":"a@b.com","password":"Passw0rd"}`))
 	req.Header.Set("Content-Type", "application/json")
 	s.ServeHTTP(w, req)
 	if w.Code != 200 {
@@ -158,7 +183,7 @@ func TestEvents_Create_OK(t *testing.T) {
 	token := authToken(t, 1001)
 
 	// POST /events
-	body := `{"name":"GoConf","description":"fun","location":"TW","dateTime":"2025-01-01T00:00:00Z"}`
+	body := `{"name":"GoConf","description":"fun","location":"TW","dateTime":"2030-01-01T00:00:00Z"}`
 	w := doReq(deps.s, http.MethodPost, "/events", body, token)
 	if w.Code != 201 {
 		t.Fatalf("POST /events code=%d body=%s", w.Code, w.Body.String())
@@ -184,7 +209,7 @@ func TestEvents_Create_OK(t *testing.T) {
 }
 
 //成功更新同一使用者的事件 → 200；
-//其他使用者嘗試更新 → 401（未授權）。＊此段在檔內續篇，語意如上（對應路由授權檢查）。
+//其他使用者嘗試更新 → 403（非擁有者）。＊此段在檔內續篇，語意如上（對應路由授權檢查）。
 func TestEvents_Update_OK_and_Unauthorized(t *testing.T) {
 	deps := setupServerWithDeps(t)
 
@@ -203,7 +228,7 @@ func TestEvents_Update_OK_and_Unauthorized(t *testing.T) {
 	// 成功更新（同一 userId）
 	// PUT /events/:id
 	tokenOwner := authToken(t, ownerID)
-	updateBody := `{"name":"NewName","description":"new","location":"B","dateTime":"2026-01-01T00:00:00Z"}`
+	updateBody := `{"name":"NewName","description":"new","location":"B","dateTime":"2031-01-01T00:00:00Z"}`
 	w := doReq(deps.s, http.MethodPut, "/events/"+ev.ID, updateBody, tokenOwner)
 	if w.Code != 200 {
 		t.Fatalf("PUT /events/:id code=%d body=%s", w.Code, w.Body.String())
@@ -213,11 +238,11 @@ func TestEvents_Update_OK_and_Unauthorized(t *testing.T) {
 		t.Fatalf("expect name updated, got %+v", deps.er.Items[ev.ID])
 	}
 
-	// 不同 userId → 應 401（Not authorized to update event.）
-	// PUT /events/:id (unauthorized)
+	// 不同 userId → 應 403（Not authorized to update event.）
+	// PUT /events/:id (forbidden)
 	tokenOther := authToken(t, 99)
 	w = doReq(deps.s, http.MethodPut, "/events/"+ev.ID, updateBody, tokenOther)
-	if w.Code != 401 {
+	if w.Code != 403 {
 		t.Fatalf("PUT /events/:id unauthorized code=%d body=%s", w.Code, w.Body.String())
 	}
 }
@@ -244,16 +269,20 @@ func TestEvents_Delete_OK_and_Unauthorized(t *testing.T) {
 	if w.Code != 200 {
 		t.Fatalf("DELETE /events/:id code=%d body=%s", w.Code, w.Body.String())
 	}
-	if _, ok := deps.er.Items[ev.ID]; ok {
-		t.Fatalf("expect event deleted from repo")
+	// 軟刪除：資料還在，但有 deletedAt，且 GET 不到
+	if got := deps.er.Items[ev.ID]; got.DeletedAt == nil {
+		t.Fatalf("expect event soft-deleted in repo, got %+v", got)
+	}
+	if w = doReq(deps.s, http.MethodGet, "/events/"+ev.ID, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("GET deleted event want 404, got %d", w.Code)
 	}
 
-	// 重新放一筆，讓非擁有者嘗試刪除 → 401
-	// DELETE /events/:id (unauthorized)
+	// 重新放一筆，讓非擁有者嘗試刪除 → 403
+	// DELETE /events/:id (forbidden)
 	deps.er.Items[ev.ID] = ev
 	tokenOther := authToken(t, 404)
 	w = doReq(deps.s, http.MethodDelete, "/events/"+ev.ID, "", tokenOther)
-	if w.Code != 401 {
+	if w.Code != 403 {
 		t.Fatalf("DELETE /events/:id unauthorized code=%d body=%s", w.Code, w.Body.String())
 	}
 }
@@ -271,7 +300,7 @@ func TestEvents_Register_Cancel_And_Conflict(t *testing.T) {
 		Name:        "reg",
 		Description: "d",
 		Location:    "L",
-		DateTime:    time.Now().UTC(),
+		DateTime:    time.Now().UTC().Add(24 * time.Hour), // 還沒開始才能取消
 		UserID:      1,
 	}
 	deps.er.Items[ev.ID] = ev
@@ -285,7 +314,7 @@ func TestEvents_Register_Cancel_And_Conflict(t *testing.T) {
 		t.Fatalf("POST /events/:id/register code=%d body=%s", w.Code, w.Body.String())
 	}
 
-	// 重複報名 → 409（mock 會回 dup，handler 統一回 Conflict）
+	// 重複報名 → 409（mock 回 ErrConflict，statusFromError 對應成 409）
 	// POST /events/:id/register (duplicate)
 	w = doReq(deps.s, http.MethodPost, "/events/"+ev.ID+"/register", "", token)
 	if w.Code != 409 {
@@ -306,7 +335,7 @@ func TestEvents_Create_WritesIntoRepo(t *testing.T) {
 	token := authToken(t, 555)
 
 	// POST /events
-	body := `{"name":"X","description":"Y","location":"Z","dateTime":"2025-01-02T03:04:05Z"}`
+	body := `{"name":"X","description":"Y","location":"Z","dateTime":"2030-01-02T03:04:05Z"}`
 	w := doReq(deps.s, http.MethodPost, "/events", body, token)
 	if w.Code != 201 {
 		t.Fatalf("POST /events code=%d body=%s", w.Code, w.Body.String())
@@ -0,0 +1,137 @@
+// 測試目的：掛上 ResponseCache 時 /events/:id/... 的快取
+// 1) 每個事件各自一份（key 含 id），不會把 A 的結果回給 B
+// 2) 修改事件、購票後（PurgeEventItem）一起清掉
+// 3) 搜尋結果、分類統計跟列表一起清（PurgeEventsList）
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+func TestCache_OccurrencesKeyedByEvent(t *testing.T) {
+	deps := setupCachedServer(t)
+	owner := authToken(t, 5)
+	a, b := createSeries(t, deps, owner), createSeries(t, deps, owner)
+	rng := "?from=2030-01-01T00:00:00Z&to=2030-01-22T00:00:00Z"
+
+	if w := doReq(deps.s, http.MethodGet, "/events/"+a+"/occurrences"+rng, "", ""); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first want MISS, got %q", w.Header().Get("X-Cache"))
+	}
+	w := doReq(deps.s, http.MethodGet, "/events/"+b+"/occurrences"+rng, "", "")
+	if w.Header().Get("X-Cache") != "MISS" || !strings.Contains(w.Body.String(), `"seriesId":"`+b+`"`) {
+		t.Fatalf("other event must not hit A's entry: cache=%q body=%s", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/"+a+"/occurrences"+rng, "", ""); w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("repeat want HIT, got %q", w.Header().Get("X-Cache"))
+	}
+
+	// 取消一次 → PurgeEventItem 清掉，重新讀到 cancelled
+	if w := doReq(deps.s, http.MethodPost, "/events/"+a+"/occurrences/20300114T100000Z/cancel", "", owner); w.Code != http.StatusOK {
+		t.Fatalf("cancel code=%d body=%s", w.Code, w.Body.String())
+	}
+	w = doReq(deps.s, http.MethodGet, "/events/"+a+"/occurrences"+rng, "", "")
+	if w.Header().Get("X-Cache") != "MISS" || !strings.Contains(w.Body.String(), `"cancelled":true`) {
+		t.Fatalf("after cancel want fresh MISS, cache=%q body=%s", w.Header().Get("X-Cache"), w.Body.String())
+	}
+}
+
+func TestCache_TicketsKeyedByEvent(t *testing.T) {
+	deps := setupCachedServer(t)
+	seedTicketed(deps, generalTicket)
+	other := deps.er.Items["tk"]
+	other.ID, other.TicketTypes = "tk2", []models.TicketType{{ID: "vip", Name: "VIP", Price: 9000, Currency: "USD"}}
+	deps.er.Items["tk2"] = other
+
+	type ticket struct {
+		ID        string
+		Remaining *int
+	}
+	get := func(id, wantCache string) []ticket {
+		t.Helper()
+		w := doReq(deps.s, http.MethodGet, "/events/"+id+"/tickets", "", "")
+		if got := w.Header().Get("X-Cache"); got != wantCache {
+			t.Fatalf("GET %s tickets want %s, got %q", id, wantCache, got)
+		}
+		var out []ticket
+		decodeJSON(t, w, &out)
+		return out
+	}
+	if got := get("tk", "MISS"); len(got) != 1 || got[0].ID != "ga" || *got[0].Remaining != 2 {
+		t.Fatalf("tk tickets = %+v", got)
+	}
+	if got := get("tk2", "MISS"); len(got) != 1 || got[0].ID != "vip" {
+		t.Fatalf("tk2 must not get tk's cached tickets: %+v", got)
+	}
+	get("tk", "HIT")
+
+	// 購票後剩餘數量要重新算
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, 5)); w.Code != http.StatusCreated {
+		t.Fatalf("checkout code=%d body=%s", w.Code, w.Body.String())
+	}
+	if got := get("tk", "MISS"); *got[0].Remaining != 1 {
+		t.Fatalf("remaining after checkout = %d, want 1", *got[0].Remaining)
+	}
+}
+
+func TestCache_SearchPurgedOnEdit(t *testing.T) {
+	deps := setupCachedServer(t)
+	seedSearch(deps)
+	a := deps.er.Items["a"]
+	a.UserID, a.Version = 1, 1
+	deps.er.Items["a"] = a
+
+	search := func(wantCache string) searchResp {
+		t.Helper()
+		w := doReq(deps.s, http.MethodGet, "/events/search?q=go", "", "")
+		if got := w.Header().Get("X-Cache"); got != wantCache {
+			t.Fatalf("search want %s, got %q", wantCache, got)
+		}
+		var resp searchResp
+		decodeJSON(t, w, &resp)
+		return resp
+	}
+	search("MISS")
+	search("HIT")
+
+	if w := doPatch(deps.s, "/events/a", utils.MergePatchContentType, `{"name":"Rust Meetup"}`, authToken(t, 1)); w.Code != http.StatusOK {
+		t.Fatalf("patch code=%d body=%s", w.Code, w.Body.String())
+	}
+	if got := search("MISS"); len(got.Results) != 2 {
+		t.Fatalf("renamed event should drop out of the results: %+v", got.Results)
+	}
+}
+
+func TestCache_FacetsPurgedOnCreate(t *testing.T) {
+	deps := setupCachedServer(t)
+	seedTaxonomy(deps)
+
+	facets := func(wantCache string) models.Facets {
+		t.Helper()
+		w := doReq(deps.s, http.MethodGet, "/events/facets", "", "")
+		if got := w.Header().Get("X-Cache"); got != wantCache {
+			t.Fatalf("facets want %s, got %q", wantCache, got)
+		}
+		var f models.Facets
+		decodeJSON(t, w, &f)
+		return f
+	}
+	facets("MISS")
+	facets("HIT")
+
+	body := `{"name":"New","location":"L","dateTime":"2030-04-01T10:00:00Z","status":"published","tags":["fresh"]}`
+	if w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 1)); w.Code != http.StatusCreated {
+		t.Fatalf("create code=%d body=%s", w.Code, w.Body.String())
+	}
+	found := false
+	for _, tag := range facets("MISS").Tags {
+		found = found || tag.Value == "fresh"
+	}
+	if !found {
+		t.Fatalf("new tag missing from facets after create")
+	}
+}
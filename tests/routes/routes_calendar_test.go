@@ -0,0 +1,126 @@
+// 測試目的：iCalendar 匯出
+// 1) GET /events/:id.ics：權限同 GET /events/:id；重複事件展開，取消的那次標成 CANCELLED
+// 2) 個人訂閱：不帶 Authorization 用 token 讀；重新產生後舊 token 失效；撤銷後 404
+// 3) 沒有設定 WithCalendarFeeds 時沒有這些路由
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"restapi/models"
+	"restapi/routes"
+	"restapi/tests/mocks"
+)
+
+func unfoldICS(s string) string { return strings.ReplaceAll(s, "\r\n ", "") }
+
+func TestEventICS(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+
+	w := doReq(deps.s, http.MethodGet, "/events/ev.ics", "", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("ics code=%d type=%q body=%s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	body := unfoldICS(w.Body.String())
+	for _, want := range []string{"UID:ev\r\n", "SUMMARY:Meetup\r\n", "LOCATION:Taipei\r\n", "DTSTART:20300107T100000Z\r\n", "STATUS:CONFIRMED\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %q in %s", want, body)
+		}
+	}
+
+	// 草稿：別人看不到，.ics 也一樣
+	ev := deps.er.Items["ev"]
+	ev.Status = models.StatusDraft
+	deps.er.Items["ev"] = ev
+	if w := doReq(deps.s, http.MethodGet, "/events/ev.ics", "", authToken(t, 4)); w.Code != http.StatusNotFound {
+		t.Fatalf("draft for stranger want 404, got %d", w.Code)
+	}
+	w = doReq(deps.s, http.MethodGet, "/events/ev.ics", "", authToken(t, 1))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "STATUS:TENTATIVE\r\n") {
+		t.Fatalf("draft for owner code=%d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestEventICS_SeriesExpanded(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	owner := authToken(t, 5)
+	id := createSeries(t, deps, owner)
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/occurrences/20300114T100000Z/cancel", "", owner); w.Code != http.StatusOK {
+		t.Fatalf("cancel code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w := doReq(deps.s, http.MethodGet, "/events/"+id+".ics", "", "")
+	body := unfoldICS(w.Body.String())
+	if w.Code != http.StatusOK || strings.Count(body, "BEGIN:VEVENT") != 10 {
+		t.Fatalf("want 10 occurrences, code=%d body=%s", w.Code, body)
+	}
+	if !strings.Contains(body, "UID:"+id+"-20300114T100000Z\r\nDTSTAMP:") || strings.Count(body, "STATUS:CANCELLED") != 1 {
+		t.Fatalf("want per-occurrence UIDs and one cancelled: %s", body)
+	}
+}
+
+func TestCalendarFeed(t *testing.T) {
+	deps := setupServerWithDeps(t, routes.WithCalendarFeeds(&mocks.MockCalendarFeedRepo{}))
+	seedPeople(deps)
+	alice := authToken(t, 5)
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register", "", alice); w.Code != http.StatusCreated {
+		t.Fatalf("register code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	var feed struct{ Token, Path string }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/users/me/calendar-feed", "", alice), http.StatusCreated, &feed)
+	if feed.Token == "" || feed.Path != "/calendar/"+feed.Token+".ics" {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	// 行事曆 app 不帶 Authorization
+	w := doReq(deps.s, http.MethodGet, feed.Path, "", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "UID:ev\r\n") || w.Header().Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("feed code=%d cc=%q body=%s", w.Code, w.Header().Get("Cache-Control"), w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodGet, "/calendar/"+feed.Token, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("missing .ics want 404, got %d", w.Code)
+	}
+
+	// 事件取消 → 留在訂閱裡但標成 CANCELLED，讓行事曆 app 移除
+	ev := deps.er.Items["ev"]
+	ev.Status = models.StatusCancelled
+	deps.er.Items["ev"] = ev
+	if w := doReq(deps.s, http.MethodGet, feed.Path, "", ""); !strings.Contains(w.Body.String(), "STATUS:CANCELLED\r\n") {
+		t.Fatalf("cancelled event should be CANCELLED: %s", w.Body.String())
+	}
+
+	// 重新產生 → 舊的失效
+	var rotated struct{ Path string }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/users/me/calendar-feed", "", alice), http.StatusCreated, &rotated)
+	if w := doReq(deps.s, http.MethodGet, feed.Path, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("old token want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, rotated.Path, "", ""); w.Code != http.StatusOK {
+		t.Fatalf("new token want 200, got %d", w.Code)
+	}
+
+	// 撤銷
+	if w := doReq(deps.s, http.MethodDelete, "/users/me/calendar-feed", "", alice); w.Code != http.StatusOK {
+		t.Fatalf("revoke code=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodGet, rotated.Path, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("revoked token want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/users/me/calendar-feed", "", alice); w.Code != http.StatusNotFound {
+		t.Fatalf("revoke again want 404, got %d", w.Code)
+	}
+}
+
+func TestCalendarFeed_NotConfigured(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	if w := doReq(deps.s, http.MethodGet, "/calendar/x.ics", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 without WithCalendarFeeds, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/users/me/calendar-feed", "", authToken(t, 1)); w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 without WithCalendarFeeds, got %d", w.Code)
+	}
+}
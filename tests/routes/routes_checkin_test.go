@@ -0,0 +1,119 @@
+// 測試目的：票券與報到
+// 1) 只有本人拿得到票（PNG / SVG / token），作廢的報名沒有票
+// 2) checker 掃票：驗簽、事件不符、重複使用 → 409；非成員 403
+// 3) 取消後重新報名，舊票失效；報到統計
+package tests
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"restapi/models"
+)
+
+// registerAndTicket 報名 ev 後取回報名 id 與票券 token
+func registerAndTicket(t *testing.T, deps serverDeps, uid int64) (int64, string) {
+	t.Helper()
+	tok := authToken(t, uid)
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register", "", tok); w.Code != http.StatusCreated {
+		t.Fatalf("register want 201, got %d %s", w.Code, w.Body.String())
+	}
+	var regs []models.Registration
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/users/me/registrations", "", tok), &regs)
+	if len(regs) == 0 || regs[0].ID == 0 || regs[0].EventID != "ev" {
+		t.Fatalf("my registrations = %+v", regs)
+	}
+	var ticket struct{ Token string }
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/users/me/registrations/"+strconv.FormatInt(regs[0].ID, 10)+"/ticket?format=json", "", tok), &ticket)
+	return regs[0].ID, ticket.Token
+}
+
+func TestCheckIn_TicketRendering(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	id, _ := registerAndTicket(t, deps, 5)
+	path := "/users/me/registrations/" + strconv.FormatInt(id, 10) + "/ticket"
+
+	w := doReq(deps.s, http.MethodGet, path, "", authToken(t, 5))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("png ticket: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if img, err := png.Decode(bytes.NewReader(w.Body.Bytes())); err != nil || img.Bounds().Dx() < 100 {
+		t.Fatalf("png decode: %v", err)
+	}
+	w = doReq(deps.s, http.MethodGet, path+"?format=svg", "", authToken(t, 5))
+	if !strings.HasPrefix(w.Body.String(), "<svg") || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("svg ticket: %s", w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodGet, path, "", authToken(t, 6)); w.Code != http.StatusNotFound {
+		t.Fatalf("someone else's ticket want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, path+"?format=gif", "", authToken(t, 5)); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format want 400, got %d", w.Code)
+	}
+
+	// 事件取消 → 報名作廢，沒有票
+	deps.rr.VoidByEvent("ev")
+	if w := doReq(deps.s, http.MethodGet, path, "", authToken(t, 5)); w.Code != http.StatusConflict {
+		t.Fatalf("voided registration want 409, got %d", w.Code)
+	}
+}
+
+func TestCheckIn_ScanOnce(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	deps.er.Items["other"] = models.Event{ID: "other", Name: "Other", Location: "Taipei", DateTime: mustTime("2030-01-07T10:00:00Z"),
+		Status: models.StatusPublished, UserID: 3, Version: 1}
+	id, token := registerAndTicket(t, deps, 5)
+	checker := authToken(t, 3)
+	body := `{"token":"` + token + `"}`
+
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/checkin", body, authToken(t, 5)); w.Code != http.StatusForbidden {
+		t.Fatalf("attendee checking in themselves want 403, got %d", w.Code)
+	}
+	tampered := `{"token":"` + token[:len(token)-2] + `xx"}`
+	if p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/checkin", tampered, checker)); p.Status != http.StatusBadRequest || p.Errors["token"] == "" {
+		t.Fatalf("tampered ticket want 400, got %+v", p)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/other/checkin", body, checker); w.Code != http.StatusBadRequest {
+		t.Fatalf("ticket for another event want 400, got %d", w.Code)
+	}
+
+	var resp struct{ Registration models.Registration }
+	decodeJSON(t, doReq(deps.s, http.MethodPost, "/events/ev/checkin", body, checker), &resp)
+	if resp.Registration.ID != id || resp.Registration.CheckedInAt == nil || resp.Registration.CheckedInBy != 3 {
+		t.Fatalf("check-in = %+v", resp.Registration)
+	}
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/checkin", body, authToken(t, 2)))
+	if p.Status != http.StatusConflict || !strings.Contains(p.Detail, "already used") {
+		t.Fatalf("reused ticket want 409, got %+v", p)
+	}
+
+	var stats struct{ Registered, CheckedIn, Remaining int }
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev/checkin/stats", "", checker), &stats)
+	if stats.Registered != 2 || stats.CheckedIn != 1 || stats.Remaining != 1 { // 9 是 seed 的報名
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestCheckIn_CancelledRegistrationInvalidatesTicket(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	_, old := registerAndTicket(t, deps, 5)
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev/register", "", authToken(t, 5)); w.Code != http.StatusOK {
+		t.Fatalf("cancel want 200, got %d", w.Code)
+	}
+	_, fresh := registerAndTicket(t, deps, 5)
+
+	checker := authToken(t, 3)
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/checkin", `{"token":"`+old+`"}`, checker); w.Code != http.StatusConflict {
+		t.Fatalf("ticket of cancelled registration want 409, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/checkin", `{"token":"`+fresh+`"}`, checker); w.Code != http.StatusOK {
+		t.Fatalf("new ticket want 200, got %d %s", w.Code, w.Body.String())
+	}
+}
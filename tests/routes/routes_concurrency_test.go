@@ -0,0 +1,97 @@
+// 測試目的：事件的樂觀鎖（version / ETag / If-Match）
+// 1) 建立 → version=1；GET 帶 ETag
+// 2) PUT 用過期版本 → 409，回應帶 current；用正確版本 → 200 且 version+1
+// 3) DELETE If-Match 過期 → 409；PATCH If-Match 過期 → 409
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+func seedVersionedEvent(deps serverDeps, version int64) models.Event {
+	ev := models.Event{
+		ID: "e-v", Name: "Base", Location: "A", UserID: 7, Version: version,
+		DateTime: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	deps.er.Items[ev.ID] = ev
+	return ev
+}
+
+//POST → version 1；GET /events/:id → ETag "1"
+func TestVersion_CreateAndETag(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := `{"name":"V","location":"L","dateTime":"2030-01-01T00:00:00Z","version":42,"status":"published"}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 1))
+	var resp struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || resp.Event.Version != 1 {
+		t.Fatalf("want version 1, got %d %+v", w.Code, resp.Event)
+	}
+
+	w = doReq(deps.s, http.MethodGet, "/events/"+resp.Event.ID, "", "")
+	if w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("want ETag \"1\", got %q", w.Header().Get("ETag"))
+	}
+}
+
+//PUT 帶過期 version → 409 + current；帶正確 version → 200，version 變 4
+func TestVersion_PutConflict(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedVersionedEvent(deps, 3)
+	token := authToken(t, 7)
+
+	stale := `{"name":"Mine","location":"A","dateTime":"2030-01-01T00:00:00Z","version":2}`
+	w := doReq(deps.s, http.MethodPut, "/events/e-v", stale, token)
+	p := decodeProblem(t, w)
+	cur, _ := p.Current.(map[string]any)
+	if w.Code != http.StatusConflict || cur["version"] != float64(3) {
+		t.Fatalf("want 409 with current v3, got %d %+v", w.Code, p)
+	}
+	if deps.er.Items["e-v"].Name != "Base" {
+		t.Fatalf("stale write must not be applied")
+	}
+
+	fresh := strings.Replace(stale, `"version":2`, `"version":3`, 1)
+	w = doReq(deps.s, http.MethodPut, "/events/e-v", fresh, token)
+	if w.Code != http.StatusOK || deps.er.Items["e-v"].Version != 4 || w.Header().Get("ETag") != `"4"` {
+		t.Fatalf("want 200 v4, got %d %+v", w.Code, deps.er.Items["e-v"])
+	}
+}
+
+//DELETE / PATCH 帶過期 If-Match → 409，資料不變
+func TestVersion_IfMatchConflict(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedVersionedEvent(deps, 5)
+	token := authToken(t, 7)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/events/e-v", nil)
+	req.Header.Set("Authorization", token)
+	req.Header.Set("If-Match", `"4"`)
+	deps.s.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("DELETE want 409, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPatch, "/events/e-v", strings.NewReader(`{"name":"x"}`))
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", utils.MergePatchContentType)
+	req.Header.Set("If-Match", `"4"`)
+	deps.s.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("PATCH want 409, got %d", w.Code)
+	}
+
+	if got := deps.er.Items["e-v"]; got.Name != "Base" || got.Version != 5 {
+		t.Fatalf("event should be untouched, got %+v", got)
+	}
+}
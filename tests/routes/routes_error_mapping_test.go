@@ -0,0 +1,65 @@
+// 測試目的：領域錯誤 → HTTP 狀態碼的集中對應（routes/errors.go）
+// 1) 報名時 DB 掛掉 → 500（不能再被當成 409）
+// 2) 取消不存在的報名 → 404
+// 3) 重複 signup → 409
+// 4) 刪除不存在的事件 → 404
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"restapi/models"
+	"restapi/tests/mocks"
+)
+
+// 讓 Register() 回非領域錯誤（模擬 DB 斷線）
+type downRegRepo struct{ models.RegistrationRepository }
+func (downRegRepo) Register(*models.Registration, int) error { return errors.New("connection refused") }
+
+//POST /events/:id/register｜Register 回一般錯誤 → 500
+func TestRegister_DBDown_500(t *testing.T) {
+	er := &mocks.MockEventRepo{Items: map[string]models.Event{
+		"e-1": {ID: "e-1", Name: "n", DateTime: time.Now().UTC(), UserID: 1},
+	}}
+	s := setupWithRepos(t, er, nil, downRegRepo{})
+
+	w := doReq(s, http.MethodPost, "/events/e-1/register", "", authToken(t, 2))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("want 500, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+//DELETE /events/:id/register｜沒報名過 → 404
+func TestCancelRegistration_NotRegistered_404(t *testing.T) {
+	deps := setupServerWithDeps(t)
+
+	w := doReq(deps.s, http.MethodDelete, "/events/e-1/register", "", authToken(t, 2))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+//POST /signup｜同一 email 註冊兩次 → 409
+func TestSignup_Duplicate_409(t *testing.T) {
+	deps := setupServerWithDeps(t)
+
+	body := `{"email":"a@b.com","password":"Passw0rd"}`
+	_ = doReq(deps.s, http.MethodPost, "/signup", body, "")
+	w := doReq(deps.s, http.MethodPost, "/signup", body, "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("want 409, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+//DELETE /events/:id｜不存在 → 404
+func TestDeleteEvent_NotFound_404(t *testing.T) {
+	deps := setupServerWithDeps(t)
+
+	w := doReq(deps.s, http.MethodDelete, "/events/nope", "", authToken(t, 1))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404, got %d body=%s", w.Code, w.Body.String())
+	}
+}
@@ -21,7 +21,7 @@ import (
 
 // 讓 GetAll() 回錯
 type failingEventRepo struct{ models.EventRepository }
-func (f failingEventRepo) GetAll() ([]models.Event, error) { return nil, errors.New("boom") }
+func (f failingEventRepo) GetAll(models.EventFilter) ([]models.Event, error) { return nil, errors.New("boom") }
 
 // 讓 GetByID() 回錯
 type nfEventRepo struct{ models.EventRepository }
@@ -55,7 +55,7 @@ func TestGetEvents_InternalError_500(t *testing.T) {
 //POST /events/:id/register｜GetByID() 失敗 → 500（帶有效 JWT）
 func TestRegister_NotFoundEvent_500(t *testing.T) {
 	// 準備一個有效的 JWT（uid=1）
-	token, _ := utils.GenerateToken("x@x.com", 1)
+	token, _ := utils.GenerateToken("x@x.com", 1, models.DefaultOrgID)
 
 	s := setupWithRepos(t, nfEventRepo{}, &mocks.MockUserRepo{Users: map[string]models.User{}}, &mocks.MockRegRepo{Pairs: map[string]bool{}})
 	w := httptest.NewRecorder()
@@ -0,0 +1,121 @@
+// 測試目的：匯出報名名單
+// 1) 只有 owner / co-organizer 能匯出（checker 只能看名單）
+// 2) CSV：固定欄位 + 每個問題一欄；會被當成公式的值前面加 '
+// 3) XLSX：合法的 zip、工作表內容與 CSV 相同的列數
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"restapi/models"
+)
+
+func seedExport(t *testing.T, deps serverDeps) {
+	t.Helper()
+	seedPeople(deps) // 9 已報名（沒有帳號 → email 空白）
+	ev := deps.er.Items["ev"]
+	ev.Questions = testQuestions
+	deps.er.Items["ev"] = ev
+	for uid, body := range map[int64]string{
+		5: `{"answers":{"diet":"=HYPERLINK(\"x\")","size":"M","topics":["go","ops"]}}`,
+		6: `{"answers":{"size":"S"},"rsvp":"maybe"}`,
+	} {
+		if w := doReq(deps.s, http.MethodPost, "/events/ev/register", body, authToken(t, uid)); w.Code != http.StatusCreated {
+			t.Fatalf("register %d: %d %s", uid, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestExportAttendees_CSV(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedExport(t, deps)
+	for _, reg := range deps.rr.Rows {
+		if reg.UserID == 5 {
+			at := time.Date(2030, 1, 7, 9, 55, 0, 0, time.UTC)
+			reg.CheckedInAt = &at
+		}
+	}
+
+	if w := doReq(deps.s, http.MethodGet, "/events/ev/attendees/export", "", authToken(t, 3)); w.Code != http.StatusForbidden {
+		t.Fatalf("checker export want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/ev/attendees/export?format=pdf", "", authToken(t, 1)); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format want 400, got %d", w.Code)
+	}
+
+	w := doReq(deps.s, http.MethodGet, "/events/ev/attendees/export", "", authToken(t, 2))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), `attachment; filename="attendees-ev.csv"`) {
+		t.Fatalf("co-organizer export: %d %v", w.Code, w.Header())
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || strings.Join(rows[0][8:], "|") != "Dietary needs|T-shirt size|Topics" {
+		t.Fatalf("rows = %q", rows)
+	}
+	byEmail := map[string][]string{}
+	for _, r := range rows[1:] {
+		byEmail[r[1]] = r
+	}
+	alice, bob := byEmail["alice@example.com"], byEmail["bob@example.com"]
+	if alice == nil || alice[7] != "2030-01-07T09:55:00Z" || alice[8] != `'=HYPERLINK("x")` || alice[10] != "go; ops" {
+		t.Fatalf("alice = %q", alice)
+	}
+	if bob == nil || bob[5] != models.RSVPMaybe || bob[7] != "" || bob[9] != "S" {
+		t.Fatalf("bob = %q", bob)
+	}
+}
+
+func TestExportAttendees_XLSX(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedExport(t, deps)
+
+	w := doReq(deps.s, http.MethodGet, "/events/ev/attendees/export?format=xlsx", "", authToken(t, 1))
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Type"), "spreadsheetml") {
+		t.Fatalf("xlsx export: %d %v", w.Code, w.Header())
+	}
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	var sheet []byte
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			sheet, _ = io.ReadAll(rc)
+			rc.Close()
+		}
+	}
+	// XLSX 不是公式注入的對象（inlineStr 不會被計算），原樣保留
+	if n := bytes.Count(sheet, []byte("<row ")); n != 4 || !bytes.Contains(sheet, []byte("=HYPERLINK(&#34;x&#34;)")) {
+		t.Fatalf("sheet = %s", sheet)
+	}
+}
+
+// 沒有報名：只有標題列；指定單次只匯出那一場
+func TestExportAttendees_EmptyAndOccurrence(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	delete(deps.rr.Pairs, "9:ev")
+
+	w := doReq(deps.s, http.MethodGet, "/events/ev/attendees/export", "", authToken(t, 1))
+	if rows, _ := csv.NewReader(w.Body).ReadAll(); w.Code != http.StatusOK || len(rows) != 1 {
+		t.Fatalf("empty export: %d %q", w.Code, rows)
+	}
+
+	deps.rr.Pairs["4:ev@a"], deps.rr.Pairs["9:ev@b"] = true, true
+	w = doReq(deps.s, http.MethodGet, "/events/ev/attendees/export?occurrence=b", "", authToken(t, 1))
+	rows, _ := csv.NewReader(w.Body).ReadAll()
+	if len(rows) != 2 || rows[1][2] != "b" || !strings.Contains(w.Header().Get("Content-Disposition"), "attendees-ev-b.csv") {
+		t.Fatalf("occurrence export: %q", rows)
+	}
+}
@@ -0,0 +1,83 @@
+// 測試目的：附近 / 地圖搜尋
+// 1) ?near=lat,lng&radius=km → 只回半徑內、依距離排序、帶 distanceKm
+// 2) ?bbox=minLng,minLat,maxLng,maxLat → 只回視窗內
+// 3) 參數 / 座標不合法 → 400
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"restapi/models"
+)
+
+type geoItem struct {
+	models.Event
+	DistanceKm *float64 `json:"distanceKm"`
+}
+
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("code=%d body=%s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode: %v body=%s", err, w.Body.String())
+	}
+}
+
+func seedGeo(deps serverDeps) {
+	at := mustTime("2030-01-07T10:00:00Z")
+	pub := models.StatusPublished
+	deps.er.Items["taipei101"] = models.Event{ID: "taipei101", Name: "101", DateTime: at, Status: pub, Geo: models.NewGeoPoint(25.0340, 121.5645)}
+	deps.er.Items["mainstation"] = models.Event{ID: "mainstation", Name: "TMS", DateTime: at, Status: pub, Geo: models.NewGeoPoint(25.0478, 121.5170)}
+	deps.er.Items["kaohsiung"] = models.Event{ID: "kaohsiung", Name: "KH", DateTime: at, Status: pub, Geo: models.NewGeoPoint(22.6273, 120.3014)}
+	deps.er.Items["nowhere"] = models.Event{ID: "nowhere", Name: "text only", Location: "somewhere", DateTime: at, Status: pub}
+}
+
+func TestGeo_NearSortedByDistance(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedGeo(deps)
+
+	var got []geoItem
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events?near=25.0330,121.5654&radius=20", "", ""), &got)
+	if len(got) != 2 || got[0].ID != "taipei101" || got[1].ID != "mainstation" {
+		t.Fatalf("want 101 then main station, got %+v", got)
+	}
+	if got[0].DistanceKm == nil || *got[0].DistanceKm > 0.5 || *got[1].DistanceKm < 4 || *got[1].DistanceKm > 6 {
+		t.Fatalf("unexpected distances: %v %v", *got[0].DistanceKm, *got[1].DistanceKm)
+	}
+
+	// 預設半徑 10 km 也不會包含高雄
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events?near=25.0330,121.5654", "", ""), &got)
+	if len(got) != 2 {
+		t.Fatalf("default radius want 2, got %d", len(got))
+	}
+}
+
+func TestGeo_BoundingBox(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedGeo(deps)
+
+	var got []geoItem
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events?bbox=120,22,121,23", "", ""), &got)
+	if len(got) != 1 || got[0].ID != "kaohsiung" || got[0].DistanceKm != nil {
+		t.Fatalf("want only kaohsiung, got %+v", got)
+	}
+}
+
+func TestGeo_Invalid_400(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	for _, q := range []string{"near=abc", "near=95,0", "near=25,121&radius=1000", "bbox=1,2,3", "bbox=121,25,120,26"} {
+		if w := doReq(deps.s, http.MethodGet, "/events?"+q, "", ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s want 400, got %d", q, w.Code)
+		}
+	}
+	body := `{"name":"G","dateTime":"2030-01-07T10:00:00Z","geo":{"type":"Point","coordinates":[200,10]}}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5))
+	if p := decodeProblem(t, w); p.Errors["geo.coordinates"] == "" {
+		t.Fatalf("want geo.coordinates error, got %s", w.Body.String())
+	}
+}
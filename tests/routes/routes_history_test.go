@@ -0,0 +1,112 @@
+// 測試目的：事件修改歷程
+// 1) create → patch → delete 各記一筆，history 依 rev 排序、帶 actor 與欄位 diff
+// 2) GET /events/:id/revisions/:rev 回 snapshot；非擁有者 403
+// 3) rollback 產生新版本（不改寫歷史）；狀態不跟著還原（取消的事件不會復活）
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+func TestHistory_RecordsEveryWrite(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	token := authToken(t, 9)
+
+	w := doReq(deps.s, http.MethodPost, "/events",
+		`{"name":"v1","location":"Room A","dateTime":"2030-01-01T00:00:00Z"}`, token)
+	var created struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Event.ID
+
+	if w = doPatch(deps.s, "/events/"+id, utils.MergePatchContentType, `{"location":"Room B"}`, token); w.Code != http.StatusOK {
+		t.Fatalf("patch code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doReq(deps.s, http.MethodGet, "/events/"+id+"/history", "", token)
+	var revs []models.Revision
+	if err := json.Unmarshal(w.Body.Bytes(), &revs); err != nil || len(revs) != 2 {
+		t.Fatalf("want 2 revisions, got %d %s", len(revs), w.Body.String())
+	}
+	upd := revs[1]
+	if upd.Action != models.RevisionUpdate || upd.Rev != 2 || upd.UserID != 9 {
+		t.Fatalf("unexpected revision: %+v", upd)
+	}
+	if ch, ok := upd.Changes["location"]; !ok || ch.From != "Room A" || ch.To != "Room B" || len(upd.Changes) != 1 {
+		t.Fatalf("want only location diff, got %+v", upd.Changes)
+	}
+
+	// 刪除後仍可查歷程
+	_ = doReq(deps.s, http.MethodDelete, "/events/"+id, "", token)
+	w = doReq(deps.s, http.MethodGet, "/events/"+id+"/history", "", token)
+	_ = json.Unmarshal(w.Body.Bytes(), &revs)
+	if len(revs) != 3 || revs[2].Action != models.RevisionDelete {
+		t.Fatalf("want delete revision, got %+v", revs)
+	}
+
+	// 非擁有者不能看
+	if w = doReq(deps.s, http.MethodGet, "/events/"+id+"/history", "", authToken(t, 10)); w.Code != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", w.Code)
+	}
+}
+
+func TestHistory_GetRevisionAndRollback(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	token := authToken(t, 9)
+
+	w := doReq(deps.s, http.MethodPost, "/events",
+		`{"name":"original","location":"A","dateTime":"2030-01-01T00:00:00Z"}`, token)
+	var created struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Event.ID
+	_ = doPatch(deps.s, "/events/"+id, utils.MergePatchContentType, `{"name":"changed"}`, token)
+
+	w = doReq(deps.s, http.MethodGet, "/events/"+id+"/revisions/1", "", token)
+	var rev models.Revision
+	_ = json.Unmarshal(w.Body.Bytes(), &rev)
+	if w.Code != http.StatusOK || rev.Snapshot == nil || rev.Snapshot.Name != "original" {
+		t.Fatalf("unexpected revision: %d %+v", w.Code, rev)
+	}
+
+	w = doReq(deps.s, http.MethodPost, "/events/"+id+"/revisions/1/rollback", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollback code=%d body=%s", w.Code, w.Body.String())
+	}
+	if got := deps.er.Items[id]; got.Name != "original" || got.Version != 3 {
+		t.Fatalf("want name restored at v3, got %+v", got)
+	}
+	last := deps.rv.Items[len(deps.rv.Items)-1]
+	if last.Action != models.RevisionRollback || last.FromRev != 1 || last.Rev != 3 {
+		t.Fatalf("unexpected rollback revision: %+v", last)
+	}
+}
+
+func TestHistory_RollbackKeepsStatus(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	token := authToken(t, 9)
+
+	w := doReq(deps.s, http.MethodPost, "/events",
+		`{"name":"original","location":"A","dateTime":"2030-01-01T00:00:00Z","status":"published"}`, token)
+	var created struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	id := created.Event.ID
+	if created.Event.Status != models.StatusPublished {
+		t.Fatalf("create code=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/cancel", "", token); w.Code != http.StatusOK {
+		t.Fatalf("cancel code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// rev 1 是 published：還原內容，但事件仍然是 cancelled
+	w = doReq(deps.s, http.MethodPost, "/events/"+id+"/revisions/1/rollback", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollback code=%d body=%s", w.Code, w.Body.String())
+	}
+	if got := deps.er.Items[id]; got.Status != models.StatusCancelled || got.Name != "original" {
+		t.Fatalf("rollback must not revive a cancelled event: %+v", got)
+	}
+}
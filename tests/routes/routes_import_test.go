@@ -0,0 +1,168 @@
+// 測試目的：匯入事件
+// 1) dryRun 逐列報告，不寫入；有不合法的列時整份不匯入
+// 2) 小的匯入在請求內完成（201）；事件屬於發起的人、一律新 id、記錄歷程；寫入失敗回錯誤而不是 201
+// 3) 超過一批的在背景分批寫入（202），用 Location 查進度；別人查不到
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"restapi/models"
+	"restapi/tests/mocks"
+)
+
+const importCSV = `name,location,dateTime,tags,capacity,lat,lng
+Go Meetup,Taipei,2030-02-01T10:00:00Z,go; Community,50,25.03,121.56
+,Taipei,2030-02-02T10:00:00Z,,,,
+DB Night,Taipei,next friday,,many,25.03,
+`
+
+func TestImport_DryRunReportsEveryRow(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	tok := authToken(t, 1)
+
+	var report struct {
+		DryRun                bool
+		Total, Valid, Invalid int
+		Errors                []struct {
+			Line   int
+			Errors map[string]string
+		}
+	}
+	decodeJSON(t, doReq(deps.s, http.MethodPost, "/events/import?format=csv&dryRun=true", importCSV, tok), &report)
+	if !report.DryRun || report.Total != 3 || report.Valid != 1 || report.Invalid != 2 || len(report.Errors) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if e := report.Errors[0]; e.Line != 3 || e.Errors["name"] == "" {
+		t.Fatalf("line 3 = %+v", e)
+	}
+	if e := report.Errors[1]; e.Line != 4 || e.Errors["dateTime"] == "" || e.Errors["capacity"] == "" || e.Errors["geo"] == "" {
+		t.Fatalf("line 4 = %+v", e)
+	}
+
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/import?format=csv", importCSV, tok))
+	if p.Status != http.StatusBadRequest || p.Errors["lines[3].name"] == "" || p.Errors["lines[4].dateTime"] == "" {
+		t.Fatalf("invalid import want 400 with per-line errors, got %+v", p)
+	}
+	if len(deps.er.Items) != 0 {
+		t.Fatalf("nothing must be imported, got %d events", len(deps.er.Items))
+	}
+
+	for _, tc := range []struct{ path, body, field string }{
+		{"/events/import", importCSV, "format"},
+		{"/events/import?format=csv", "name,venue\nx,y\n", "header[1]"},
+	} {
+		if p := decodeProblem(t, doReq(deps.s, http.MethodPost, tc.path, tc.body, tok)); p.Errors[tc.field] == "" {
+			t.Fatalf("%s: want error on %s, got %+v", tc.path, tc.field, p)
+		}
+	}
+}
+
+func TestImport_SmallImportIsSynchronous(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := `{"id":"keep-me","name":"Go Meetup","location":"Taipei","dateTime":"2030-02-01T10:00:00Z","members":[{"userId":9,"role":"owner"}]}
+
+{"name":"Ticketed","location":"Taipei","dateTime":"2030-02-02T10:00:00Z","status":"published",
+ "ticketTypes":[{"name":"GA","price":500,"currency":"TWD","quantity":10}]}`
+	body = strings.Replace(body, ",\n ", ",", 1) // 一行一個事件
+
+	req := httptest.NewRequest(http.MethodPost, "/events/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Authorization", authToken(t, 1))
+	w := httptest.NewRecorder()
+	deps.s.ServeHTTP(w, req)
+
+	var job struct {
+		ID, Status      string
+		Total, Imported int
+	}
+	decodeStatus(t, w, http.StatusCreated, &job)
+	if job.Status != "done" || job.Total != 2 || job.Imported != 2 || len(deps.er.Items) != 2 {
+		t.Fatalf("job = %+v, events = %d", job, len(deps.er.Items))
+	}
+	for id, ev := range deps.er.Items {
+		if id == "keep-me" || ev.UserID != 1 || ev.Members != nil || ev.Version != 1 {
+			t.Fatalf("imported event = %+v", ev)
+		}
+		if ev.Name == "Go Meetup" && ev.Status != models.StatusDraft {
+			t.Fatalf("default status want draft, got %q", ev.Status)
+		}
+	}
+	if len(deps.rv.Items) != 2 || deps.rv.Items[0].Action != models.RevisionCreate {
+		t.Fatalf("revisions = %+v", deps.rv.Items)
+	}
+}
+
+// 寫入第一筆之後資料庫就失敗
+type failingBatchRepo struct{ *mocks.MockEventRepo }
+
+func (f failingBatchRepo) InOrg(org int64) models.EventRepository {
+	return failingBatchRepo{f.MockEventRepo.InOrg(org).(*mocks.MockEventRepo)}
+}
+func (f failingBatchRepo) CreateMany(es []models.Event) (int, error) {
+	n, _ := f.MockEventRepo.CreateMany(es[:1])
+	return n, errors.New("write failed")
+}
+
+func TestImport_SmallImportFailureIsAnError(t *testing.T) {
+	er := &mocks.MockEventRepo{Items: map[string]models.Event{}}
+	s := setupWithRepos(t, failingBatchRepo{er}, nil, nil)
+	body := "name,location,dateTime\nA,Taipei,2030-03-01T10:00:00Z\nB,Taipei,2030-03-02T10:00:00Z\n"
+
+	p := decodeProblem(t, doReq(s, http.MethodPost, "/events/import?format=csv", body, authToken(t, 1)))
+	if p.Status != http.StatusInternalServerError || !strings.Contains(p.Detail, "Stopped after 1 of 2 events") {
+		t.Fatalf("failed import want 500 with progress, got %+v", p)
+	}
+	if len(er.Items) != 1 {
+		t.Fatalf("events = %d, want the one written before the failure", len(er.Items))
+	}
+}
+
+func TestImport_TooLarge_413(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := "name,location,dateTime\nA," + strings.Repeat("x", 11<<20) + ",2030-03-01T10:00:00Z\n"
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/import?format=csv", body, authToken(t, 1)))
+	if p.Status != http.StatusRequestEntityTooLarge || p.Code != "payload_too_large" {
+		t.Fatalf("want 413 payload_too_large, got %+v", p)
+	}
+}
+
+func TestImport_LargeImportRunsInBackground(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	var b strings.Builder
+	b.WriteString("name,location,dateTime\n")
+	for i := 0; i < 250; i++ {
+		fmt.Fprintf(&b, "Event %d,Taipei,2030-03-01T10:00:00Z\n", i)
+	}
+
+	w := doReq(deps.s, http.MethodPost, "/events/import?format=csv", b.String(), authToken(t, 1))
+	var job struct {
+		ID, Status      string
+		Total, Imported int
+	}
+	decodeStatus(t, w, http.StatusAccepted, &job)
+	loc := w.Header().Get("Location")
+	if loc != "/events/import/"+job.ID || job.Total != 250 {
+		t.Fatalf("accepted job = %+v, location %q", job, loc)
+	}
+	if w := doReq(deps.s, http.MethodGet, loc, "", authToken(t, 2)); w.Code != http.StatusNotFound {
+		t.Fatalf("someone else's job want 404, got %d", w.Code)
+	}
+
+	for i := 0; job.Status != "done"; i++ {
+		if i == 8 {
+			t.Fatalf("import did not finish: %+v", job)
+		}
+		time.Sleep(50 * time.Millisecond)
+		decodeJSON(t, doReq(deps.s, http.MethodGet, loc, "", authToken(t, 1)), &job)
+	}
+	if job.Imported != 250 || len(deps.er.Items) != 250 {
+		t.Fatalf("job = %+v, events = %d", job, len(deps.er.Items))
+	}
+}
@@ -0,0 +1,90 @@
+// 測試目的：事件生命週期（draft / scheduled / published / cancelled / completed）
+// 1) 新事件預設 draft：公開列表看不到、訪客 GET 404、擁有者 GET 200、不能報名
+// 2) publish 後公開；cancel 後報名作廢；不合法轉換 → 409
+// 3) schedule 需要未來的 publishAt
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"restapi/models"
+)
+
+func createDraft(t *testing.T, deps serverDeps, token string) string {
+	t.Helper()
+	w := doReq(deps.s, http.MethodPost, "/events", `{"name":"D","location":"L","dateTime":"2030-01-01T00:00:00Z"}`, token)
+	var resp struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || resp.Event.Status != models.StatusDraft {
+		t.Fatalf("want draft created, got %d %+v", w.Code, resp.Event)
+	}
+	return resp.Event.ID
+}
+
+func TestLifecycle_DraftPublishCancel(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	owner := authToken(t, 5)
+	id := createDraft(t, deps, owner)
+
+	var list []models.Event
+	_ = json.Unmarshal(doReq(deps.s, http.MethodGet, "/events", "", "").Body.Bytes(), &list)
+	if len(list) != 0 {
+		t.Fatalf("draft must not be listed")
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/"+id, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("anonymous GET draft want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/"+id, "", owner); w.Code != http.StatusOK {
+		t.Fatalf("owner GET draft want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/register", "", authToken(t, 6)); w.Code != http.StatusConflict {
+		t.Fatalf("register on draft want 409, got %d", w.Code)
+	}
+
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/publish", "", owner); w.Code != http.StatusOK {
+		t.Fatalf("publish code=%d body=%s", w.Code, w.Body.String())
+	}
+	_ = json.Unmarshal(doReq(deps.s, http.MethodGet, "/events", "", "").Body.Bytes(), &list)
+	if len(list) != 1 {
+		t.Fatalf("published event should be listed")
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/register", "", authToken(t, 6)); w.Code != http.StatusCreated {
+		t.Fatalf("register code=%d", w.Code)
+	}
+
+	w := doReq(deps.s, http.MethodPost, "/events/"+id+"/cancel", "", owner)
+	var resp struct{ VoidedRegistrations int64 `json:"voidedRegistrations"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.VoidedRegistrations != 1 || len(deps.rr.Pairs) != 0 {
+		t.Fatalf("cancel should void 1 registration, got %d %s", w.Code, w.Body.String())
+	}
+
+	// cancelled 是終態
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/publish", "", owner); w.Code != http.StatusConflict {
+		t.Fatalf("publish cancelled want 409, got %d", w.Code)
+	}
+}
+
+func TestLifecycle_ScheduleNeedsFutureTime(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	owner := authToken(t, 5)
+	id := createDraft(t, deps, owner)
+
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/schedule", `{"publishAt":"2001-01-01T00:00:00Z"}`, owner); w.Code != http.StatusBadRequest {
+		t.Fatalf("past publishAt want 400, got %d", w.Code)
+	}
+	at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/schedule", `{"publishAt":"`+at+`"}`, owner); w.Code != http.StatusOK {
+		t.Fatalf("schedule code=%d body=%s", w.Code, w.Body.String())
+	}
+	if got := deps.er.Items[id]; got.Status != models.StatusScheduled || got.PublishAt == nil {
+		t.Fatalf("unexpected: %+v", got)
+	}
+	// 非擁有者不能轉換
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/publish", "", authToken(t, 6)); w.Code != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,133 @@
+// 測試目的：事件成員（owner / co-organizer / checker）
+// 1) co-organizer 可編輯但不能刪除、不能管理成員；checker 只能看報名名單
+// 2) 新增成員：只限 owner、使用者要存在、重複 409；成員可自己退出，owner 不能被移除
+// 3) 轉移擁有權：新 owner 取得全部權限，原 owner 變成 co-organizer
+// 4) PUT / PATCH 不能改 members
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"restapi/models"
+)
+
+func seedMembers(deps serverDeps) {
+	deps.er.Items["ev"] = models.Event{ID: "ev", Name: "Meetup", Location: "Taipei", DateTime: mustTime("2030-01-07T10:00:00Z"),
+		Status: models.StatusPublished, UserID: 1, Version: 1,
+		Members: []models.EventMember{{UserID: 2, Role: models.MemberCoOrganizer}, {UserID: 3, Role: models.MemberChecker}}}
+	for i, email := range []string{"owner@example.com", "co@example.com", "checker@example.com", "new@example.com"} {
+		deps.ur.Users[email] = models.User{ID: int64(i + 1), Email: email}
+	}
+	deps.rr.Pairs["9:ev"] = true
+}
+
+const memberUpdateBody = `{"name":"Renamed","location":"Taipei","dateTime":"2030-01-07T10:00:00Z"}`
+
+func TestMembers_RolePermissions(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	co, checker, stranger := authToken(t, 2), authToken(t, 3), authToken(t, 9)
+
+	if w := doReq(deps.s, http.MethodPut, "/events/ev", memberUpdateBody, co); w.Code != http.StatusOK {
+		t.Fatalf("co-organizer update want 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPut, "/events/ev", memberUpdateBody, checker); w.Code != http.StatusForbidden {
+		t.Fatalf("checker update want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev", "", co); w.Code != http.StatusForbidden {
+		t.Fatalf("co-organizer delete want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/members", `{"userId":4,"role":"checker"}`, co); w.Code != http.StatusForbidden {
+		t.Fatalf("co-organizer add member want 403, got %d", w.Code)
+	}
+
+	for _, tok := range []string{co, checker} {
+		var regs []models.Registration
+		decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev/registrations", "", tok), &regs)
+		if len(regs) != 1 || regs[0].UserID != 9 || regs[0].Status != models.RegistrationActive {
+			t.Fatalf("registrations = %+v", regs)
+		}
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/ev/registrations", "", stranger); w.Code != http.StatusForbidden {
+		t.Fatalf("stranger registrations want 403, got %d", w.Code)
+	}
+}
+
+func TestMembers_AddRemove(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	owner, checker := authToken(t, 1), authToken(t, 3)
+
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/members", `{"userId":4,"role":"owner"}`, owner); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid role want 400, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/members", `{"userId":42,"role":"checker"}`, owner); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown user want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/members", `{"userId":4,"role":"checker"}`, owner); w.Code != http.StatusCreated {
+		t.Fatalf("add member want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/members", `{"userId":4,"role":"co-organizer"}`, owner); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate member want 409, got %d", w.Code)
+	}
+	var members []models.EventMember
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev/members", "", checker), &members)
+	if len(members) != 4 || members[0].Role != models.MemberOwner {
+		t.Fatalf("members = %+v", members)
+	}
+
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev/members/2", "", checker); w.Code != http.StatusForbidden {
+		t.Fatalf("checker removing others want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev/members/3", "", checker); w.Code != http.StatusOK {
+		t.Fatalf("self removal want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/ev/registrations", "", checker); w.Code != http.StatusForbidden {
+		t.Fatalf("removed checker want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev/members/1", "", owner); w.Code != http.StatusConflict {
+		t.Fatalf("removing owner want 409, got %d", w.Code)
+	}
+}
+
+func TestMembers_TransferOwnership(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	owner, co := authToken(t, 1), authToken(t, 2)
+
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/transfer-ownership", `{"userId":1}`, co); w.Code != http.StatusForbidden {
+		t.Fatalf("co-organizer transfer want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/transfer-ownership", `{"userId":2}`, owner); w.Code != http.StatusOK {
+		t.Fatalf("transfer want 200, got %d %s", w.Code, w.Body.String())
+	}
+	ev := deps.er.Items["ev"]
+	if ev.UserID != 2 || ev.RoleOf(1) != models.MemberCoOrganizer || ev.RoleOf(3) != models.MemberChecker || len(ev.Members) != 2 {
+		t.Fatalf("after transfer: %+v", ev)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev", "", owner); w.Code != http.StatusForbidden {
+		t.Fatalf("former owner delete want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/events/ev", "", co); w.Code != http.StatusOK {
+		t.Fatalf("new owner delete want 200, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMembers_NotWritableThroughUpdate(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	co := authToken(t, 2)
+
+	body := strings.TrimSuffix(memberUpdateBody, "}") + `,"members":[{"userId":2,"role":"owner"}]}`
+	if w := doReq(deps.s, http.MethodPut, "/events/ev", body, co); w.Code != http.StatusOK {
+		t.Fatalf("update want 200, got %d", w.Code)
+	}
+	if ev := deps.er.Items["ev"]; len(ev.Members) != 2 || ev.RoleOf(2) != models.MemberCoOrganizer {
+		t.Fatalf("PUT must not change members: %+v", ev.Members)
+	}
+	if w := doReq(deps.s, http.MethodPatch, "/events/ev", `{"members":[]}`, co); w.Code != http.StatusBadRequest {
+		t.Fatalf("PATCH members want 400, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,91 @@
+// 測試目的：PATCH /events/:id 部分更新
+// 1) merge patch 只改 name，其餘欄位保留
+// 2) JSON Patch（replace + test）；test 不符 → 409
+// 3) 嘗試改 userId → 400；非擁有者 → 403
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+func doPatch(s *gin.Engine, path, contentType, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", token)
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func seedPatchEvent(deps serverDeps) models.Event {
+	ev := models.Event{
+		ID:          "e-p",
+		Name:        "Old",
+		Description: "keep me",
+		Location:    "Room A",
+		DateTime:    time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+		UserID:      7,
+	}
+	deps.er.Items[ev.ID] = ev
+	return ev
+}
+
+//merge patch 只送 name → description/location/dateTime 不變
+func TestPatchEvent_MergePatch_KeepsOtherFields(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	ev := seedPatchEvent(deps)
+
+	w := doPatch(deps.s, "/events/e-p", utils.MergePatchContentType, `{"name":"New"}`, authToken(t, 7))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	got := deps.er.Items["e-p"]
+	if got.Name != "New" || got.Description != ev.Description || got.Location != ev.Location || !got.DateTime.Equal(ev.DateTime) {
+		t.Fatalf("unexpected event after patch: %+v", got)
+	}
+}
+
+//JSON Patch：test 通過才 replace；test 不符 → 409 且不寫入
+func TestPatchEvent_JSONPatch(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedPatchEvent(deps)
+	token := authToken(t, 7)
+
+	ok := `[{"op":"test","path":"/location","value":"Room A"},{"op":"replace","path":"/location","value":"Room B"}]`
+	w := doPatch(deps.s, "/events/e-p", utils.JSONPatchContentType, ok, token)
+	if w.Code != http.StatusOK || deps.er.Items["e-p"].Location != "Room B" {
+		t.Fatalf("want location Room B, got %d %+v", w.Code, deps.er.Items["e-p"])
+	}
+
+	stale := `[{"op":"test","path":"/location","value":"Room A"},{"op":"replace","path":"/name","value":"X"}]`
+	w = doPatch(deps.s, "/events/e-p", utils.JSONPatchContentType, stale, token)
+	if w.Code != http.StatusConflict || deps.er.Items["e-p"].Name != "Old" {
+		t.Fatalf("want 409 and no write, got %d %+v", w.Code, deps.er.Items["e-p"])
+	}
+}
+
+//不可改 userId（400）；非擁有者（403）
+func TestPatchEvent_ReadOnlyAndForbidden(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedPatchEvent(deps)
+
+	w := doPatch(deps.s, "/events/e-p", utils.MergePatchContentType, `{"userId":99}`, authToken(t, 7))
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusBadRequest || p.Errors["userId"] == "" {
+		t.Fatalf("unexpected: code=%d problem=%+v", w.Code, p)
+	}
+
+	w = doPatch(deps.s, "/events/e-p", utils.MergePatchContentType, `{"name":"x"}`, authToken(t, 8))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("want 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+// 測試目的：錯誤回應統一為 RFC 7807 application/problem+json
+// 1) handler 錯誤（GET /events/:id 不存在）→ code=not_found、instance=request id
+// 2) middleware abort（沒帶 token）→ 同樣是 problem+json
+// 3) login 失敗不再出現 debug 字樣
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"restapi/middlewares"
+	"restapi/models"
+	"restapi/routes"
+	"restapi/tests/mocks"
+	"restapi/utils"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) utils.Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, utils.ProblemContentType) {
+		t.Fatalf("want %s, got %q", utils.ProblemContentType, ct)
+	}
+	var p utils.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode problem: %v body=%s", err, w.Body.String())
+	}
+	if p.Status != w.Code {
+		t.Fatalf("status mismatch: body=%d code=%d", p.Status, w.Code)
+	}
+	return p
+}
+
+//GET /events/:id 不存在 → 404 problem，instance 等於帶進來的 X-Request-ID
+func TestProblem_NotFound_HasCodeAndInstance(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	s := gin.New()
+	s.Use(middlewares.RequestID())
+	routes.RegisterRoutes(s, deps.ur, deps.rr, deps.er, nil, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events/nope", nil)
+	req.Header.Set(middlewares.RequestIDHeader, "req-123")
+	s.ServeHTTP(w, req)
+
+	p := decodeProblem(t, w)
+	if p.Code != utils.CodeNotFound || p.Title != "Not Found" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+	if p.Instance != "req-123" || w.Header().Get(middlewares.RequestIDHeader) != "req-123" {
+		t.Fatalf("want instance req-123, got %+v", p)
+	}
+}
+
+//沒帶 token → 401 problem（middleware abort 也走同一格式）
+func TestProblem_MiddlewareAbort(t *testing.T) {
+	deps := setupServerWithDeps(t)
+
+	w := doReq(deps.s, http.MethodPost, "/events", `{}`, "")
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusUnauthorized || p.Code != utils.CodeUnauthorized {
+		t.Fatalf("unexpected: code=%d problem=%+v", w.Code, p)
+	}
+}
+
+//login 帳密錯 → invalid_credentials，detail 不含舊的 debug 字樣
+func TestProblem_LoginFailure(t *testing.T) {
+	s := setupWithRepos(t, &mocks.MockEventRepo{Items: map[string]models.Event{}}, nil, nil)
+
+	w := doReq(s, http.MethodPost, "/login", `{"email":"x@y.com","password":"p"}`, "")
+	p := decodeProblem(t, w)
+	if p.Code != utils.CodeInvalidCredentials || strings.Contains(p.Detail, "user1") {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+}
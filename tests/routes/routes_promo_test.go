@@ -0,0 +1,159 @@
+// 測試目的：折扣碼
+// 1) 建立：事件主辦只能建立限定自己事件的折扣碼；組織 admin 可建立全組織的；驗證與重複 code
+// 2) 結帳套用：百分比 / 固定金額、範圍與幣別不符 → 400、全額折抵不經過金流
+// 3) 次數上限：用完 → 409 且訂單不佔名額；取消（退款）後歸還次數
+// 4) 過期訂單歸還的次數被別人用掉後，原訂單才付款成功 → 退款，不會超用
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"restapi/models"
+	"restapi/routes"
+	"restapi/tests/mocks"
+)
+
+func setupPromo(t *testing.T) (serverDeps, *mocks.MockPromoRepo) {
+	t.Helper()
+	pr := &mocks.MockPromoRepo{}
+	deps := setupServerWithDeps(t, routes.WithPromoCodes(pr))
+	seedTicketed(deps, generalTicket, models.TicketType{ID: "vip", Name: "VIP", Price: 5000, Currency: "EUR"})
+	deps.ur.Users["owner@example.com"] = models.User{ID: 1, Email: "owner@example.com"}
+	deps.ur.Users["admin@example.com"] = models.User{ID: 8, Email: "admin@example.com", Role: models.RoleAdmin}
+	return deps, pr
+}
+
+func TestPromo_CreatePermissions(t *testing.T) {
+	deps, pr := setupPromo(t)
+	owner, admin, stranger := authToken(t, 1), authToken(t, 8), authToken(t, 9)
+
+	scoped := `{"code":"early20","kind":"percent","value":20,"eventIds":["tk"]}`
+	if w := doReq(deps.s, http.MethodPost, "/promo-codes", scoped, stranger); w.Code != http.StatusForbidden {
+		t.Fatalf("stranger want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/promo-codes", `{"code":"ALL10","kind":"percent","value":10}`, owner); w.Code != http.StatusForbidden {
+		t.Fatalf("org-wide code by organizer want 403, got %d", w.Code)
+	}
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/promo-codes", `{"code":"x","kind":"percent","value":150}`, admin))
+	if p.Status != http.StatusBadRequest || p.Errors["code"] == "" || p.Errors["value"] == "" {
+		t.Fatalf("invalid code want 400 with field errors, got %+v", p)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/promo-codes", scoped, owner); w.Code != http.StatusCreated {
+		t.Fatalf("organizer scoped code want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if pr.Items[0].Code != "EARLY20" {
+		t.Fatalf("code should be stored upper-case: %+v", pr.Items[0])
+	}
+	if w := doReq(deps.s, http.MethodPost, "/promo-codes", `{"code":"Early20","kind":"fixed","value":100,"currency":"USD"}`, admin); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate code want 409, got %d", w.Code)
+	}
+
+	var codes []models.PromoCode
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/promo-codes?eventId=tk", "", owner), &codes)
+	if len(codes) != 1 {
+		t.Fatalf("codes = %+v", codes)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/promo-codes", "", owner); w.Code != http.StatusForbidden {
+		t.Fatalf("listing all codes by organizer want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, "/promo-codes/1", "", owner); w.Code != http.StatusOK {
+		t.Fatalf("deactivate want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga","promoCode":"early20"}`, authToken(t, 5)); w.Code != http.StatusBadRequest {
+		t.Fatalf("inactive code want 400, got %d", w.Code)
+	}
+}
+
+func TestPromo_CheckoutDiscounts(t *testing.T) {
+	deps, pr := setupPromo(t)
+	pr.Items = []models.PromoCode{
+		{ID: 1, Code: "TWENTY", Kind: models.PromoPercent, Value: 20, Active: true},
+		{ID: 2, Code: "FIVEOFF", Kind: models.PromoFixed, Value: 500, Currency: "USD", Active: true},
+		{ID: 3, Code: "OTHER", Kind: models.PromoPercent, Value: 50, EventIDs: []string{"elsewhere"}, Active: true},
+		{ID: 4, Code: "COMP", Kind: models.PromoPercent, Value: 100, TicketTypeIDs: []string{"vip"}, Active: true},
+	}
+
+	for _, tc := range []struct{ body, field string }{
+		{`{"ticketTypeId":"vip","promoCode":"FIVEOFF"}`, "promoCode"}, // EUR 票
+		{`{"ticketTypeId":"ga","promoCode":"OTHER"}`, "promoCode"},    // 別的事件
+		{`{"ticketTypeId":"ga","promoCode":"NOPE"}`, "promoCode"},
+	} {
+		p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/tk/register", tc.body, authToken(t, 5)))
+		if p.Status != http.StatusBadRequest || p.Errors[tc.field] == "" {
+			t.Fatalf("%s: want 400 on %s, got %+v", tc.body, tc.field, p)
+		}
+	}
+
+	var resp struct{ Order models.Order }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga","promoCode":"twenty"}`, authToken(t, 5)), http.StatusCreated, &resp)
+	if resp.Order.Amount != 1200 || resp.Order.Discount != 300 || resp.Order.PromoCode != "TWENTY" {
+		t.Fatalf("percent discount: %+v", resp.Order)
+	}
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga","promoCode":"FIVEOFF"}`, authToken(t, 6)), http.StatusCreated, &resp)
+	if resp.Order.Amount != 1000 || resp.Order.Discount != 500 {
+		t.Fatalf("fixed discount: %+v", resp.Order)
+	}
+	var comp struct{ Order models.Order }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"vip","promoCode":"COMP"}`, authToken(t, 7)), http.StatusCreated, &comp)
+	if comp.Order.Amount != 0 || comp.Order.PaymentID != "" || !deps.rr.Pairs["7:tk"] {
+		t.Fatalf("fully discounted order should skip the provider: %+v", comp.Order)
+	}
+}
+
+func TestPromo_RedemptionLimits(t *testing.T) {
+	deps, pr := setupPromo(t)
+	pr.Items = []models.PromoCode{{ID: 1, Code: "ONCE", Kind: models.PromoPercent, Value: 10, MaxRedemptions: 1, Active: true}}
+	body := `{"ticketTypeId":"vip","promoCode":"ONCE"}`
+
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", body, authToken(t, 5)); w.Code != http.StatusCreated {
+		t.Fatalf("first redemption want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", body, authToken(t, 6)); w.Code != http.StatusConflict {
+		t.Fatalf("over-redemption want 409, got %d", w.Code)
+	}
+	if o := deps.or.Items[1]; o.Status != models.OrderCancelled || deps.rr.Pairs["6:tk"] {
+		t.Fatalf("order without redemption must not hold a seat: %+v", o)
+	}
+
+	// 退款後歸還次數，別人可以用
+	if w := doReq(deps.s, http.MethodDelete, "/events/tk/register", "", authToken(t, 5)); w.Code != http.StatusOK {
+		t.Fatalf("cancel want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", body, authToken(t, 6)); w.Code != http.StatusCreated {
+		t.Fatalf("released code want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if p, _ := pr.Get(1); p.Redemptions != 1 {
+		t.Fatalf("redemptions = %d", p.Redemptions)
+	}
+}
+
+func TestPromo_LatePaymentDoesNotOverRedeem(t *testing.T) {
+	pp := models.NewLocalPaymentProvider("test-secret", models.WithManualConfirmation())
+	or, pr := &mocks.MockOrderRepo{}, &mocks.MockPromoRepo{}
+	deps := setupServerWithDeps(t, routes.WithPayments(or, pp), routes.WithPromoCodes(pr))
+	seedTicketed(deps, generalTicket)
+	pr.Items = []models.PromoCode{{ID: 1, Code: "ONCE", Kind: models.PromoPercent, Value: 10, MaxRedemptions: 1, Active: true}}
+	body := `{"ticketTypeId":"ga","promoCode":"ONCE"}`
+
+	var first struct{ Order models.Order }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", body, authToken(t, 5)), http.StatusAccepted, &first)
+	// 訂單過期：Redeem 下一次會把它的兌換紀錄清掉（SQL 的做法），次數給了下一個人
+	or.Items[0].ExpiresAt = time.Now().Add(-time.Minute)
+	_ = pr.Release(first.Order.ID)
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", body, authToken(t, 6)); w.Code != http.StatusAccepted {
+		t.Fatalf("released code want 202, got %d %s", w.Code, w.Body.String())
+	}
+
+	wb, header := pp.Confirm(first.Order.PaymentID, true)
+	if w := postWebhook(deps, wb, header); w.Code != http.StatusOK {
+		t.Fatalf("webhook want 200, got %d %s", w.Code, w.Body.String())
+	}
+	if or.Items[0].Status == models.OrderPaid || deps.rr.Pairs["5:tk"] {
+		t.Fatalf("late payment must not be accepted with the discount: %+v", or.Items[0])
+	}
+	if p, _ := pr.Get(1); p.Redemptions != 1 {
+		t.Fatalf("redemptions = %d, want 1", p.Redemptions)
+	}
+}
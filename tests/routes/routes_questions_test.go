@@ -0,0 +1,115 @@
+// 測試目的：報名表單
+// 1) 建立事件時驗證問題（類型、選項），自動產生 id
+// 2) 報名時依表單驗證答案：必填、選項、未知問題；通過後存在報名並出現在報名名單
+// 3) 有票種的事件：答案先存在訂單，付款成功後帶到報名
+package tests
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"restapi/models"
+)
+
+var testQuestions = []models.Question{
+	{ID: "diet", Label: "Dietary needs", Type: models.QuestionText},
+	{ID: "size", Label: "T-shirt size", Type: models.QuestionSelect, Required: true, Options: []string{"S", "M", "L"}},
+	{ID: "topics", Label: "Topics", Type: models.QuestionMultiSelect, Options: []string{"go", "db", "ops"}},
+}
+
+func TestQuestions_SchemaValidation(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	tok := authToken(t, 1)
+
+	bad := `{"name":"Conf","location":"Taipei","dateTime":"2030-03-01T09:00:00Z",
+		"questions":[{"label":"Size","type":"select"},{"label":"Bio","type":"essay"},{"label":"","type":"text"}]}`
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events", bad, tok))
+	if p.Status != http.StatusBadRequest || p.Errors["questions[0].options"] == "" || p.Errors["questions[1].type"] == "" || p.Errors["questions[2].label"] == "" {
+		t.Fatalf("want question validation errors, got %+v", p)
+	}
+
+	good := `{"name":"Conf","location":"Taipei","dateTime":"2030-03-01T09:00:00Z",
+		"questions":[{"label":" Size ","type":"select","required":true,"options":["S"," M ","M",""]}]}`
+	var created struct{ Event models.Event }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events", good, tok), http.StatusCreated, &created)
+	q := created.Event.Questions
+	if len(q) != 1 || q[0].ID == "" || q[0].Label != "Size" || !slices.Equal(q[0].Options, []string{"S", "M"}) {
+		t.Fatalf("questions not normalized: %+v", q)
+	}
+}
+
+func TestQuestions_AnswersValidatedAndStored(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	ev := deps.er.Items["ev"]
+	ev.Questions = testQuestions
+	deps.er.Items["ev"] = ev
+	tok := authToken(t, 5)
+
+	for _, tc := range []struct{ body, field string }{
+		{"", "answers.size"}, // 必填但沒有 body
+		{`{"answers":{"size":"XL"}}`, "answers.size"},
+		{`{"answers":{"size":"M","topics":["go","rust"]}}`, "answers.topics"},
+		{`{"answers":{"size":"M","topics":"go"}}`, "answers.topics"},
+		{`{"answers":{"size":"M","age":"30"}}`, "answers.age"},
+	} {
+		p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/register", tc.body, tok))
+		if p.Status != http.StatusBadRequest || p.Errors[tc.field] == "" {
+			t.Fatalf("%s: want 400 on %s, got %+v", tc.body, tc.field, p)
+		}
+	}
+	if deps.rr.Pairs["5:ev"] {
+		t.Fatalf("invalid answers must not register")
+	}
+
+	var resp struct{ Registration models.Registration }
+	body := `{"answers":{"diet":"  vegan ","size":"M","topics":["ops","go","go"]}}`
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/ev/register", body, tok), http.StatusCreated, &resp)
+	if resp.Registration.Answers.Text("diet") != "vegan" || resp.Registration.Answers["size"] != "M" {
+		t.Fatalf("answers = %+v", resp.Registration.Answers)
+	}
+
+	// 報名名單（checker 也看得到）帶答案；複選依選項順序、去重複
+	var regs []struct {
+		UserID  int64
+		Answers map[string]any
+	}
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev/registrations", "", authToken(t, 3)), &regs)
+	i := slices.IndexFunc(regs, func(r struct {
+		UserID  int64
+		Answers map[string]any
+	}) bool {
+		return r.UserID == 5
+	})
+	if i < 0 {
+		t.Fatalf("registration missing: %+v", regs)
+	}
+	if topics, _ := regs[i].Answers["topics"].([]any); len(topics) != 2 || topics[0] != "go" || topics[1] != "ops" {
+		t.Fatalf("attendee list answers = %+v", regs[i].Answers)
+	}
+}
+
+func TestQuestions_TicketedCheckoutCarriesAnswers(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTicketed(deps, generalTicket)
+	ev := deps.er.Items["tk"]
+	ev.Questions = testQuestions
+	deps.er.Items["tk"] = ev
+
+	if p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga"}`, authToken(t, 5))); p.Errors["answers.size"] == "" {
+		t.Fatalf("checkout without required answer want 400, got %+v", p)
+	}
+	if len(deps.or.Items) != 0 {
+		t.Fatalf("invalid answers must not create an order")
+	}
+	var resp struct{ Order models.Order }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga","answers":{"size":"L"}}`, authToken(t, 5)), http.StatusCreated, &resp)
+	if resp.Order.Answers["size"] != "L" {
+		t.Fatalf("order answers = %+v", resp.Order.Answers)
+	}
+	regs, _ := deps.rr.ListByEvent("tk")
+	if len(regs) != 1 || regs[0].Answers.Text("size") != "L" {
+		t.Fatalf("registration answers = %+v", regs)
+	}
+}
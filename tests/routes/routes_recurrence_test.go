@@ -0,0 +1,144 @@
+// 測試目的：重複事件系列（RRULE）
+// 1) 列表帶 from/to → 重複事件展開成單次（seriesId / occurrenceId）
+// 2) 單次例外：取消後不再列出、不能報名；改期後以新時間列出
+// 3) 報名按單次：重複事件必須帶 occurrence，同一人可報不同單次
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"restapi/models"
+)
+
+const weeklyBody = `{"name":"Meetup","location":"L","dateTime":"2030-01-07T10:00:00Z","status":"published",` +
+	`"recurrence":{"rrule":"FREQ=WEEKLY;COUNT=10"}}`
+
+type listedOccurrence struct {
+	models.Event
+	SeriesID     string `json:"seriesId"`
+	OccurrenceID string `json:"occurrenceId"`
+}
+
+func mustTime(s string) time.Time { t, _ := time.Parse(time.RFC3339, s); return t }
+
+func createSeries(t *testing.T, deps serverDeps, token string) string {
+	t.Helper()
+	w := doReq(deps.s, http.MethodPost, "/events", weeklyBody, token)
+	var resp struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || !resp.Event.IsRecurring() {
+		t.Fatalf("create series code=%d body=%s", w.Code, w.Body.String())
+	}
+	return resp.Event.ID
+}
+
+func listRange(t *testing.T, deps serverDeps, query string) []listedOccurrence {
+	t.Helper()
+	w := doReq(deps.s, http.MethodGet, "/events?"+query, "", "")
+	if w.Code != http.StatusOK { t.Fatalf("list code=%d body=%s", w.Code, w.Body.String()) }
+	var out []listedOccurrence
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	return out
+}
+
+func TestRecurrence_ExpandOnRead(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	id := createSeries(t, deps, authToken(t, 5))
+	deps.er.Items["one-off"] = models.Event{ID: "one-off", Name: "x", DateTime: mustTime("2030-01-10T00:00:00Z"), UserID: 1, Status: models.StatusPublished}
+
+	got := listRange(t, deps, "from=2030-01-01T00:00:00Z&to=2030-01-22T00:00:00Z")
+	if len(got) != 4 {
+		t.Fatalf("want 3 occurrences + 1 one-off, got %+v", got)
+	}
+	if got[0].SeriesID != id || got[0].OccurrenceID != "20300107T100000Z" || got[1].ID != "one-off" {
+		t.Fatalf("unexpected order/ids: %+v", got)
+	}
+
+	// 超過 COUNT 之後沒有
+	if got := listRange(t, deps, "from=2030-06-01T00:00:00Z&to=2030-07-01T00:00:00Z"); len(got) != 0 {
+		t.Fatalf("want none after COUNT, got %d", len(got))
+	}
+	// 範圍不合法 → 400
+	if w := doReq(deps.s, http.MethodGet, "/events?from=2030-02-01T00:00:00Z&to=2030-01-01T00:00:00Z", "", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("to before from want 400, got %d", w.Code)
+	}
+}
+
+func TestRecurrence_InvalidRRule_400(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := `{"name":"M","dateTime":"2030-01-07T10:00:00Z","recurrence":{"rrule":"FREQ=HOURLY"}}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5))
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusBadRequest || p.Errors["recurrence.rrule"] == "" {
+		t.Fatalf("want 400 on recurrence.rrule, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRecurrence_Exceptions(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	owner := authToken(t, 5)
+	id := createSeries(t, deps, owner)
+	rng := "from=2030-01-01T00:00:00Z&to=2030-01-22T00:00:00Z"
+
+	// 非擁有者 → 403；不在規則上的日期 → 404
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/occurrences/20300114T100000Z/cancel", "", authToken(t, 6)); w.Code != http.StatusForbidden {
+		t.Fatalf("non-owner want 403, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/occurrences/20300115T100000Z/cancel", "", owner); w.Code != http.StatusNotFound {
+		t.Fatalf("non-occurrence want 404, got %d", w.Code)
+	}
+
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/occurrences/20300114T100000Z/cancel", "", owner); w.Code != http.StatusOK {
+		t.Fatalf("cancel code=%d body=%s", w.Code, w.Body.String())
+	}
+	w := doReq(deps.s, http.MethodPost, "/events/"+id+"/occurrences/20300121T100000Z/move", `{"dateTime":"2030-01-20T18:00:00Z"}`, owner)
+	if w.Code != http.StatusOK {
+		t.Fatalf("move code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	got := listRange(t, deps, rng)
+	if len(got) != 2 || got[1].OccurrenceID != "20300121T100000Z" || !got[1].DateTime.Equal(mustTime("2030-01-20T18:00:00Z")) {
+		t.Fatalf("want 07 + moved 21→20, got %+v", got)
+	}
+
+	// /occurrences 會列出取消的那次
+	w = doReq(deps.s, http.MethodGet, "/events/"+id+"/occurrences?"+rng, "", "")
+	var resp struct{ Occurrences []models.Occurrence `json:"occurrences"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Occurrences) != 3 || !resp.Occurrences[1].Cancelled || !resp.Occurrences[2].Moved {
+		t.Fatalf("occurrences code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// 已取消的單次不能報名
+	if w := doReq(deps.s, http.MethodPost, "/events/"+id+"/register?occurrence=20300114T100000Z", "", authToken(t, 6)); w.Code != http.StatusConflict {
+		t.Fatalf("register cancelled occurrence want 409, got %d", w.Code)
+	}
+}
+
+func TestRecurrence_RegisterPerOccurrence(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	id := createSeries(t, deps, authToken(t, 5))
+	guest := authToken(t, 6)
+	reg := "/events/" + id + "/register"
+
+	if w := doReq(deps.s, http.MethodPost, reg, "", guest); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing occurrence want 400, got %d", w.Code)
+	}
+	for _, occ := range []string{"20300107T100000Z", "20300114T100000Z"} {
+		if w := doReq(deps.s, http.MethodPost, reg+"?occurrence="+occ, "", guest); w.Code != http.StatusCreated {
+			t.Fatalf("register %s code=%d body=%s", occ, w.Code, w.Body.String())
+		}
+	}
+	if w := doReq(deps.s, http.MethodPost, reg+"?occurrence=20300107T100000Z", "", guest); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate want 409, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, reg+"?occurrence=20300107T100000Z", "", guest); w.Code != http.StatusOK {
+		t.Fatalf("cancel registration code=%d", w.Code)
+	}
+	if len(deps.rr.Pairs) != 1 {
+		t.Fatalf("want 1 remaining registration, got %v", deps.rr.Pairs)
+	}
+}
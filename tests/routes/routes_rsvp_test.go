@@ -0,0 +1,130 @@
+// 測試目的：RSVP
+// 1) going + 同行者佔名額，額滿 → 409；maybe / not_going 不佔名額
+// 2) GET /events/:id 附上回覆統計，報名異動後不會拿到舊的快取
+// 3) 重複事件的名額按單次算；不會到的人沒有票
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"restapi/models"
+)
+
+type eventWithRSVP struct {
+	Name  string
+	Local *models.LocalTimes
+	RSVP  struct {
+		models.RSVPCounts
+		Attending, Capacity int
+		Remaining           *int
+	}
+}
+
+func TestRSVP_CapacityCountsGuests(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps) // 9 已報名（going）
+	ev := deps.er.Items["ev"]
+	ev.Capacity = 4
+	deps.er.Items["ev"] = ev
+	alice, bob := authToken(t, 5), authToken(t, 6)
+
+	var resp struct{ Registration models.Registration }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/ev/register", `{"rsvp":"going","guests":2}`, alice), http.StatusCreated, &resp)
+	if resp.Registration.RSVP != models.RSVPGoing || resp.Registration.Guests != 2 || resp.Registration.RespondedAt.IsZero() {
+		t.Fatalf("registration = %+v", resp.Registration)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register", "", bob); w.Code != http.StatusConflict {
+		t.Fatalf("full event want 409, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register", `{"rsvp":"Maybe"}`, bob); w.Code != http.StatusCreated {
+		t.Fatalf("maybe does not take a seat, got %d %s", w.Code, w.Body.String())
+	}
+	var got eventWithRSVP
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev", "", ""), &got) // 進快取
+
+	if w := doReq(deps.s, http.MethodPut, "/events/ev/rsvp", `{"rsvp":"going"}`, bob); w.Code != http.StatusConflict {
+		t.Fatalf("going on a full event want 409, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPut, "/events/ev/rsvp", `{"rsvp":"going","guests":1}`, alice); w.Code != http.StatusOK {
+		t.Fatalf("fewer guests want 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPut, "/events/ev/rsvp", `{"rsvp":"going"}`, bob); w.Code != http.StatusOK {
+		t.Fatalf("freed seat want 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev", "", ""), &got)
+	r := got.RSVP
+	if got.Name != "Meetup" || got.Local == nil {
+		t.Fatalf("event fields lost: %+v", got)
+	}
+	if r.Going != 3 || r.Maybe != 0 || r.Guests != 1 || r.Attending != 4 || r.Capacity != 4 || r.Remaining == nil || *r.Remaining != 0 {
+		t.Fatalf("rsvp = %+v", r)
+	}
+}
+
+func TestRSVP_Validation(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedMembers(deps)
+	tok := authToken(t, 5)
+
+	for _, tc := range []struct{ body, field string }{
+		{`{"rsvp":"perhaps"}`, "rsvp"},
+		{`{"guests":11}`, "guests"},
+		{`{"rsvp":"not going","guests":1}`, "guests"},
+	} {
+		p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/register", tc.body, tok))
+		if p.Status != http.StatusBadRequest || p.Errors[tc.field] == "" {
+			t.Fatalf("%s: want 400 on %s, got %+v", tc.body, tc.field, p)
+		}
+	}
+	if w := doReq(deps.s, http.MethodPut, "/events/ev/rsvp", `{"rsvp":"maybe"}`, tok); w.Code != http.StatusNotFound {
+		t.Fatalf("rsvp without registration want 404, got %d", w.Code)
+	}
+
+	// 不會到：有報名紀錄但沒有票，也不算進報到統計
+	var resp struct{ Registration models.Registration }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/ev/register", `{"rsvp":"not_going"}`, tok), http.StatusCreated, &resp)
+	if resp.Registration.RSVP != models.RSVPNotGoing {
+		t.Fatalf("rsvp = %q", resp.Registration.RSVP)
+	}
+	ticket := "/users/me/registrations/" + strconv.FormatInt(resp.Registration.ID, 10) + "/ticket"
+	if w := doReq(deps.s, http.MethodGet, ticket, "", tok); w.Code != http.StatusConflict {
+		t.Fatalf("ticket for not going want 409, got %d", w.Code)
+	}
+	var stats struct{ Registered int }
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev/checkin/stats", "", authToken(t, 3)), &stats)
+	if stats.Registered != 1 {
+		t.Fatalf("stats registered = %d, want only going", stats.Registered)
+	}
+}
+
+func TestRSVP_RecurringCapacityPerOccurrence(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	id := createSeries(t, deps, authToken(t, 5))
+	ev := deps.er.Items[id]
+	ev.Capacity = 1
+	deps.er.Items[id] = ev
+	reg := "/events/" + id + "/register?occurrence="
+
+	if w := doReq(deps.s, http.MethodPost, reg+"20300107T100000Z", "", authToken(t, 6)); w.Code != http.StatusCreated {
+		t.Fatalf("first seat want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPost, reg+"20300107T100000Z", "", authToken(t, 7)); w.Code != http.StatusConflict {
+		t.Fatalf("full occurrence want 409, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, reg+"20300114T100000Z", "", authToken(t, 7)); w.Code != http.StatusCreated {
+		t.Fatalf("another occurrence has its own seats, got %d", w.Code)
+	}
+
+	var got eventWithRSVP
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/"+id, "", ""), &got)
+	if got.RSVP.Going != 2 || got.RSVP.Remaining != nil {
+		t.Fatalf("series total = %+v", got.RSVP)
+	}
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/"+id+"?occurrence=20300114T100000Z", "", ""), &got)
+	if got.RSVP.Going != 1 || got.RSVP.Remaining == nil || *got.RSVP.Remaining != 0 {
+		t.Fatalf("occurrence counts = %+v", got.RSVP)
+	}
+}
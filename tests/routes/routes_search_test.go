@@ -0,0 +1,92 @@
+// 測試目的：全文搜尋 GET /events/search（預設用記憶體版 SearchIndex）
+// 1) 依相關度排序（name 權重 > location > description），回 highlights
+// 2) 可搭配 from/to；草稿不會被搜到
+// 3) 缺 q → 400；可以換成自訂 SearchIndex
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"restapi/models"
+	"restapi/routes"
+)
+
+type searchResp struct {
+	Results []models.SearchHit `json:"results"`
+}
+
+func seedSearch(deps serverDeps) {
+	pub := models.StatusPublished
+	deps.er.Items["a"] = models.Event{ID: "a", Name: "Go Meetup", Description: "monthly", DateTime: mustTime("2030-01-07T10:00:00Z"), Status: pub}
+	deps.er.Items["b"] = models.Event{ID: "b", Name: "Coffee", Description: "we talk about go and rust", DateTime: mustTime("2030-02-07T10:00:00Z"), Status: pub}
+	deps.er.Items["c"] = models.Event{ID: "c", Name: "Yoga", Location: "Go-go park", DateTime: mustTime("2030-03-07T10:00:00Z"), Status: pub}
+	deps.er.Items["d"] = models.Event{ID: "d", Name: "Go draft", DateTime: mustTime("2030-01-07T10:00:00Z"), Status: models.StatusDraft}
+	deps.er.Items["e"] = models.Event{ID: "e", Name: "Unrelated", DateTime: mustTime("2030-01-07T10:00:00Z"), Status: pub}
+}
+
+func TestSearch_RankedAndHighlighted(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedSearch(deps)
+
+	var resp searchResp
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/search?q=go", "", ""), &resp)
+	if len(resp.Results) != 3 {
+		t.Fatalf("want 3 hits (draft excluded), got %+v", resp.Results)
+	}
+	if ids := resp.Results[0].Event.ID + resp.Results[1].Event.ID + resp.Results[2].Event.ID; ids != "acb" {
+		t.Fatalf("want order a,c,b got %s", ids)
+	}
+	if h := resp.Results[0].Highlights["name"]; h != "<mark>Go</mark> Meetup" {
+		t.Fatalf("unexpected highlight %q", h)
+	}
+	if _, ok := resp.Results[2].Highlights["description"]; !ok {
+		t.Fatalf("description hit should be highlighted: %+v", resp.Results[2].Highlights)
+	}
+}
+
+func TestSearch_WithDateRangeAndLimit(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedSearch(deps)
+
+	var resp searchResp
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/search?q=go&from=2030-02-01&to=2030-02-28", "", ""), &resp)
+	if len(resp.Results) != 1 || resp.Results[0].Event.ID != "b" {
+		t.Fatalf("want only b in February, got %+v", resp.Results)
+	}
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/search?q=go&limit=1", "", ""), &resp)
+	if len(resp.Results) != 1 {
+		t.Fatalf("limit=1 want 1, got %d", len(resp.Results))
+	}
+}
+
+func TestSearch_Validation_400(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	for _, q := range []string{"", "?q=%20", "?q=go&limit=0", "?q=go&from=nope"} {
+		if w := doReq(deps.s, http.MethodGet, "/events/search"+q, "", ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q want 400, got %d", q, w.Code)
+		}
+	}
+}
+
+type fixedIndex struct{ got models.SearchQuery }
+
+func (f *fixedIndex) InOrg(int64) models.SearchIndex { return f }
+
+func (f *fixedIndex) Search(q models.SearchQuery) ([]models.SearchHit, error) {
+	f.got = q
+	return []models.SearchHit{{Event: models.Event{ID: "x"}, Score: 1}}, nil
+}
+
+func TestSearch_CustomIndex(t *testing.T) {
+	idx := &fixedIndex{}
+	deps := setupServerWithDeps(t, routes.WithSearchIndex(idx))
+
+	w := doReq(deps.s, http.MethodGet, "/events/search?q=hello&limit=5", "", "")
+	var resp searchResp
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Results) != 1 || idx.got.Text != "hello" || idx.got.Limit != 5 {
+		t.Fatalf("custom index not used: %d %s %+v", w.Code, w.Body.String(), idx.got)
+	}
+}
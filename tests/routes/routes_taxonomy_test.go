@@ -0,0 +1,72 @@
+// 測試目的：分類 / 標籤
+// 1) 建立時標籤正規化（小寫、去重）；格式不合法 → 400
+// 2) GET /events?category=&tag= 篩選（多個 tag 要全部符合）
+// 3) GET /events/facets 回各標籤 / 分類數量（數量多到少），可搭配篩選
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"restapi/models"
+)
+
+func TestTaxonomy_NormalizeAndValidate(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := `{"name":"T","dateTime":"2030-01-07T10:00:00Z","category":" Tech ","tags":["Go","go","Open Source"]}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5))
+	var resp struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || resp.Event.Category != "tech" || len(resp.Event.Tags) != 2 || resp.Event.Tags[1] != "open-source" {
+		t.Fatalf("unexpected: %d %+v", w.Code, resp.Event)
+	}
+
+	body = `{"name":"T","dateTime":"2030-01-07T10:00:00Z","tags":["c++"]}`
+	w = doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5))
+	if p := decodeProblem(t, w); p.Errors["tags"] == "" {
+		t.Fatalf("want tags error, got %s", w.Body.String())
+	}
+}
+
+func seedTaxonomy(deps serverDeps) {
+	at, pub := mustTime("2030-01-07T10:00:00Z"), models.StatusPublished
+	deps.er.Items["1"] = models.Event{ID: "1", Name: "a", DateTime: at, Status: pub, Category: "tech", Tags: []string{"go", "backend"}}
+	deps.er.Items["2"] = models.Event{ID: "2", Name: "b", DateTime: at, Status: pub, Category: "tech", Tags: []string{"go"}}
+	deps.er.Items["3"] = models.Event{ID: "3", Name: "c", DateTime: at.AddDate(0, 1, 0), Status: pub, Category: "sports", Tags: []string{"running"}}
+	deps.er.Items["4"] = models.Event{ID: "4", Name: "d", DateTime: at, Status: models.StatusDraft, Category: "tech", Tags: []string{"go"}}
+}
+
+func TestTaxonomy_Filter(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTaxonomy(deps)
+
+	var got []models.Event
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events?category=Tech", "", ""), &got)
+	if len(got) != 2 {
+		t.Fatalf("category=tech want 2, got %d", len(got))
+	}
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events?tag=go&tag=backend", "", ""), &got)
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("tag=go&tag=backend want [1], got %+v", got)
+	}
+}
+
+func TestTaxonomy_Facets(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTaxonomy(deps)
+
+	var f models.Facets
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/facets", "", ""), &f)
+	if len(f.Tags) != 3 || f.Tags[0] != (models.FacetCount{Value: "go", Count: 2}) || f.Tags[1].Value != "backend" {
+		t.Fatalf("unexpected tag facets (draft must not count): %+v", f.Tags)
+	}
+	if len(f.Categories) != 2 || f.Categories[0] != (models.FacetCount{Value: "tech", Count: 2}) {
+		t.Fatalf("unexpected category facets: %+v", f.Categories)
+	}
+
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/facets?from=2030-02-01&to=2030-02-28", "", ""), &f)
+	if len(f.Tags) != 1 || f.Tags[0].Value != "running" {
+		t.Fatalf("February facets want only running, got %+v", f.Tags)
+	}
+}
@@ -0,0 +1,239 @@
+// 測試目的：多租戶隔離（組織由 X-Tenant / token 的 orgId 決定）
+// 1) 列表、單筆、搜尋只看得到自己組織的事件；沒有 orgId 的舊資料屬於預設組織
+// 2) 在某組織註冊 → 成為該組織成員；不是成員就登入不了；token 不能拿到別的組織用
+// 3) 新事件寫入目前組織；別的組織的事件改不到、報名不到
+// 4) 組織成員管理：只限組織 owner / admin 與全域 admin
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"restapi/middlewares"
+	"restapi/models"
+	"restapi/routes"
+	"restapi/tests/mocks"
+	"restapi/utils"
+)
+
+type tenantDeps struct {
+	serverDeps
+	orgs *mocks.MockOrgRepo
+}
+
+func setupTenantServer(t *testing.T) tenantDeps {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { mr.Close() })
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	orgs := &mocks.MockOrgRepo{
+		Orgs: map[int64]models.Organization{
+			1: {ID: 1, Slug: "default", Name: "Default"},
+			2: {ID: 2, Slug: "acme", Name: "Acme"},
+			3: {ID: 3, Slug: "globex", Name: "Globex"},
+		},
+		Members: map[int64]map[int64]string{2: {10: models.OrgOwner, 11: models.OrgMember}, 3: {20: models.OrgMember}},
+	}
+	ur := &mocks.MockUserRepo{Users: map[string]models.User{
+		"owner@acme.test":  {ID: 10, Email: "owner@acme.test", Password: "Passw0rd", Role: models.RoleUser},
+		"member@acme.test": {ID: 11, Email: "member@acme.test", Password: "Passw0rd", Role: models.RoleUser},
+		"bob@globex.test":  {ID: 20, Email: "bob@globex.test", Password: "Passw0rd", Role: models.RoleUser},
+		"root@x.test":      {ID: 99, Email: "root@x.test", Password: "Passw0rd", Role: models.RoleAdmin},
+	}, Members: orgs.Members}
+	rr := &mocks.MockRegRepo{Pairs: map[string]bool{}}
+	at, pub := mustTime("2030-01-07T10:00:00Z"), models.StatusPublished
+	er := &mocks.MockEventRepo{Items: map[string]models.Event{
+		"legacy": {ID: "legacy", Name: "Legacy meetup", DateTime: at, Status: pub, UserID: 1, Version: 1},
+		"a1":     {ID: "a1", OrgID: 2, Name: "Acme meetup", DateTime: at, Status: pub, UserID: 10, Version: 1},
+		"g1":     {ID: "g1", OrgID: 3, Name: "Globex meetup", DateTime: at, Status: pub, UserID: 20, Version: 1},
+	}}
+	rv, iv := &mocks.MockRevisionRepo{}, &mocks.MockInvitationRepo{}
+
+	s := gin.New()
+	s.Use(middlewares.Tenant(middlewares.TenantConfig{Orgs: orgs}))
+	routes.RegisterRoutes(s, ur, rr, er, rdb, utils.NewCacheInvalidator(rdb),
+		routes.WithRevisions(rv), routes.WithInvitations(iv), routes.WithOrganizations(orgs))
+	return tenantDeps{serverDeps{s: s, ur: ur, rr: rr, er: er, rv: rv, iv: iv}, orgs}
+}
+
+func tenantReq(s *gin.Engine, method, path, body, tenant, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if tenant != "" {
+		req.Header.Set(middlewares.TenantHeader, tenant)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func orgToken(t *testing.T, uid, org int64) string {
+	t.Helper()
+	tok, err := utils.GenerateToken("tester@example.com", uid, org)
+	if err != nil {
+		t.Fatalf("gen token: %v", err)
+	}
+	return tok
+}
+
+func TestTenancy_ReadIsolation(t *testing.T) {
+	d := setupTenantServer(t)
+
+	ids := func(tenant string) []string {
+		var list []models.Event
+		decodeJSON(t, tenantReq(d.s, http.MethodGet, "/events", "", tenant, ""), &list)
+		out := []string{}
+		for _, e := range list {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	if got := ids("acme"); len(got) != 1 || got[0] != "a1" {
+		t.Fatalf("acme list = %v", got)
+	}
+	if got := ids(""); len(got) != 1 || got[0] != "legacy" {
+		t.Fatalf("default org list = %v (legacy events belong to the default org)", got)
+	}
+	if w := tenantReq(d.s, http.MethodGet, "/events/g1", "", "acme", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("other org's event want 404, got %d", w.Code)
+	}
+	var sr searchResp
+	decodeJSON(t, tenantReq(d.s, http.MethodGet, "/events/search?q=meetup", "", "globex", ""), &sr)
+	if len(sr.Results) != 1 {
+		t.Fatalf("globex search want 1 hit, got %d", len(sr.Results))
+	}
+}
+
+func TestTenancy_SignupLoginScopedToOrg(t *testing.T) {
+	d := setupTenantServer(t)
+
+	if w := tenantReq(d.s, http.MethodPost, "/signup", `{"email":"new@acme.test","password":"Passw0rd"}`, "acme", ""); w.Code != http.StatusCreated {
+		t.Fatalf("signup want 201, got %d %s", w.Code, w.Body.String())
+	}
+	u := d.ur.Users["new@acme.test"]
+	if d.orgs.Members[2][u.ID] != models.OrgMember {
+		t.Fatalf("signup must add membership, got %+v", d.orgs.Members)
+	}
+
+	login := `{"email":"new@acme.test","password":"Passw0rd"}`
+	w := tenantReq(d.s, http.MethodPost, "/login", login, "acme", "")
+	var resp struct{ Token string `json:"token"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	claims, err := utils.ParseToken(resp.Token)
+	if w.Code != http.StatusOK || err != nil || claims.OrgID != 2 {
+		t.Fatalf("login code=%d claims=%+v err=%v", w.Code, claims, err)
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/login", login, "globex", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login in another org want 401, got %d", w.Code)
+	}
+	// /login 每個 IP 只有 2 次 burst，全域 admin 換一台 server 測
+	if w := tenantReq(setupTenantServer(t).s, http.MethodPost, "/login", `{"email":"root@x.test","password":"Passw0rd"}`, "globex", ""); w.Code != http.StatusOK {
+		t.Fatalf("global admin login want 200, got %d", w.Code)
+	}
+	if w := tenantReq(d.s, http.MethodGet, "/events", "", "globex", resp.Token); w.Code != http.StatusForbidden {
+		t.Fatalf("acme token at globex want 403, got %d", w.Code)
+	}
+}
+
+func TestTenancy_WritesStayInOrg(t *testing.T) {
+	d := setupTenantServer(t)
+	acme := orgToken(t, 11, 2)
+
+	body := `{"name":"New","location":"Taipei","dateTime":"2030-02-01T10:00:00Z"}`
+	w := tenantReq(d.s, http.MethodPost, "/events", body, "", acme) // 組織來自 token
+	var resp struct{ Event models.Event `json:"event"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || d.er.Items[resp.Event.ID].OrgID != 2 {
+		t.Fatalf("create code=%d stored=%+v", w.Code, d.er.Items[resp.Event.ID])
+	}
+
+	// 就算是 globex 事件的擁有者，拿 acme 的 token 也碰不到
+	bob := orgToken(t, 20, 2)
+	if w := tenantReq(d.s, http.MethodDelete, "/events/g1", "", "", bob); w.Code != http.StatusNotFound {
+		t.Fatalf("cross-org delete want 404, got %d", w.Code)
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/events/g1/register", "", "", acme); w.Code != http.StatusNotFound {
+		t.Fatalf("cross-org register want 404, got %d", w.Code)
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/events/a1/register", "", "", acme); w.Code != http.StatusCreated {
+		t.Fatalf("register want 201, got %d", w.Code)
+	}
+	if d.rr.Orgs["11:a1"] != 2 {
+		t.Fatalf("registration must be stored in org 2: %+v", d.rr.Orgs)
+	}
+}
+
+func TestTenancy_OrgMembers(t *testing.T) {
+	d := setupTenantServer(t)
+	owner, member, root := orgToken(t, 10, 2), orgToken(t, 11, 2), orgToken(t, 99, 2)
+
+	if w := tenantReq(d.s, http.MethodPost, "/orgs/current/members", `{"userId":20}`, "", member); w.Code != http.StatusForbidden {
+		t.Fatalf("member adding members want 403, got %d", w.Code)
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/orgs/current/members", `{"userId":20}`, "", owner); w.Code != http.StatusCreated {
+		t.Fatalf("owner add member want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/orgs/current/members", `{"userId":20}`, "", owner); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate member want 409, got %d", w.Code)
+	}
+	// bob 現在同時屬於 acme 與 globex，可以在 acme 登入
+	if w := tenantReq(d.s, http.MethodPost, "/login", `{"email":"bob@globex.test","password":"Passw0rd"}`, "acme", ""); w.Code != http.StatusOK {
+		t.Fatalf("login after join want 200, got %d", w.Code)
+	}
+	var members []models.OrgMembership
+	decodeJSON(t, tenantReq(d.s, http.MethodGet, "/orgs/current/members", "", "", owner), &members)
+	if len(members) != 3 {
+		t.Fatalf("members = %+v", members)
+	}
+	if w := tenantReq(d.s, http.MethodDelete, "/orgs/current/members/20", "", "", owner); w.Code != http.StatusOK {
+		t.Fatalf("remove member want 200, got %d", w.Code)
+	}
+
+	if w := tenantReq(d.s, http.MethodPost, "/orgs", `{"slug":"initech","name":"Initech","ownerId":11}`, "", owner); w.Code != http.StatusForbidden {
+		t.Fatalf("non-admin create org want 403, got %d", w.Code)
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/orgs", `{"slug":"Bad Slug","name":"x"}`, "", root); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid slug want 400, got %d", w.Code)
+	}
+	if w := tenantReq(d.s, http.MethodPost, "/orgs", `{"slug":"initech","name":"Initech","ownerId":11}`, "", root); w.Code != http.StatusCreated {
+		t.Fatalf("admin create org want 201, got %d %s", w.Code, w.Body.String())
+	}
+	var org models.Organization
+	decodeJSON(t, tenantReq(d.s, http.MethodGet, "/orgs/current", "", "initech", ""), &org)
+	if org.Slug != "initech" || d.orgs.Members[org.ID][11] != models.OrgOwner {
+		t.Fatalf("org=%+v members=%+v", org, d.orgs.Members[org.ID])
+	}
+}
+
+func TestTenancy_OrgAdminSeesOrgTrash(t *testing.T) {
+	d := setupTenantServer(t)
+	ev := d.er.Items["a1"]
+	ev.UserID = 11
+	d.er.Items["a1"] = ev
+	if w := tenantReq(d.s, http.MethodDelete, "/events/a1", "", "", orgToken(t, 11, 2)); w.Code != http.StatusOK {
+		t.Fatalf("delete want 200, got %d", w.Code)
+	}
+	var trash []models.Event
+	decodeJSON(t, tenantReq(d.s, http.MethodGet, "/events/trash", "", "", orgToken(t, 10, 2)), &trash)
+	if len(trash) != 1 || trash[0].ID != "a1" {
+		t.Fatalf("org owner trash = %+v", trash)
+	}
+	decodeJSON(t, tenantReq(d.s, http.MethodGet, "/events/trash", "", "", orgToken(t, 20, 3)), &trash)
+	if len(trash) != 0 {
+		t.Fatalf("other org trash must be empty, got %+v", trash)
+	}
+}
@@ -0,0 +1,352 @@
+// 測試目的：票種與購票（訂單 + PaymentProvider）
+// 1) 沒有票種的事件維持免費報名；票種驗證（幣別、販售期間）
+// 2) 付款同步成功 → 訂單 paid + 報名；數量上限、重複購買、被拒的卡
+// 3) 需要 webhook 確認的付款：202 → webhook 後報名；重送同一事件只處理一次；簽章錯誤 403；訂單過期後才付款 → 退款
+// 4) 取消報名：已付款的退款（同一筆付款不會退兩次）並釋出名額；已報到、已開始或已結束 → 409
+// 5) 取消事件：所有已付款的訂單退款；退款失敗回錯誤，再 POST /cancel 重試
+// 6) 取消重複事件的某一次：只作廢 / 退款該次，重試同上
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"restapi/models"
+	"restapi/routes"
+	"restapi/tests/mocks"
+)
+
+func seedTicketed(deps serverDeps, types ...models.TicketType) {
+	deps.er.Items["tk"] = models.Event{ID: "tk", Name: "Conf", Location: "Taipei", DateTime: mustTime("2030-03-01T09:00:00Z"),
+		Status: models.StatusPublished, UserID: 1, Version: 1, TicketTypes: types}
+}
+
+func postWebhook(deps serverDeps, body []byte, header http.Header) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	deps.s.ServeHTTP(w, req)
+	return w
+}
+
+var generalTicket = models.TicketType{ID: "ga", Name: "General", Price: 1500, Currency: "USD", Quantity: 2}
+
+func TestTickets_ValidationAndFreeEvents(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	tok := authToken(t, 1)
+
+	bad := `{"name":"Conf","location":"Taipei","dateTime":"2030-03-01T09:00:00Z",
+		"ticketTypes":[{"name":"VIP","price":5000,"currency":"dollars"},{"name":"Late","salesStart":"2030-02-01T00:00:00Z","salesEnd":"2030-01-01T00:00:00Z"}]}`
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events", bad, tok))
+	if p.Status != http.StatusBadRequest || p.Errors["ticketTypes[0].currency"] == "" || p.Errors["ticketTypes[1].salesEnd"] == "" {
+		t.Fatalf("want ticket validation errors, got %+v", p)
+	}
+
+	good := `{"name":"Conf","location":"Taipei","dateTime":"2030-03-01T09:00:00Z","ticketTypes":[{"name":"Early bird","price":900,"currency":"usd"}]}`
+	var created struct{ Event models.Event }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events", good, tok), http.StatusCreated, &created)
+	if tt := created.Event.TicketTypes; len(tt) != 1 || tt[0].ID == "" || tt[0].Currency != "USD" {
+		t.Fatalf("ticket types not normalized: %+v", tt)
+	}
+
+	// 沒有票種：照舊免費報名，不建立訂單
+	seedMembers(deps)
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register", "", authToken(t, 5)); w.Code != http.StatusCreated {
+		t.Fatalf("free register want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if len(deps.or.Items) != 0 {
+		t.Fatalf("free registration must not create orders: %+v", deps.or.Items)
+	}
+}
+
+func TestTickets_CheckoutConfirmsSynchronously(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	closed := models.TicketType{ID: "late", Name: "Late", Price: 2000, Currency: "USD", SalesEnd: ptrTime(time.Now().Add(-time.Hour))}
+	seedTicketed(deps, generalTicket, closed)
+
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, 5)); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing ticket type with several types want 400, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"late"}`, authToken(t, 5)); w.Code != http.StatusConflict {
+		t.Fatalf("closed sales want 409, got %d", w.Code)
+	}
+
+	var resp struct{ Order models.Order }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga"}`, authToken(t, 5)), http.StatusCreated, &resp)
+	if resp.Order.Status != models.OrderPaid || resp.Order.Amount != 1500 || resp.Order.PaymentID == "" {
+		t.Fatalf("checkout want paid order, got %+v", resp.Order)
+	}
+	if !deps.rr.Pairs["5:tk"] {
+		t.Fatalf("paid order should register the user")
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga"}`, authToken(t, 5)); w.Code != http.StatusConflict {
+		t.Fatalf("second order want 409, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/orders/1", "", authToken(t, 6)); w.Code != http.StatusNotFound {
+		t.Fatalf("someone else's order want 404, got %d", w.Code)
+	}
+
+	doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga"}`, authToken(t, 6))
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", `{"ticketTypeId":"ga"}`, authToken(t, 7)); w.Code != http.StatusConflict {
+		t.Fatalf("sold out want 409, got %d", w.Code)
+	}
+	var avail []struct {
+		ID        string
+		Remaining *int
+		OnSale    bool
+	}
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/tk/tickets", "", ""), &avail)
+	if len(avail) != 2 || avail[0].Remaining == nil || *avail[0].Remaining != 0 || avail[0].OnSale || avail[1].Remaining != nil {
+		t.Fatalf("availability = %+v", avail)
+	}
+}
+
+func TestTickets_DeclinedPaymentReleasesSeat(t *testing.T) {
+	pp := models.NewLocalPaymentProvider("test-secret", models.WithDeclinedAmounts(1500))
+	or := &mocks.MockOrderRepo{}
+	deps := setupServerWithDeps(t, routes.WithPayments(or, pp))
+	seedTicketed(deps, generalTicket)
+
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, 5)))
+	if p.Status != http.StatusPaymentRequired || p.Code != "payment_failed" {
+		t.Fatalf("declined want 402 payment_failed, got %+v", p)
+	}
+	if deps.rr.Pairs["5:tk"] || or.Items[0].Status != models.OrderFailed {
+		t.Fatalf("declined payment must fail the order without registering: %+v", or.Items)
+	}
+}
+
+func TestTickets_WebhookConfirmationIsIdempotent(t *testing.T) {
+	pp := models.NewLocalPaymentProvider("test-secret", models.WithManualConfirmation())
+	or := &mocks.MockOrderRepo{}
+	deps := setupServerWithDeps(t, routes.WithPayments(or, pp))
+	seedTicketed(deps, generalTicket)
+
+	var resp struct {
+		Order       models.Order
+		CheckoutURL string
+	}
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, 5)), http.StatusAccepted, &resp)
+	if resp.Order.Status != models.OrderPending || resp.CheckoutURL == "" {
+		t.Fatalf("manual payment want pending order, got %+v", resp)
+	}
+	if deps.rr.Pairs["5:tk"] {
+		t.Fatalf("pending order must not register yet")
+	}
+
+	body, header := pp.Confirm(resp.Order.PaymentID, true)
+	if w := postWebhook(deps, body, http.Header{models.LocalSignatureHeader: {"00"}}); w.Code != http.StatusForbidden {
+		t.Fatalf("bad signature want 403, got %d", w.Code)
+	}
+	if w := postWebhook(deps, body, header); w.Code != http.StatusOK {
+		t.Fatalf("webhook want 200, got %d %s", w.Code, w.Body.String())
+	}
+	if !deps.rr.Pairs["5:tk"] || or.Items[0].Status != models.OrderPaid {
+		t.Fatalf("webhook should confirm order and register: %+v", or.Items)
+	}
+
+	// 使用者取消後金流重送同一個事件：不能又把報名加回來
+	if w := doReq(deps.s, http.MethodDelete, "/events/tk/register", "", authToken(t, 5)); w.Code != http.StatusOK {
+		t.Fatalf("cancel want 200, got %d %s", w.Code, w.Body.String())
+	}
+	var again struct{ Message string }
+	decodeJSON(t, postWebhook(deps, body, header), &again)
+	if again.Message != "Already processed." || deps.rr.Pairs["5:tk"] || or.Items[0].Status != models.OrderRefunded {
+		t.Fatalf("redelivered webhook must be a no-op: %q %+v", again.Message, or.Items)
+	}
+}
+
+// 過期的訂單名額已經釋出（可能被別人買走），之後才到的付款成功不能再報名，要退款
+func TestTickets_LatePaymentOnExpiredOrderIsRefunded(t *testing.T) {
+	pp := models.NewLocalPaymentProvider("test-secret", models.WithManualConfirmation())
+	or := &mocks.MockOrderRepo{}
+	deps := setupServerWithDeps(t, routes.WithPayments(or, pp))
+	last := generalTicket
+	last.Quantity = 1
+	seedTicketed(deps, last)
+
+	var resp struct{ Order models.Order }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, 5)), http.StatusAccepted, &resp)
+	or.Items[0].ExpiresAt = time.Now().Add(-time.Minute)
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, 6)); w.Code != http.StatusAccepted {
+		t.Fatalf("seat should be free after expiry, got %d %s", w.Code, w.Body.String())
+	}
+
+	body, header := pp.Confirm(resp.Order.PaymentID, true)
+	if w := postWebhook(deps, body, header); w.Code != http.StatusOK {
+		t.Fatalf("webhook want 200, got %d %s", w.Code, w.Body.String())
+	}
+	if deps.rr.Pairs["5:tk"] || or.Items[0].Status != models.OrderExpired {
+		t.Fatalf("late payment must not register: %+v", or.Items[0])
+	}
+	if err := pp.Refund(resp.Order.PaymentID, 1); err == nil {
+		t.Fatalf("late payment should have been refunded")
+	}
+	if sold, _ := or.Sold("tk", ""); sold["ga"] != 1 {
+		t.Fatalf("sold = %v, want only the second order", sold)
+	}
+}
+
+func TestTickets_CancelRefundsAndReleases(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTicketed(deps, generalTicket)
+	tok := authToken(t, 5)
+
+	doReq(deps.s, http.MethodPost, "/events/tk/register", "", tok)
+	var resp struct{ Order models.Order }
+	decodeJSON(t, doReq(deps.s, http.MethodDelete, "/events/tk/register", "", tok), &resp)
+	if resp.Order.Status != models.OrderRefunded || deps.rr.Pairs["5:tk"] {
+		t.Fatalf("cancel want refunded order, got %+v", resp.Order)
+	}
+	// 同一筆付款不能退兩次
+	if err := deps.pp.Refund(resp.Order.PaymentID, resp.Order.Amount); err == nil {
+		t.Fatalf("payment should already be fully refunded")
+	}
+	// 名額釋出後可以再買
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register", "", tok); w.Code != http.StatusCreated {
+		t.Fatalf("re-purchase want 201, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTickets_CancelRejectedAfterCheckIn(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTicketed(deps, generalTicket)
+	tok := authToken(t, 5)
+
+	doReq(deps.s, http.MethodPost, "/events/tk/register", "", tok)
+	now := time.Now().UTC()
+	deps.rr.Rows["5:tk"].CheckedInAt = &now
+
+	p := decodeProblem(t, doReq(deps.s, http.MethodDelete, "/events/tk/register", "", tok))
+	if p.Status != http.StatusConflict {
+		t.Fatalf("cancel after check-in want 409, got %+v", p)
+	}
+	if deps.or.Items[0].Status != models.OrderPaid || !deps.rr.Pairs["5:tk"] {
+		t.Fatalf("order and registration should be untouched: %+v %v", deps.or.Items[0], deps.rr.Pairs)
+	}
+}
+
+func TestTickets_CancelRejectedOnceEventStarted(t *testing.T) {
+	for name, change := range map[string]func(*models.Event){
+		"started":   func(e *models.Event) { e.DateTime = time.Now().UTC().Add(-time.Hour) },
+		"completed": func(e *models.Event) { e.Status = models.StatusCompleted },
+	} {
+		deps := setupServerWithDeps(t)
+		seedTicketed(deps, generalTicket)
+		tok := authToken(t, 5)
+
+		doReq(deps.s, http.MethodPost, "/events/tk/register", "", tok)
+		ev := deps.er.Items["tk"]
+		change(&ev)
+		deps.er.Items["tk"] = ev
+
+		p := decodeProblem(t, doReq(deps.s, http.MethodDelete, "/events/tk/register", "", tok))
+		if p.Status != http.StatusConflict {
+			t.Fatalf("%s: cancel want 409, got %+v", name, p)
+		}
+		if deps.or.Items[0].Status != models.OrderPaid || !deps.rr.Pairs["5:tk"] {
+			t.Fatalf("%s: order and registration should be untouched: %+v %v", name, deps.or.Items[0], deps.rr.Pairs)
+		}
+	}
+}
+
+func TestTickets_CancelEventRefundsPaidOrders(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTicketed(deps, generalTicket)
+	for _, uid := range []int64{5, 6} {
+		if w := doReq(deps.s, http.MethodPost, "/events/tk/register", "", authToken(t, uid)); w.Code != http.StatusCreated {
+			t.Fatalf("checkout %d: %d %s", uid, w.Code, w.Body.String())
+		}
+	}
+	owner := authToken(t, 1)
+
+	// 第二筆退款失敗（金流不認得這筆付款）→ 錯誤回應；第一筆照樣退，報名都作廢
+	payID := deps.or.Items[1].PaymentID
+	deps.or.Items[1].PaymentID = "pay_unknown"
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/tk/cancel", "", owner))
+	if p.Status != http.StatusPaymentRequired || p.Code != "payment_failed" {
+		t.Fatalf("failed refund want 402, got %+v", p)
+	}
+	if deps.or.Items[0].Status != models.OrderRefunded || deps.or.Items[1].Status != models.OrderPaid || len(deps.rr.Pairs) != 0 {
+		t.Fatalf("want first refunded, second still paid, registrations voided: %+v %v", deps.or.Items, deps.rr.Pairs)
+	}
+
+	// 重試：事件已經是 cancelled，只補做沒完成的退款
+	deps.or.Items[1].PaymentID = payID
+	var resp struct{ RefundedOrders int }
+	decodeJSON(t, doReq(deps.s, http.MethodPost, "/events/tk/cancel", "", owner), &resp)
+	if resp.RefundedOrders != 1 || deps.or.Items[1].Status != models.OrderRefunded {
+		t.Fatalf("retry want 1 refund, got %d %+v", resp.RefundedOrders, deps.or.Items)
+	}
+	if err := deps.pp.Refund(deps.or.Items[0].PaymentID, 1); err == nil {
+		t.Fatalf("first payment should be fully refunded")
+	}
+}
+
+func TestTickets_CancelOccurrenceRefundsItsOrders(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTicketed(deps, generalTicket)
+	ev := deps.er.Items["tk"]
+	ev.Recurrence = &models.Recurrence{RRule: "FREQ=WEEKLY;COUNT=10"}
+	deps.er.Items["tk"] = ev
+	const first, second = "20300301T090000Z", "20300308T090000Z"
+	for _, b := range []struct {
+		uid int64
+		occ string
+	}{{5, first}, {6, first}, {7, second}} {
+		if w := doReq(deps.s, http.MethodPost, "/events/tk/register?occurrence="+b.occ, "", authToken(t, b.uid)); w.Code != http.StatusCreated {
+			t.Fatalf("checkout %d: %d %s", b.uid, w.Code, w.Body.String())
+		}
+	}
+	var regs []models.Registration
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/users/me/registrations", "", authToken(t, 5)), &regs)
+	var ticket struct{ Token string }
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/users/me/registrations/"+strconv.FormatInt(regs[0].ID, 10)+"/ticket?format=json", "", authToken(t, 5)), &ticket)
+	owner := authToken(t, 1)
+	cancel := "/events/tk/occurrences/" + first + "/cancel"
+
+	// 第二筆退款失敗 → 錯誤回應；同一次的第一筆照樣退、報名作廢，別的單次不動
+	payID := deps.or.Items[1].PaymentID
+	deps.or.Items[1].PaymentID = "pay_unknown"
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, cancel, "", owner))
+	if p.Status != http.StatusPaymentRequired || p.Code != "payment_failed" {
+		t.Fatalf("failed refund want 402, got %+v", p)
+	}
+	if deps.or.Items[0].Status != models.OrderRefunded || deps.or.Items[1].Status != models.OrderPaid || deps.or.Items[2].Status != models.OrderPaid {
+		t.Fatalf("want first refunded, others still paid: %+v", deps.or.Items)
+	}
+	if deps.rr.Pairs["5:tk@"+first] || deps.rr.Pairs["6:tk@"+first] || !deps.rr.Pairs["7:tk@"+second] {
+		t.Fatalf("only the cancelled occurrence should be voided: %v", deps.rr.Pairs)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/checkin", `{"token":"`+ticket.Token+`"}`, owner); w.Code != http.StatusConflict {
+		t.Fatalf("check-in for cancelled occurrence want 409, got %d %s", w.Code, w.Body.String())
+	}
+
+	// 重試：單次已經取消，只補做沒完成的退款
+	deps.or.Items[1].PaymentID = payID
+	var resp struct{ RefundedOrders int }
+	decodeJSON(t, doReq(deps.s, http.MethodPost, cancel, "", owner), &resp)
+	if resp.RefundedOrders != 1 || deps.or.Items[1].Status != models.OrderRefunded || deps.or.Items[2].Status != models.OrderPaid {
+		t.Fatalf("retry want 1 refund, got %d %+v", resp.RefundedOrders, deps.or.Items)
+	}
+}
+
+// decodeStatus 同 decodeJSON，但預期的狀態碼不一定是 200
+func decodeStatus(t *testing.T, w *httptest.ResponseRecorder, status int, v any) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("want %d, got %d %s", status, w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode: %v body=%s", err, w.Body.String())
+	}
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
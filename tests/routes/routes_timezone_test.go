@@ -0,0 +1,82 @@
+// 測試目的：結束時間與時區
+// 1) 輸入帶 offset → 以 UTC 儲存/輸出，另外回 local（事件時區）
+// 2) endTime 必須晚於 dateTime；timeZone 必須是 IANA 名稱
+// 3) 日期篩選依 ?tz= 解讀「哪一天」
+// 4) 重複事件在事件時區展開：跨夏令時間仍是當地 10:00
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"restapi/models"
+)
+
+func TestTimeZone_UTCAndLocal(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := `{"name":"TZ","dateTime":"2030-01-07T18:00:00+08:00","endTime":"2030-01-07T20:00:00+08:00","timeZone":"Asia/Taipei"}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create code=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Event struct {
+			DateTime string `json:"dateTime"`
+			EndTime  string `json:"endTime"`
+			Local    struct {
+				TimeZone string `json:"timeZone"`
+				DateTime string `json:"dateTime"`
+				EndTime  string `json:"endTime"`
+			} `json:"local"`
+		} `json:"event"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	ev := resp.Event
+	if ev.DateTime != "2030-01-07T10:00:00Z" || ev.EndTime != "2030-01-07T12:00:00Z" {
+		t.Fatalf("want UTC times, got %s / %s", ev.DateTime, ev.EndTime)
+	}
+	if ev.Local.TimeZone != "Asia/Taipei" || ev.Local.DateTime != "2030-01-07T18:00:00+08:00" || ev.Local.EndTime != "2030-01-07T20:00:00+08:00" {
+		t.Fatalf("unexpected local: %+v", ev.Local)
+	}
+}
+
+func TestTimeZone_Validation(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	body := `{"name":"TZ","dateTime":"2030-01-07T10:00:00Z","endTime":"2030-01-07T09:00:00Z","timeZone":"Mars/Olympus"}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5))
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusBadRequest || p.Errors["endTime"] == "" || p.Errors["timeZone"] == "" {
+		t.Fatalf("want endTime + timeZone errors, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeZone_DateFilter(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	// 台北時間 2030-01-08 01:00
+	deps.er.Items["late"] = models.Event{ID: "late", Name: "x", DateTime: mustTime("2030-01-07T17:00:00Z"), UserID: 1, Status: models.StatusPublished}
+
+	if got := listRange(t, deps, "from=2030-01-08&to=2030-01-08&tz=Asia/Taipei"); len(got) != 1 {
+		t.Fatalf("Taipei 2030-01-08 should include the event, got %d", len(got))
+	}
+	if got := listRange(t, deps, "from=2030-01-08&to=2030-01-08"); len(got) != 0 {
+		t.Fatalf("UTC 2030-01-08 should not include the event, got %d", len(got))
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events?from=2030-01-08&tz=Nowhere", "", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad tz want 400, got %d", w.Code)
+	}
+}
+
+func TestTimeZone_RecurrenceAcrossDST(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	// 紐約 2030-03-10 開始夏令時間；每週一當地 10:00
+	body := `{"name":"NY","dateTime":"2030-03-04T10:00:00-05:00","timeZone":"America/New_York","status":"published",` +
+		`"recurrence":{"rrule":"FREQ=WEEKLY;COUNT=2"}}`
+	if w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 5)); w.Code != http.StatusCreated {
+		t.Fatalf("create code=%d body=%s", w.Code, w.Body.String())
+	}
+	got := listRange(t, deps, "from=2030-03-01&to=2030-03-31")
+	if len(got) != 2 || !got[0].DateTime.Equal(mustTime("2030-03-04T15:00:00Z")) || !got[1].DateTime.Equal(mustTime("2030-03-11T14:00:00Z")) {
+		t.Fatalf("want 15:00Z then 14:00Z, got %+v", got)
+	}
+}
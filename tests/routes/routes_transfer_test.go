@@ -0,0 +1,164 @@
+// 測試目的：轉讓與團體報名
+// 1) 轉讓要對方接受才生效；接受後報名換人、舊票失效；拒絕 / 撤回後不能再接受
+// 2) 團體報名全有全無：有帳號的直接報名，沒有帳號的保留名額（佔 capacity），註冊後接受
+package tests
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"restapi/models"
+)
+
+func seedPeople(deps serverDeps) {
+	seedMembers(deps)
+	for id, email := range map[int64]string{5: "alice@example.com", 6: "bob@example.com"} {
+		deps.ur.Users[email] = models.User{ID: id, Email: email}
+	}
+}
+
+func offer(t *testing.T, deps serverDeps, uid int64, email string) string {
+	t.Helper()
+	var resp struct{ Transfer models.Transfer }
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/ev/register/transfer", `{"email":"`+email+`"}`, authToken(t, uid)), http.StatusCreated, &resp)
+	return "/events/ev/register/transfer/" + strconv.FormatInt(resp.Transfer.ID, 10)
+}
+
+func TestTransfer_AcceptMovesRegistration(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedPeople(deps)
+	id, oldTicket := registerAndTicket(t, deps, 5)
+	alice, bob := authToken(t, 5), authToken(t, 6)
+
+	if p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/register/transfer", `{"email":"Alice@example.com"}`, alice)); p.Errors["email"] == "" {
+		t.Fatalf("transfer to yourself want 400, got %+v", p)
+	}
+	path := offer(t, deps, 5, "Bob@Example.com")
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register/transfer", `{"email":"new@example.com"}`, alice); w.Code != http.StatusConflict {
+		t.Fatalf("second pending transfer want 409, got %d", w.Code)
+	}
+	if !deps.rr.Pairs["5:ev"] {
+		t.Fatalf("registration must not move before acceptance")
+	}
+
+	var inbox []models.Transfer
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/users/me/transfers", "", bob), &inbox)
+	if len(inbox) != 1 || inbox[0].Email != "bob@example.com" || inbox[0].Kind != models.TransferRegistration {
+		t.Fatalf("inbox = %+v", inbox)
+	}
+	if w := doReq(deps.s, http.MethodPost, path+"/accept", "", authToken(t, 4)); w.Code != http.StatusNotFound {
+		t.Fatalf("someone else accepting want 404, got %d", w.Code)
+	}
+
+	var resp struct{ Registration models.Registration }
+	decodeJSON(t, doReq(deps.s, http.MethodPost, path+"/accept", "", bob), &resp)
+	if resp.Registration.ID != id || resp.Registration.UserID != 6 || deps.rr.Pairs["5:ev"] || !deps.rr.Pairs["6:ev"] {
+		t.Fatalf("accepted registration = %+v, pairs = %v", resp.Registration, deps.rr.Pairs)
+	}
+	if w := doReq(deps.s, http.MethodPost, path+"/accept", "", bob); w.Code != http.StatusConflict {
+		t.Fatalf("accepting twice want 409, got %d", w.Code)
+	}
+	// 舊票的 userId 對不上 → 失效
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/checkin", `{"token":"`+oldTicket+`"}`, authToken(t, 3)); w.Code != http.StatusConflict {
+		t.Fatalf("old ticket want 409, got %d", w.Code)
+	}
+}
+
+func TestTransfer_DeclineAndCancel(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedPeople(deps)
+	registerAndTicket(t, deps, 5)
+	alice, bob := authToken(t, 5), authToken(t, 6)
+
+	path := offer(t, deps, 5, "bob@example.com")
+	if w := doReq(deps.s, http.MethodPost, path+"/decline", "", bob); w.Code != http.StatusOK {
+		t.Fatalf("decline want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, path+"/accept", "", bob); w.Code != http.StatusConflict {
+		t.Fatalf("accept after decline want 409, got %d", w.Code)
+	}
+
+	path = offer(t, deps, 5, "bob@example.com") // 拒絕後可以再送
+	if w := doReq(deps.s, http.MethodDelete, path, "", bob); w.Code != http.StatusNotFound {
+		t.Fatalf("recipient cancelling want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodDelete, path, "", alice); w.Code != http.StatusOK {
+		t.Fatalf("sender cancelling want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, path+"/accept", "", bob); w.Code != http.StatusConflict || !deps.rr.Pairs["5:ev"] {
+		t.Fatalf("cancelled transfer must not be accepted, got %d", w.Code)
+	}
+}
+
+func TestGroupRegistration_AtomicWithHeldSeats(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedPeople(deps) // 9 已報名，佔 1 個名額
+	ev := deps.er.Items["ev"]
+	ev.Capacity = 4
+	deps.er.Items["ev"] = ev
+	alice := authToken(t, 5)
+
+	p := decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/register/group", `{"emails":["bob@example.com","nope","BOB@example.com"]}`, alice))
+	if p.Status != http.StatusBadRequest || p.Errors["emails[1]"] == "" || p.Errors["emails[2]"] == "" {
+		t.Fatalf("want per-email validation errors, got %+v", p)
+	}
+
+	var resp struct {
+		Registrations []models.Registration
+		Invited       []models.Transfer
+	}
+	body := `{"emails":["bob@example.com","carol@example.com","dan@example.com"]}`
+	decodeStatus(t, doReq(deps.s, http.MethodPost, "/events/ev/register/group", body, alice), http.StatusCreated, &resp)
+	if len(resp.Registrations) != 1 || resp.Registrations[0].UserID != 6 || len(resp.Invited) != 2 || !deps.rr.Pairs["6:ev"] {
+		t.Fatalf("group = %+v", resp)
+	}
+
+	// 額滿（保留的名額也算）→ 整批不寫入
+	if w := doReq(deps.s, http.MethodPost, "/events/ev/register/group", `{"emails":["new@example.com","erin@example.com"]}`, alice); w.Code != http.StatusConflict {
+		t.Fatalf("full event want 409, got %d", w.Code)
+	}
+	if deps.rr.Pairs["4:ev"] {
+		t.Fatalf("failed group must not register anyone")
+	}
+	ev.Capacity = 10
+	deps.er.Items["ev"] = ev
+	p = decodeProblem(t, doReq(deps.s, http.MethodPost, "/events/ev/register/group", `{"emails":["new@example.com","bob@example.com"]}`, alice))
+	if p.Status != http.StatusConflict || !strings.Contains(p.Detail, "bob@example.com") || deps.rr.Pairs["4:ev"] {
+		t.Fatalf("already registered member want 409 naming them, got %+v", p)
+	}
+
+	// carol 註冊後接受保留的名額；dan 的名額由發起人撤回
+	deps.ur.Users["carol@example.com"] = models.User{ID: 7, Email: "carol@example.com"}
+	var inbox []models.Transfer
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/users/me/transfers", "", authToken(t, 7)), &inbox)
+	if len(inbox) != 1 || inbox[0].Kind != models.TransferGroupSeat {
+		t.Fatalf("carol's inbox = %+v", inbox)
+	}
+	base := "/events/ev/register/transfer/" + strconv.FormatInt(inbox[0].ID, 10)
+	if w := doReq(deps.s, http.MethodPost, base+"/accept", "", authToken(t, 7)); w.Code != http.StatusOK || !deps.rr.Pairs["7:ev"] {
+		t.Fatalf("accept held seat want 200, got %d %s", w.Code, w.Body.String())
+	}
+	for _, h := range resp.Invited {
+		if h.Email == "dan@example.com" {
+			if w := doReq(deps.s, http.MethodDelete, "/events/ev/register/transfer/"+strconv.FormatInt(h.ID, 10), "", alice); w.Code != http.StatusOK {
+				t.Fatalf("lead releasing held seat want 200, got %d", w.Code)
+			}
+		}
+	}
+
+	var got eventWithRSVP
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/ev", "", ""), &got)
+	if got.RSVP.Going != 3 || got.RSVP.Held != 0 || got.RSVP.Attending != 3 {
+		t.Fatalf("rsvp = %+v", got.RSVP)
+	}
+}
+
+func TestGroupRegistration_OnlyFreeEvents(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedTicketed(deps, generalTicket)
+	if w := doReq(deps.s, http.MethodPost, "/events/tk/register/group", `{"emails":["a@example.com"]}`, authToken(t, 5)); w.Code != http.StatusConflict {
+		t.Fatalf("ticketed group registration want 409, got %d", w.Code)
+	}
+}
@@ -0,0 +1,80 @@
+// 測試目的：軟刪除 / 垃圾桶 / 還原
+// 1) 擁有者刪除 → GET /events 看不到、GET /events/trash 看得到 → restore 後回來
+// 2) 其他人不能 restore（403）；admin 可以看全部垃圾桶並還原
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"restapi/models"
+)
+
+func trashList(t *testing.T, deps serverDeps, token string) []models.Event {
+	t.Helper()
+	w := doReq(deps.s, http.MethodGet, "/events/trash", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /events/trash code=%d body=%s", w.Code, w.Body.String())
+	}
+	var out []models.Event
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return out
+}
+
+func TestTrash_DeleteListRestore(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	deps.er.Items["e-t"] = models.Event{ID: "e-t", Name: "T", UserID: 3, Version: 1, DateTime: time.Now().Add(time.Hour)}
+	token := authToken(t, 3)
+
+	if w := doReq(deps.s, http.MethodDelete, "/events/e-t", "", token); w.Code != http.StatusOK {
+		t.Fatalf("delete code=%d", w.Code)
+	}
+	var list []models.Event
+	_ = json.Unmarshal(doReq(deps.s, http.MethodGet, "/events", "", "").Body.Bytes(), &list)
+	if len(list) != 0 {
+		t.Fatalf("deleted event should be hidden from GET /events")
+	}
+	if got := trashList(t, deps, token); len(got) != 1 || got[0].DeletedAt == nil {
+		t.Fatalf("want 1 trashed event, got %+v", got)
+	}
+
+	// 其他人不能還原
+	if w := doReq(deps.s, http.MethodPost, "/events/e-t/restore", "", authToken(t, 4)); w.Code != http.StatusForbidden {
+		t.Fatalf("restore by other want 403, got %d", w.Code)
+	}
+	if got := trashList(t, deps, authToken(t, 4)); len(got) != 0 {
+		t.Fatalf("other user should not see owner's trash, got %+v", got)
+	}
+
+	w := doReq(deps.s, http.MethodPost, "/events/e-t/restore", "", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore code=%d body=%s", w.Code, w.Body.String())
+	}
+	if w = doReq(deps.s, http.MethodGet, "/events/e-t", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("restored event should be readable, got %d", w.Code)
+	}
+}
+
+//admin：垃圾桶看全部、可以還原別人的事件
+func TestTrash_AdminSeesAll(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	deps.ur.Users["admin@x.com"] = models.User{ID: 50, Email: "admin@x.com", Role: models.RoleAdmin}
+	at := time.Now()
+	deps.er.Items["a"] = models.Event{ID: "a", UserID: 1, Version: 2, DeletedAt: &at}
+	deps.er.Items["b"] = models.Event{ID: "b", UserID: 2, Version: 2, DeletedAt: &at}
+
+	admin := authToken(t, 50)
+	if got := trashList(t, deps, admin); len(got) != 2 {
+		t.Fatalf("admin want 2 trashed events, got %d", len(got))
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/b/restore", "", admin); w.Code != http.StatusOK {
+		t.Fatalf("admin restore code=%d body=%s", w.Code, w.Body.String())
+	}
+	if deps.er.Items["b"].DeletedAt != nil || deps.er.Items["b"].Version != 3 {
+		t.Fatalf("unexpected after restore: %+v", deps.er.Items["b"])
+	}
+}
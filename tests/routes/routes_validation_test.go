@@ -0,0 +1,84 @@
+// 測試目的：事件與使用者的輸入驗證（models/validation.go）
+// 1) POST /events：空名稱、過去時間 → 400 + 欄位錯誤
+// 2) PUT /events/:id：更新走同一套規則
+// 3) POST /signup：email 格式、密碼強度；WithPasswordPolicy 可放寬
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"restapi/models"
+	"restapi/routes"
+	"restapi/utils"
+)
+
+//POST /events｜name 空白、dateTime 在過去 → 400，errors 帶兩個欄位
+func TestCreateEvent_Validation_400(t *testing.T) {
+	deps := setupServerWithDeps(t)
+
+	body := `{"name":"  ","location":"L","dateTime":"2001-01-01T00:00:00Z"}`
+	w := doReq(deps.s, http.MethodPost, "/events", body, authToken(t, 1))
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusBadRequest || p.Code != utils.CodeValidation {
+		t.Fatalf("unexpected: code=%d problem=%+v", w.Code, p)
+	}
+	if p.Errors["name"] == "" || p.Errors["dateTime"] == "" {
+		t.Fatalf("want name and dateTime errors, got %v", p.Errors)
+	}
+	if len(deps.er.Items) != 0 {
+		t.Fatalf("invalid event should not be persisted")
+	}
+}
+
+//PUT /events/:id｜名稱過長 → 400，原資料不變
+func TestUpdateEvent_Validation_400(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	ev := models.Event{ID: "e-1", Name: "ok", DateTime: time.Now().Add(time.Hour), UserID: 1}
+	deps.er.Items[ev.ID] = ev
+
+	body := `{"name":"` + strings.Repeat("x", models.MaxEventNameLen+1) + `","dateTime":"2030-01-01T00:00:00Z"}`
+	w := doReq(deps.s, http.MethodPut, "/events/e-1", body, authToken(t, 1))
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusBadRequest || p.Errors["name"] == "" {
+		t.Fatalf("unexpected: code=%d problem=%+v", w.Code, p)
+	}
+	if deps.er.Items["e-1"].Name != "ok" {
+		t.Fatalf("event should not be updated")
+	}
+}
+
+//POST /signup｜email 不合法、密碼太弱 → 400，兩個欄位都要回報
+func TestSignup_Validation_400(t *testing.T) {
+	deps := setupServerWithDeps(t)
+
+	w := doReq(deps.s, http.MethodPost, "/signup", `{"email":"Bob <bob@x.com>","password":"short"}`, "")
+	p := decodeProblem(t, w)
+	if w.Code != http.StatusBadRequest || p.Errors["email"] == "" || p.Errors["password"] == "" {
+		t.Fatalf("unexpected: code=%d problem=%+v", w.Code, p)
+	}
+}
+
+//WithPasswordPolicy 放寬規則後，短密碼也能註冊
+func TestSignup_CustomPasswordPolicy(t *testing.T) {
+	deps := setupServerWithDeps(t, routes.WithPasswordPolicy(models.PasswordPolicy{MinLength: 4}))
+
+	w := doReq(deps.s, http.MethodPost, "/signup", `{"email":"a@b.com","password":"abcd"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want 201, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestPasswordPolicy_Rules(t *testing.T) {
+	p := models.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+	if err := p.Validate("Abcdefg1!"); err != nil {
+		t.Fatalf("strong password rejected: %v", err)
+	}
+	for _, pw := range []string{"Abc1!", "abcdefg1!", "Abcdefgh!", "Abcdefgh1"} {
+		if err := p.Validate(pw); err == nil {
+			t.Fatalf("weak password %q accepted", pw)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// 測試目的：事件可見度（public / unlisted / private）
+// 1) unlisted：不在列表 / 搜尋，知道 id 可讀
+// 2) private：訪客 404；擁有者、受邀者（email）、有效分享 token 可讀；回應標 no-store
+// 3) 邀請管理只限擁有者；重複邀請 409；撤銷後就讀不到
+// 4) 分享 token 綁定事件、不能拿來登入
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"restapi/models"
+	"restapi/utils"
+)
+
+func seedVisibility(deps serverDeps) {
+	at, pub := mustTime("2030-01-07T10:00:00Z"), models.StatusPublished
+	deps.er.Items["pub"] = models.Event{ID: "pub", Name: "Open meetup", DateTime: at, Status: pub, UserID: 5}
+	deps.er.Items["unl"] = models.Event{ID: "unl", Name: "Unlisted meetup", DateTime: at, Status: pub, UserID: 5, Visibility: models.VisibilityUnlisted}
+	deps.er.Items["prv"] = models.Event{ID: "prv", Name: "Private meetup", DateTime: at, Status: pub, UserID: 5, Visibility: models.VisibilityPrivate}
+	deps.ur.Users["friend@example.com"] = models.User{ID: 7, Email: "friend@example.com"}
+	deps.ur.Users["other@example.com"] = models.User{ID: 8, Email: "other@example.com"}
+}
+
+func TestVisibility_ListingAndDirectRead(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedVisibility(deps)
+
+	var list []models.Event
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events", "", ""), &list)
+	if len(list) != 1 || list[0].ID != "pub" {
+		t.Fatalf("only public should be listed, got %+v", list)
+	}
+	var sr searchResp
+	decodeJSON(t, doReq(deps.s, http.MethodGet, "/events/search?q=meetup", "", ""), &sr)
+	if len(sr.Results) != 1 {
+		t.Fatalf("only public should be searchable, got %d", len(sr.Results))
+	}
+
+	if w := doReq(deps.s, http.MethodGet, "/events/unl", "", ""); w.Code != http.StatusOK || w.Header().Get("Cache-Control") == "" {
+		t.Fatalf("unlisted by id want 200 + no-store, got %d %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/prv", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("anonymous private want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/prv", "", authToken(t, 5)); w.Code != http.StatusOK {
+		t.Fatalf("owner private want 200, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/prv/register", "", authToken(t, 8)); w.Code != http.StatusNotFound {
+		t.Fatalf("uninvited register want 404, got %d", w.Code)
+	}
+}
+
+func TestVisibility_Invitations(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedVisibility(deps)
+	owner, friend := authToken(t, 5), authToken(t, 7)
+
+	if w := doReq(deps.s, http.MethodPost, "/events/prv/invitations", `{"email":"friend@example.com"}`, friend); w.Code != http.StatusForbidden {
+		t.Fatalf("non-owner invite want 403, got %d", w.Code)
+	}
+	w := doReq(deps.s, http.MethodPost, "/events/prv/invitations", `{"email":"Friend@Example.com"}`, owner)
+	var resp struct{ Invitation models.Invitation `json:"invitation"` }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || resp.Invitation.Email != "friend@example.com" {
+		t.Fatalf("invite code=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/prv/invitations", `{"email":"friend@example.com"}`, owner); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate invite want 409, got %d", w.Code)
+	}
+
+	w = doReq(deps.s, http.MethodGet, "/events/prv", "", friend)
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("invited read want 200 + no-store, got %d %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/prv", "", authToken(t, 8)); w.Code != http.StatusNotFound {
+		t.Fatalf("other user want 404, got %d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodPost, "/events/prv/register", "", friend); w.Code != http.StatusCreated {
+		t.Fatalf("invited register want 201, got %d", w.Code)
+	}
+
+	path := "/events/prv/invitations/" + strconv.FormatInt(resp.Invitation.ID, 10)
+	if w := doReq(deps.s, http.MethodDelete, path, "", owner); w.Code != http.StatusOK {
+		t.Fatalf("revoke code=%d", w.Code)
+	}
+	if w := doReq(deps.s, http.MethodGet, "/events/prv", "", friend); w.Code != http.StatusNotFound {
+		t.Fatalf("after revoke want 404, got %d", w.Code)
+	}
+}
+
+func TestVisibility_ShareToken(t *testing.T) {
+	deps := setupServerWithDeps(t)
+	seedVisibility(deps)
+
+	if w := doReq(deps.s, http.MethodPost, "/events/prv/share-links", `{"expiresIn":"9999h"}`, authToken(t, 5)); w.Code != http.StatusBadRequest {
+		t.Fatalf("too long ttl want 400, got %d", w.Code)
+	}
+	w := doReq(deps.s, http.MethodPost, "/events/prv/share-links", `{"expiresIn":"1h"}`, authToken(t, 5))
+	var link struct {
+		Token string `json:"token"`
+		Path  string `json:"path"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &link)
+	if w.Code != http.StatusCreated || link.Token == "" {
+		t.Fatalf("share code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	if w := doReq(deps.s, http.MethodGet, link.Path, "", ""); w.Code != http.StatusOK {
+		t.Fatalf("share link read want 200, got %d", w.Code)
+	}
+	// 綁定事件：拿去讀別的 private 事件無效
+	deps.er.Items["prv2"] = models.Event{ID: "prv2", Name: "x", DateTime: mustTime("2030-01-07T10:00:00Z"), UserID: 5, Visibility: models.VisibilityPrivate}
+	if w := doReq(deps.s, http.MethodGet, "/events/prv2?share="+link.Token, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("token for another event want 404, got %d", w.Code)
+	}
+	// 不能當登入 token
+	if _, err := utils.VerifyToken(link.Token); err == nil {
+		t.Fatalf("share token must not authenticate")
+	}
+	// 過期
+	expired, _, _ := utils.GenerateShareToken("prv", -1)
+	if w := doReq(deps.s, http.MethodGet, "/events/prv?share="+expired, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expired token want 404, got %d", w.Code)
+	}
+}
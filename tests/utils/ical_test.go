@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"restapi/utils"
+)
+
+func TestICalText_Escapes(t *testing.T) {
+	got := utils.ICalText("a,b;c\\d\r\ne\nf")
+	if want := `a\,b\;c\\d\ne\nf`; got != want {
+		t.Fatalf("ICalText = %q, want %q", got, want)
+	}
+}
+
+// 每行（不含 CRLF）不超過 75 octets、不切在 UTF-8 字元中間；去掉折行後還原原本的值
+func TestICalendar_FoldsLongLines(t *testing.T) {
+	start := time.Date(2030, 1, 7, 18, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	desc := strings.Repeat("台北活動說明 ", 30)
+	cal := utils.ICalendar{Name: "My events", Refresh: time.Hour, Events: []utils.ICalEvent{{
+		UID: "ev", Start: start, Summary: "Meetup", Description: desc, Status: utils.ICalConfirmed,
+	}}}
+	out := string(cal.Bytes(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Fatalf("lines must end with CRLF: %q", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 || !utf8.ValidString(line) {
+			t.Fatalf("bad folded line (%d octets): %q", len(line), line)
+		}
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"DESCRIPTION:" + utils.ICalText(desc) + "\r\n",
+		"DTSTART:20300107T100000Z\r\n", // 一律轉成 UTC
+		"DTSTAMP:20300101T000000Z\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT60M\r\n",
+		"X-WR-CALNAME:My events\r\n",
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Fatalf("missing %q in %q", want, unfolded)
+		}
+	}
+}
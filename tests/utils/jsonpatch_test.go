@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"restapi/utils"
+)
+
+func jsonEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var a, b any
+	_ = json.Unmarshal(got, &a)
+	_ = json.Unmarshal([]byte(want), &b)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+//RFC 7396：null 刪除、物件遞迴合併、陣列整個取代
+func TestMergePatch(t *testing.T) {
+	out, err := utils.MergePatch(
+		[]byte(`{"a":"b","c":{"d":"e","f":"g"},"l":[1,2]}`),
+		[]byte(`{"a":"z","c":{"f":null},"l":[3]}`))
+	if err != nil { t.Fatalf("merge: %v", err) }
+	jsonEqual(t, out, `{"a":"z","c":{"d":"e"},"l":[3]}`)
+}
+
+//RFC 6902：add/remove/replace/move/copy/test
+func TestApplyJSONPatch(t *testing.T) {
+	doc := []byte(`{"foo":["bar","baz"],"x":{"y":1}}`)
+	patch := []byte(`[
+		{"op":"add","path":"/foo/1","value":"qux"},
+		{"op":"remove","path":"/foo/0"},
+		{"op":"replace","path":"/x/y","value":2},
+		{"op":"copy","from":"/x","path":"/z"},
+		{"op":"move","from":"/foo/-1","path":"/w"},
+		{"op":"test","path":"/z/y","value":2}
+	]`)
+	// "/foo/-1" 不是合法 index → 整包失敗
+	if _, err := utils.ApplyJSONPatch(doc, patch); !errors.Is(err, utils.ErrInvalidPatch) {
+		t.Fatalf("want ErrInvalidPatch, got %v", err)
+	}
+
+	patch = []byte(`[
+		{"op":"add","path":"/foo/1","value":"qux"},
+		{"op":"remove","path":"/foo/0"},
+		{"op":"replace","path":"/x/y","value":2},
+		{"op":"copy","from":"/x","path":"/z"},
+		{"op":"move","from":"/foo/1","path":"/w"},
+		{"op":"add","path":"/foo/-","value":"end"},
+		{"op":"test","path":"/z/y","value":2}
+	]`)
+	out, err := utils.ApplyJSONPatch(doc, patch)
+	if err != nil { t.Fatalf("patch: %v", err) }
+	jsonEqual(t, out, `{"foo":["qux","end"],"x":{"y":2},"z":{"y":2},"w":"baz"}`)
+
+	_, err = utils.ApplyJSONPatch(doc, []byte(`[{"op":"test","path":"/x/y","value":5}]`))
+	if !errors.Is(err, utils.ErrPatchTestFailed) {
+		t.Fatalf("want ErrPatchTestFailed, got %v", err)
+	}
+}
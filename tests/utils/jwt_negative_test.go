@@ -8,7 +8,7 @@ import (
 
 //把 token 竄改後必須驗證失敗（涵蓋 JWT 驗證的錯誤支線）
 func TestVerifyToken_Tampered_Fails(t *testing.T) {
-	tok, err := utils.GenerateToken("x@x.com", 99)
+	tok, err := utils.GenerateToken("x@x.com", 99, 1)
 	if err != nil { t.Fatalf("gen: %v", err) }
 
 	// 竄改 payload（簡單替換字元破壞簽章）
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"restapi/utils"
+)
+
+// 版本依資料長度遞增（等級 M、byte 模式的容量上限）
+func TestEncodeQR_PicksSmallestVersion(t *testing.T) {
+	for _, tc := range []struct{ n, version int }{{14, 1}, {15, 2}, {213, 10}, {214, 11}, {412, 15}} {
+		q, err := utils.EncodeQR(bytes.Repeat([]byte("a"), tc.n))
+		if err != nil { t.Fatalf("%d bytes: %v", tc.n, err) }
+		if q.Version != tc.version || q.Size != 17+4*tc.version {
+			t.Fatalf("%d bytes: version %d size %d, want version %d", tc.n, q.Version, q.Size, tc.version)
+		}
+	}
+	if _, err := utils.EncodeQR(make([]byte, 413)); !errors.Is(err, utils.ErrQRTooLong) {
+		t.Fatalf("want ErrQRTooLong, got %v", err)
+	}
+}
+
+// 三個角的 finder pattern、timing 與固定的深色模組
+func TestEncodeQR_FunctionPatterns(t *testing.T) {
+	q, err := utils.EncodeQR([]byte("https://example.com/ticket"))
+	if err != nil { t.Fatal(err) }
+	n := q.Size
+	for _, corner := range [][2]int{{0, 0}, {n - 7, 0}, {0, n - 7}} {
+		for i := 0; i < 7; i++ {
+			x, y := corner[0], corner[1]
+			if !q.Dark(x+i, y) || !q.Dark(x, y+i) || !q.Dark(x+6, y+i) || !q.Dark(x+i, y+6) { t.Fatalf("finder border at %v", corner) }
+			if q.Dark(x+1, y+1+i%5) || !q.Dark(x+2+i%3, y+2+i%3) { t.Fatalf("finder inside at %v", corner) }
+		}
+	}
+	for i := 8; i < n-8; i++ {
+		if q.Dark(i, 6) != (i%2 == 0) || q.Dark(6, i) != (i%2 == 0) { t.Fatalf("timing pattern broken at %d", i) }
+	}
+	if !q.Dark(8, n-8) { t.Fatalf("dark module missing") }
+	if q.Dark(-1, 0) || q.Dark(n, n) { t.Fatalf("outside the symbol must be light") }
+}
+
+func TestEncodeQR_Rendering(t *testing.T) {
+	q, err := utils.EncodeQR([]byte("hello"))
+	if err != nil { t.Fatal(err) }
+	b, err := q.PNG(3)
+	if err != nil { t.Fatal(err) }
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil { t.Fatal(err) }
+	if want := (q.Size + 8) * 3; img.Bounds().Dx() != want || img.Bounds().Dy() != want {
+		t.Fatalf("png size %v, want %d (with quiet zone)", img.Bounds(), want)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 { t.Fatalf("quiet zone must be white") }
+	if r, _, _, _ := img.At(4*3, 4*3).RGBA(); r != 0 { t.Fatalf("finder corner must be black") }
+
+	svg := string(q.SVG())
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `viewBox="0 0 29 29"`) || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatalf("svg = %s", svg)
+	}
+}
+
+// 票券 token：竄改、以及拿別種 token 來冒充都要失敗
+func TestTicketToken(t *testing.T) {
+	claims := utils.TicketClaims{RegistrationID: 7, UserID: 5, EventID: "ev", Occurrence: "20300107T100000Z"}
+	tok, err := utils.GenerateTicketToken(claims)
+	if err != nil { t.Fatal(err) }
+	got, err := utils.VerifyTicketToken(tok)
+	if err != nil || got != claims { t.Fatalf("verify = %+v, %v", got, err) }
+
+	if _, err := utils.VerifyTicketToken(tok[:len(tok)-2] + "xx"); !errors.Is(err, utils.ErrInvalidTicket) {
+		t.Fatalf("tampered ticket must fail, got %v", err)
+	}
+	share, _, _ := utils.GenerateShareToken("ev", 0)
+	login, _ := utils.GenerateToken("x@example.com", 5, 1)
+	for _, other := range []string{share, login} {
+		if _, err := utils.VerifyTicketToken(other); err == nil { t.Fatalf("non-ticket token accepted") }
+	}
+	// 反過來，票券也不能拿來登入
+	if _, err := utils.VerifyToken(tok); err == nil { t.Fatalf("ticket must not work as a login token") }
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"restapi/utils"
+)
+
+func mustRule(t *testing.T, s string) *utils.RRule {
+	t.Helper()
+	r, err := utils.ParseRRule(s)
+	if err != nil { t.Fatalf("parse %q: %v", s, err) }
+	return r
+}
+
+func dates(ts []time.Time) []string {
+	out := make([]string, 0, len(ts))
+	for _, t := range ts { out = append(out, t.Format("2006-01-02")) }
+	return out
+}
+
+func eq(t *testing.T, got []time.Time, want ...string) {
+	t.Helper()
+	g := dates(got)
+	if len(g) != len(want) { t.Fatalf("got %v want %v", g, want) }
+	for i := range want {
+		if g[i] != want[i] { t.Fatalf("got %v want %v", g, want) }
+	}
+}
+
+var (
+	rrStart = time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC) // 週一
+	rrFar   = time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+//WEEKLY + BYDAY + COUNT：從 DTSTART 開始算次數
+func TestRRule_WeeklyByDayCount(t *testing.T) {
+	r := mustRule(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4")
+	eq(t, r.Between(rrStart, rrStart, rrFar, 0), "2030-01-07", "2030-01-09", "2030-01-14", "2030-01-16")
+}
+
+//INTERVAL + UNTIL（含當天）
+func TestRRule_DailyIntervalUntil(t *testing.T) {
+	r := mustRule(t, "FREQ=DAILY;INTERVAL=2;UNTIL=20300111T100000Z")
+	eq(t, r.Between(rrStart, rrStart, rrFar, 0), "2030-01-07", "2030-01-09", "2030-01-11")
+}
+
+//MONTHLY：最後一個週五、每月 31 號（沒有 31 號的月份略過）
+func TestRRule_Monthly(t *testing.T) {
+	r := mustRule(t, "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+	eq(t, r.Between(rrStart, rrStart, rrFar, 0), "2030-01-25", "2030-02-22", "2030-03-29")
+
+	jan31 := time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC)
+	r = mustRule(t, "FREQ=MONTHLY;COUNT=3")
+	eq(t, r.Between(jan31, jan31, rrFar, 0), "2030-01-31", "2030-03-31", "2030-05-31")
+}
+
+//Between 只回範圍內；Contains 判斷單次
+func TestRRule_RangeAndContains(t *testing.T) {
+	r := mustRule(t, "RRULE:FREQ=WEEKLY")
+	from := time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2030, 2, 15, 0, 0, 0, 0, time.UTC)
+	eq(t, r.Between(rrStart, from, to, 0), "2030-02-04", "2030-02-11")
+
+	if !r.Contains(rrStart, rrStart.AddDate(0, 0, 21)) { t.Fatalf("3 weeks later should be an occurrence") }
+	if r.Contains(rrStart, rrStart.AddDate(0, 0, 1)) { t.Fatalf("next day is not an occurrence") }
+}
+
+//不支援或矛盾的規則 → ErrInvalidRRule
+func TestRRule_Invalid(t *testing.T) {
+	for _, s := range []string{"", "FREQ=HOURLY", "INTERVAL=2", "FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20300101", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;BYSETPOS=1"} {
+		if _, err := utils.ParseRRule(s); !errors.Is(err, utils.ErrInvalidRRule) {
+			t.Fatalf("%q: want ErrInvalidRRule, got %v", s, err)
+		}
+	}
+}
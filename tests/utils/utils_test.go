@@ -19,9 +19,18 @@ func TestHashAndCheckPassword(t *testing.T) {
 
 //能成功產生 JWT，VerifyToken 解析出正確 userId（200/成功路徑的核心）。
 func TestJWTGenerateAndVerify(t *testing.T) {
-	token, err := utils.GenerateToken("a@b.com", 87)
+	token, err := utils.GenerateToken("a@b.com", 87, 1)
 	if err != nil { t.Fatalf("gen token err: %v", err) }
 	uid, err := utils.VerifyToken(token)
 	if err != nil { t.Fatalf("verify err: %v", err) }
 	if uid != 87 { t.Fatalf("want 87 got %d", uid) }
 }
+
+//token 帶簽發時的組織（tenant），ParseToken 一起取出
+func TestJWTCarriesOrgID(t *testing.T) {
+	token, err := utils.GenerateToken("a@b.com", 87, 42)
+	if err != nil { t.Fatalf("gen token err: %v", err) }
+	claims, err := utils.ParseToken(token)
+	if err != nil { t.Fatalf("parse err: %v", err) }
+	if claims.UserID != 87 || claims.OrgID != 42 { t.Fatalf("claims = %+v", claims) }
+}
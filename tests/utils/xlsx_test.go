@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+
+	"restapi/utils"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := utils.XLSXColumn(i); got != want {
+			t.Fatalf("XLSXColumn(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+// 產生的檔案是 zip、每個 part 都是合法 XML；特殊字元會被跳脫、空白儲存格不寫
+func TestXLSXWriter_ValidPackage(t *testing.T) {
+	var buf bytes.Buffer
+	x, err := utils.NewXLSXWriter(&buf, "Attendees: [all]")
+	if err != nil { t.Fatal(err) }
+	if err := x.WriteRow([]string{"a<b", "", "x & \"y\"\x01"}); err != nil { t.Fatal(err) }
+	if err := x.WriteRow([]string{"second"}); err != nil { t.Fatal(err) }
+	if err := x.Close(); err != nil { t.Fatal(err) }
+	if err := x.WriteRow([]string{"late"}); !errors.Is(err, utils.ErrXLSXClosed) {
+		t.Fatalf("write after close want ErrXLSXClosed, got %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil { t.Fatal(err) }
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		parts[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+		dec := xml.NewDecoder(bytes.NewReader(parts[f.Name]))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if parts[name] == nil { t.Fatalf("missing part %s", name) }
+	}
+	if !bytes.Contains(parts["xl/workbook.xml"], []byte(`name="Attendees_ _all_"`)) {
+		t.Fatalf("sheet name not sanitized: %s", parts["xl/workbook.xml"])
+	}
+
+	var sheet struct {
+		Rows []struct {
+			R     string `xml:"r,attr"`
+			Cells []struct {
+				R    string `xml:"r,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil { t.Fatal(err) }
+	if len(sheet.Rows) != 2 || len(sheet.Rows[0].Cells) != 2 || sheet.Rows[1].R != "2" {
+		t.Fatalf("rows = %+v", sheet.Rows)
+	}
+	c := sheet.Rows[0].Cells
+	if c[0].R != "A1" || c[0].Text != "a<b" || c[1].R != "C1" || c[1].Text != "x & \"y\"�" {
+		t.Fatalf("cells = %+v", c)
+	}
+}
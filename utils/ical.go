@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar（RFC 5545）輸出：只有 VEVENT，時間一律用 UTC（...Z），不需要 VTIMEZONE。
+// 重複事件由呼叫端展開成一筆一筆的 VEVENT，不輸出 RRULE（這樣時區與夏令時間的處理跟 API 一致）
+
+const icalTimeLayout = "20060102T150405Z"
+
+const icalMaxLine = 75 // octets，不含 CRLF
+
+type ICalEvent struct {
+	UID         string
+	Sequence    int64 // 每次修改遞增，讓行事曆 app 知道要更新
+	Start       time.Time
+	End         *time.Time
+	Summary     string
+	Description string
+	Location    string
+	Geo         *[2]float64 // [lat, lng]
+	URL         string
+	Categories  []string
+	Status      string // TENTATIVE / CONFIRMED / CANCELLED
+}
+
+const (
+	ICalTentative = "TENTATIVE"
+	ICalConfirmed = "CONFIRMED"
+	ICalCancelled = "CANCELLED"
+)
+
+type ICalendar struct {
+	Name    string        // X-WR-CALNAME，行事曆 app 顯示的名稱
+	Refresh time.Duration // > 0 → 建議訂閱的更新頻率（REFRESH-INTERVAL / X-PUBLISHED-TTL）
+	Events  []ICalEvent
+}
+
+// Bytes 以 CRLF 分行、超過 75 octets 折行；now 是 DTSTAMP
+func (cal ICalendar) Bytes(now time.Time) []byte {
+	var b strings.Builder
+	w := func(name, value string) { icalFold(&b, name+":"+value) }
+
+	w("BEGIN", "VCALENDAR")
+	w("VERSION", "2.0")
+	w("PRODID", "-//restapi//events//EN")
+	w("CALSCALE", "GREGORIAN")
+	w("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		w("X-WR-CALNAME", ICalText(cal.Name))
+	}
+	if cal.Refresh > 0 {
+		d := "PT" + strconv.Itoa(int(cal.Refresh.Minutes())) + "M"
+		w("REFRESH-INTERVAL;VALUE=DURATION", d)
+		w("X-PUBLISHED-TTL", d)
+	}
+	stamp := now.UTC().Format(icalTimeLayout)
+	for _, e := range cal.Events {
+		w("BEGIN", "VEVENT")
+		w("UID", e.UID)
+		w("DTSTAMP", stamp)
+		w("SEQUENCE", strconv.FormatInt(e.Sequence, 10))
+		w("DTSTART", e.Start.UTC().Format(icalTimeLayout))
+		if e.End != nil {
+			w("DTEND", e.End.UTC().Format(icalTimeLayout))
+		}
+		w("SUMMARY", ICalText(e.Summary))
+		if e.Description != "" {
+			w("DESCRIPTION", ICalText(e.Description))
+		}
+		if e.Location != "" {
+			w("LOCATION", ICalText(e.Location))
+		}
+		if e.Geo != nil {
+			w("GEO", strconv.FormatFloat(e.Geo[0], 'f', -1, 64)+";"+strconv.FormatFloat(e.Geo[1], 'f', -1, 64))
+		}
+		if e.URL != "" {
+			w("URL", e.URL)
+		}
+		if len(e.Categories) > 0 {
+			cats := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				cats[i] = ICalText(c)
+			}
+			w("CATEGORIES", strings.Join(cats, ","))
+		}
+		if e.Status != "" {
+			w("STATUS", e.Status)
+		}
+		w("END", "VEVENT")
+	}
+	w("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// ICalText 跳脫 TEXT 值：\ ; , 與換行
+func ICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// icalFold 一行最多 75 octets，續行以空白開頭；不切在 UTF-8 字元中間
+func icalFold(b *strings.Builder, line string) {
+	limit := icalMaxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalMaxLine - 1 // 續行開頭的空白也算
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
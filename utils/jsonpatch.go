@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PATCH 用的兩種 Content-Type
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+var (
+	ErrInvalidPatch    = errors.New("invalid patch")
+	ErrPatchTestFailed = errors.New("patch test operation failed")
+)
+
+/* -------------------- RFC 7396 JSON Merge Patch -------------------- */
+
+// MergePatch 把 patch 合併進 doc：物件遞迴合併、null 代表刪除、其他值直接取代
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var d, p any
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergeValue(d, p))
+}
+
+func mergeValue(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch // 非物件 → 整個取代
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = map[string]any{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+			continue
+		}
+		tm[k] = mergeValue(tm[k], v)
+	}
+	return tm
+}
+
+/* -------------------- RFC 6902 JSON Patch -------------------- */
+
+type patchOp struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch 依序套用 add/remove/replace/move/copy/test，任何一步失敗整包不生效
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []patchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	var d any
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: op %d (%s) missing value", ErrInvalidPatch, i, op.Op)
+			}
+			var v any
+			if err := json.Unmarshal(*op.Value, &v); err != nil {
+				return nil, fmt.Errorf("%w: op %d: %v", ErrInvalidPatch, i, err)
+			}
+			switch op.Op {
+			case "add":
+				d, err = addAt(d, op.Path, v)
+			case "replace":
+				if _, err = getAt(d, op.Path); err == nil {
+					if d, err = removeAt(d, op.Path); err == nil {
+						d, err = addAt(d, op.Path, v)
+					}
+				}
+			case "test":
+				var cur any
+				if cur, err = getAt(d, op.Path); err == nil && !reflect.DeepEqual(cur, v) {
+					return nil, fmt.Errorf("%w: %s", ErrPatchTestFailed, op.Path)
+				}
+			}
+		case "remove":
+			d, err = removeAt(d, op.Path)
+		case "move", "copy":
+			var v any
+			if v, err = getAt(d, op.From); err == nil {
+				if op.Op == "move" {
+					if strings.HasPrefix(op.Path, op.From+"/") {
+						return nil, fmt.Errorf("%w: op %d cannot move into its own child", ErrInvalidPatch, i)
+					}
+					d, err = removeAt(d, op.From)
+				} else {
+					v = deepCopy(v)
+				}
+				if err == nil {
+					d, err = addAt(d, op.Path, v)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%w: op %d unknown operation %q", ErrInvalidPatch, i, op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: op %d (%s %s): %v", ErrInvalidPatch, i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(d)
+}
+
+// splitPointer 解析 RFC 6901 JSON Pointer："" 代表整份文件
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("pointer %q must start with /", ptr)
+	}
+	parts := strings.Split(ptr[1:], "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+func arrayIndex(tok string, n int, allowEnd bool) (int, error) {
+	if allowEnd && tok == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && strings.HasPrefix(tok, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	max := n - 1
+	if allowEnd {
+		max = n
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func getAt(doc any, ptr string) (any, error) {
+	parts, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, tok := range parts {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[tok]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", ptr)
+			}
+			cur = v
+		case []any:
+			i, err := arrayIndex(tok, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("path %q not found", ptr)
+		}
+	}
+	return cur, nil
+}
+
+// addAt / removeAt 回傳新的根節點（根或陣列長度可能改變）
+func addAt(doc any, ptr string, v any) (any, error) {
+	parts, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return v, nil
+	}
+	return updateParent(doc, parts, func(parent any, tok string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[tok] = v
+			return node, nil
+		case []any:
+			i, err := arrayIndex(tok, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = v
+			return node, nil
+		}
+		return nil, fmt.Errorf("path %q not found", ptr)
+	})
+}
+
+func removeAt(doc any, ptr string) (any, error) {
+	parts, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return updateParent(doc, parts, func(parent any, tok string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[tok]; !ok {
+				return nil, fmt.Errorf("path %q not found", ptr)
+			}
+			delete(node, tok)
+			return node, nil
+		case []any:
+			i, err := arrayIndex(tok, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("path %q not found", ptr)
+	})
+}
+
+// updateParent 走到最後一段的父節點，交給 fn 修改後再一路寫回
+func updateParent(doc any, parts []string, fn func(parent any, tok string) (any, error)) (any, error) {
+	if len(parts) == 1 {
+		return fn(doc, parts[0])
+	}
+	tok := parts[0]
+	switch node := doc.(type) {
+	case map[string]any:
+		child, ok := node[tok]
+		if !ok {
+			return nil, fmt.Errorf("path segment %q not found", tok)
+		}
+		nc, err := updateParent(child, parts[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[tok] = nc
+		return node, nil
+	case []any:
+		i, err := arrayIndex(tok, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		nc, err := updateParent(node[i], parts[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = nc
+		return node, nil
+	}
+	return nil, fmt.Errorf("path segment %q not found", tok)
+}
+
+func deepCopy(v any) any {
+	b, _ := json.Marshal(v)
+	var out any
+	_ = json.Unmarshal(b, &out)
+	return out
+}
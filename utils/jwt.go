@@ -9,18 +9,32 @@ import (
 
 const secretKey = "supersecret"
 
-func GenerateToken(email string, userId int64) (string, error) {
+// orgId：登入時所在的組織（tenant），token 只在這個組織有效
+func GenerateToken(email string, userId, orgId int64) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"email": email,
 		"userId": userId,
+		"orgId": orgId,
 		"exp": time.Now().Add(time.Hour * 2).Unix(), 
 	})
 
 	return token.SignedString([]byte(secretKey))
 }
 
+// TokenClaims 登入 token 裡我們用得到的欄位；OrgID=0 → 舊 token（沒有 orgId claim）
+type TokenClaims struct {
+	UserID int64
+	OrgID  int64
+}
+
 //驗證token + 回傳id
 func VerifyToken(token string) (int64, error) {
+	claims, err := ParseToken(token)
+	return claims.UserID, err
+}
+
+// ParseToken 驗證 token，回傳 userId 與 orgId
+func ParseToken(token string) (TokenClaims, error) {
 	
 	//檢驗token
 	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
@@ -34,13 +48,13 @@ func VerifyToken(token string) (int64, error) {
 		return []byte(secretKey), nil
 	}) 
 	if err != nil {
-		return 0, errors.New("Could not parse token.")
+		return TokenClaims{}, errors.New("Could not parse token.")
 	}
 
 	//就算簽章正確，Token 也不一定「有效」 (可能過期)
 	tokenIsValid := parsedToken.Valid
 	if !tokenIsValid {
-		return 0, errors.New("Invalid token!")
+		return TokenClaims{}, errors.New("Invalid token!")
 	}
 
 	
@@ -49,14 +63,17 @@ func VerifyToken(token string) (int64, error) {
 	//轉型成 jwt.MapClaims（map 格式）好存取 //map[string]interface{}
 	Claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, errors.New("Invalid token claims")
+		return TokenClaims{}, errors.New("Invalid token claims")
 	}
 	// email := Claims["email"].(string)
-	userId := int64(Claims["userId"].(float64))
-
-	
+	// 分享 token（typ=share）沒有 userId，不能拿來登入
+	uid, ok := Claims["userId"].(float64)
+	if !ok || Claims["typ"] != nil {
+		return TokenClaims{}, errors.New("Invalid token claims")
+	}
+	orgId, _ := Claims["orgId"].(float64)
 
-	return userId, nil
+	return TokenClaims{UserID: int64(uid), OrgID: int64(orgId)}, nil
 }
 
 
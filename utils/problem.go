@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RFC 7807 錯誤格式：所有錯誤回應都用 application/problem+json
+const ProblemContentType = "application/problem+json"
+
+// 穩定的機器可讀錯誤碼（前端依 code 判斷，不要依 detail 文字）
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidation         = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeRateLimited        = "rate_limited"
+	CodeQuotaExceeded      = "quota_exceeded"
+	CodePaymentFailed      = "payment_failed"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeInternal           = "internal_error"
+)
+
+// RequestIDKey 是 request id 在 gin.Context 裡的 key（由 middlewares.RequestID 放入）
+const RequestIDKey = "requestId"
+
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Code     string            `json:"code"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"` // request id
+	Errors   map[string]string `json:"errors,omitempty"`   // 欄位層級錯誤（validation）
+	Current  any               `json:"current,omitempty"`  // 版本衝突時附上目前的資料
+}
+
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank", // 沒有專屬說明頁 → title 用 HTTP 狀態文字
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// WriteProblem 寫出 problem+json 並中止後續 handler
+func WriteProblem(c *gin.Context, p Problem) {
+	if p.Instance == "" {
+		p.Instance = c.GetString(RequestIDKey)
+	}
+	c.Header("Content-Type", ProblemContentType) // 先設好，gin 的 JSON render 就不會覆蓋
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// AbortWithProblem 是 WriteProblem(NewProblem(...)) 的簡寫，middleware 常用
+func AbortWithProblem(c *gin.Context, status int, code, detail string) {
+	WriteProblem(c, NewProblem(status, code, detail))
+}
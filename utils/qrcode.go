@@ -0,0 +1,515 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// QR code 編碼器（ISO/IEC 18004 的子集）：byte 模式、錯誤更正等級 M、版本 1~15（最多 412 bytes）。
+// 票券的簽章 payload 約 200 bytes，夠用；不想為了這個多一個相依套件
+
+var ErrQRTooLong = errors.New("qr: data too long")
+
+const qrQuietZone = 4 // 四周留白（模組數），規格要求至少 4
+
+// 等級 M 每個版本的區塊配置：每區塊 EC codeword 數、兩組區塊的（數量, data codeword 數）
+var qrBlocksM = [...]struct{ ec, n1, d1, n2, d2 int }{
+	{}, // 版本從 1 開始
+	{10, 1, 16, 0, 0},
+	{16, 1, 28, 0, 0},
+	{26, 1, 44, 0, 0},
+	{18, 2, 32, 0, 0},
+	{24, 2, 43, 0, 0},
+	{16, 4, 27, 0, 0},
+	{18, 4, 31, 0, 0},
+	{22, 2, 38, 2, 39},
+	{22, 3, 36, 2, 37},
+	{26, 4, 43, 1, 44},
+	{30, 1, 50, 4, 51},
+	{22, 6, 36, 2, 37},
+	{22, 8, 37, 1, 38},
+	{24, 4, 40, 5, 41},
+	{24, 5, 41, 5, 42},
+}
+
+// 對齊圖案中心座標（版本 2 起）
+var qrAlignment = [...][]int{
+	{}, {},
+	{6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+	{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50}, {6, 30, 54}, {6, 32, 58}, {6, 34, 62},
+	{6, 26, 46, 66}, {6, 26, 48, 70},
+}
+
+// QRCode 編好的矩陣（不含留白）
+type QRCode struct {
+	Version int
+	Size    int
+	modules []bool // true = 深色
+	fixed   []bool // 功能圖案（finder / timing / 對齊 / 格式資訊），遮罩不套用
+}
+
+// Dark 座標 (x, y) 是不是深色；超出範圍（留白）→ false
+func (q *QRCode) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= q.Size || y >= q.Size {
+		return false
+	}
+	return q.modules[y*q.Size+x]
+}
+
+// EncodeQR 把 data 編成 QR code，自動選最小的版本與罰分最低的遮罩
+func EncodeQR(data []byte) (*QRCode, error) {
+	version := 0
+	for v := 1; v < len(qrBlocksM); v++ {
+		if qrDataBits(len(data), v) <= 8*qrDataCodewords(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrQRTooLong, len(data))
+	}
+
+	codewords := qrInterleave(version, qrDataStream(data, version))
+	base := newQRMatrix(version)
+	base.placeData(codewords)
+
+	var best *QRCode
+	bestScore := -1
+	for mask := 0; mask < 8; mask++ {
+		q := base.clone()
+		q.applyMask(mask)
+		q.placeFormat(mask)
+		if s := q.penalty(); bestScore < 0 || s < bestScore {
+			best, bestScore = q, s
+		}
+	}
+	return best, nil
+}
+
+// PNG 每個模組 scale×scale 像素，含留白
+func (q *QRCode) PNG(scale int) ([]byte, error) {
+	scale = max(scale, 1)
+	n := (q.Size + 2*qrQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, n, n), color.Palette{color.White, color.Black})
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if q.Dark(x/scale-qrQuietZone, y/scale-qrQuietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG 以模組為單位的 viewBox（可任意縮放），每一列連續的深色模組合併成一段 path
+func (q *QRCode) SVG() []byte {
+	n := q.Size + 2*qrQuietZone
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; {
+			if !q.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < q.Size && q.Dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start+qrQuietZone, y+qrQuietZone, x-start, x-start)
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return []byte(b.String())
+}
+
+/* -------------------- 資料編碼 -------------------- */
+
+func qrDataCodewords(v int) int {
+	b := qrBlocksM[v]
+	return b.n1*b.d1 + b.n2*b.d2
+}
+
+// 字數欄位：版本 1~9 是 8 bits，10 以上 16 bits
+func qrCountBits(v int) int {
+	if v < 10 {
+		return 8
+	}
+	return 16
+}
+
+func qrDataBits(n, v int) int { return 4 + qrCountBits(v) + 8*n }
+
+type bitWriter struct {
+	buf []byte
+	n   int // 已寫入的 bit 數
+}
+
+func (w *bitWriter) write(v uint, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if v>>uint(i)&1 == 1 {
+			w.buf[w.n/8] |= 0x80 >> uint(w.n%8)
+		}
+		w.n++
+	}
+}
+
+// qrDataStream 模式指示 + 字數 + 資料 + 結束符號 + 補齊到 data codeword 數
+func qrDataStream(data []byte, v int) []byte {
+	capacity := qrDataCodewords(v)
+	w := &bitWriter{}
+	w.write(0b0100, 4) // byte 模式
+	w.write(uint(len(data)), qrCountBits(v))
+	for _, c := range data {
+		w.write(uint(c), 8)
+	}
+	w.write(0, min(4, 8*capacity-w.n))
+	if w.n%8 != 0 {
+		w.write(0, 8-w.n%8)
+	}
+	for pad := byte(0xEC); len(w.buf) < capacity; pad ^= 0xEC ^ 0x11 {
+		w.buf = append(w.buf, pad)
+	}
+	return w.buf
+}
+
+// qrInterleave 分區塊算 Reed-Solomon，再依規格交錯排列
+func qrInterleave(v int, data []byte) []byte {
+	b := qrBlocksM[v]
+	var blocks, ecs [][]byte
+	for i := 0; i < b.n1+b.n2; i++ {
+		size := b.d1
+		if i >= b.n1 {
+			size = b.d2
+		}
+		blocks = append(blocks, data[:size])
+		ecs = append(ecs, rsEncode(data[:size], b.ec))
+		data = data[size:]
+	}
+	out := make([]byte, 0, qrDataCodewords(v)+b.ec*len(blocks))
+	for i := 0; i < max(b.d1, b.d2); i++ {
+		for _, blk := range blocks {
+			if i < len(blk) {
+				out = append(out, blk[i])
+			}
+		}
+	}
+	for i := 0; i < b.ec; i++ {
+		for _, ec := range ecs {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+/* -------------------- Reed-Solomon（GF(256)，x^8+x^4+x^3+x^2+1）-------------------- */
+
+var gfExp, gfLog [256]byte
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	gfExp[255] = gfExp[0]
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+// rsEncode 回傳 n 個錯誤更正 codeword
+func rsEncode(data []byte, n int) []byte {
+	// 生成多項式 (x - α^0)(x - α^1)...(x - α^(n-1))，最高次係數在前
+	gen := []byte{1}
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(gen)+1)
+		for j, g := range gen {
+			next[j] ^= g
+			next[j+1] ^= gfMul(g, gfExp[i])
+		}
+		gen = next
+	}
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := 0; j < n; j++ {
+			rem[j] ^= gfMul(gen[j+1], factor)
+		}
+	}
+	return rem
+}
+
+/* -------------------- 矩陣 -------------------- */
+
+func newQRMatrix(v int) *QRCode {
+	size := 17 + 4*v
+	q := &QRCode{Version: v, Size: size, modules: make([]bool, size*size), fixed: make([]bool, size*size)}
+
+	// finder（含分隔白邊）
+	for _, p := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := -1; dy <= 7; dy++ {
+			for dx := -1; dx <= 7; dx++ {
+				x, y := p[0]+dx, p[1]+dy
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+				ring := max(abs(dx-3), abs(dy-3))
+				q.setFixed(x, y, ring != 2 && ring != 4)
+			}
+		}
+	}
+	// timing
+	for i := 8; i < size-8; i++ {
+		q.setFixed(i, 6, i%2 == 0)
+		q.setFixed(6, i, i%2 == 0)
+	}
+	// 對齊圖案（跳過與 finder 重疊的三個角）
+	pos := qrAlignment[v]
+	for i, cy := range pos {
+		for j, cx := range pos {
+			last := len(pos) - 1
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFixed(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	// 格式資訊的位置先保留（遮罩選好後才寫入），加上固定的深色模組
+	for i := 0; i < 9; i++ {
+		if i == 6 { // timing
+			continue
+		}
+		q.setFixed(8, i, false)
+		q.setFixed(i, 8, false)
+	}
+	for i := 0; i < 8; i++ {
+		q.setFixed(size-1-i, 8, false)
+		q.setFixed(8, size-1-i, false)
+	}
+	q.setFixed(8, size-8, true)
+	// 版本資訊（7 以上）：右上與左下各一塊 6×3
+	if v >= 7 {
+		bits := qrVersionBits(v)
+		for i := 0; i < 18; i++ {
+			dark := bits>>uint(i)&1 == 1
+			a, b := i/3, size-11+i%3
+			q.setFixed(b, a, dark)
+			q.setFixed(a, b, dark)
+		}
+	}
+	return q
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (q *QRCode) setFixed(x, y int, dark bool) {
+	q.modules[y*q.Size+x] = dark
+	q.fixed[y*q.Size+x] = true
+}
+
+func (q *QRCode) clone() *QRCode {
+	c := *q
+	c.modules = append([]bool(nil), q.modules...)
+	return &c
+}
+
+// placeData 從右下角開始，兩欄一組上下來回填入；第 6 欄是 timing，跳過。
+// 填完 codeword 後剩下的模組（remainder bits）維持 0
+func (q *QRCode) placeData(codewords []byte) {
+	bit := 0
+	upward := true
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for i := 0; i < q.Size; i++ {
+			y := i
+			if upward {
+				y = q.Size - 1 - i
+			}
+			for _, x := range []int{right, right - 1} {
+				if q.fixed[y*q.Size+x] {
+					continue
+				}
+				if bit < 8*len(codewords) {
+					q.modules[y*q.Size+x] = codewords[bit/8]>>uint(7-bit%8)&1 == 1
+				}
+				bit++
+			}
+		}
+		upward = !upward
+	}
+}
+
+func qrMask(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.fixed[y*q.Size+x] && qrMask(mask, x, y) {
+				q.modules[y*q.Size+x] = !q.modules[y*q.Size+x]
+			}
+		}
+	}
+}
+
+// qrFormatBits 等級 M（00）+ 遮罩，BCH(15,5) 後與 0x5412 XOR
+func qrFormatBits(mask int) uint {
+	data := uint(mask) // M = 00
+	rem := data << 10
+	for i := 14; i >= 10; i-- {
+		if rem>>uint(i)&1 == 1 {
+			rem ^= 0x537 << uint(i-10)
+		}
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// qrVersionBits 版本 6 bits + BCH(18,6)
+func qrVersionBits(v int) uint {
+	rem := uint(v) << 12
+	for i := 17; i >= 12; i-- {
+		if rem>>uint(i)&1 == 1 {
+			rem ^= 0x1F25 << uint(i-12)
+		}
+	}
+	return uint(v)<<12 | rem
+}
+
+// placeFormat 格式資訊寫兩份：左上角繞 finder 一圈，以及拆到右上 / 左下
+func (q *QRCode) placeFormat(mask int) {
+	bits := qrFormatBits(mask)
+	size := q.Size
+	for i := 0; i < 15; i++ {
+		dark := bits>>uint(i)&1 == 1
+		// 左上：bit 0~7 在第 8 欄由下往上（跳過 timing），bit 8~14 在第 8 列由右往左
+		switch {
+		case i < 6:
+			q.modules[i*size+8] = dark
+		case i < 8:
+			q.modules[(i+1)*size+8] = dark
+		case i == 8:
+			q.modules[8*size+7] = dark
+		default:
+			q.modules[8*size+14-i] = dark
+		}
+		// 另一份：bit 0~7 在第 8 列由右往左，bit 8~14 在第 8 欄由下往上
+		if i < 8 {
+			q.modules[8*size+size-1-i] = dark
+		} else {
+			q.modules[(size-15+i)*size+8] = dark
+		}
+	}
+}
+
+/* -------------------- 遮罩罰分（規格 7.8.3）-------------------- */
+
+func (q *QRCode) penalty() int {
+	n := q.Size
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.modules[x*n+y]
+		}
+		return q.modules[y*n+x]
+	}
+	score := 0
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < n; y++ {
+			// 規則 1：連續 5 個以上同色
+			run := 1
+			for x := 1; x < n; x++ {
+				if at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				score += run - 2
+			}
+			// 規則 3：1:1:3:1:1 的 finder 樣式，前或後接 4 個淺色
+			for x := 0; x+10 < n; x++ {
+				var pattern [11]bool
+				for k := range pattern {
+					pattern[k] = at(x+k, y, vertical)
+				}
+				if pattern == [11]bool{true, false, true, true, true, false, true, false, false, false, false} ||
+					pattern == [11]bool{false, false, false, false, true, false, true, true, true, false, true} {
+					score += 40
+				}
+			}
+		}
+	}
+	// 規則 2：2×2 同色區塊
+	dark := 0
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			c := q.modules[y*n+x]
+			if c {
+				dark++
+			}
+			if x+1 < n && y+1 < n && c == q.modules[y*n+x+1] && c == q.modules[(y+1)*n+x] && c == q.modules[(y+1)*n+x+1] {
+				score += 3
+			}
+		}
+	}
+	// 規則 4：深色比例偏離 50%，每 5% 加 10 分
+	percent := dark * 100 / (n * n)
+	score += 10 * (abs(percent-50) / 5)
+	return score
+}
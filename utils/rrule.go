@@ -0,0 +1,331 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule 是 RFC 5545 RRULE 的子集：
+// FREQ=DAILY|WEEKLY|MONTHLY|YEARLY、INTERVAL、COUNT、UNTIL、BYDAY（MONTHLY 可帶序數，例如 1MO、-1FR）、BYMONTHDAY
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int        // 0 = 不限
+	Until      *time.Time // 含當下
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// WeekdayNum：N=0 代表「每個」該星期幾；N=1 第一個、N=-1 最後一個（只用於 MONTHLY）
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+var ErrInvalidRRule = errors.New("invalid RRULE")
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// 展開時的保險：避免奇怪規則（例如每月 31 號）跑太久
+const maxRRulePeriods = 10000
+
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidRRule)
+	}
+	r := &RRule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRRule, part)
+		}
+		key, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.Freq = val
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRRule, val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRRule)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive integer", ErrInvalidRRule)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := parseRRuleTime(val)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL %v", ErrInvalidRRule, err)
+			}
+			r.Until = &t
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY %q", ErrInvalidRRule, d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRRule)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRRule, key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRRule)
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != "MONTHLY" {
+			return nil, fmt.Errorf("%w: ordinal BYDAY is only supported with FREQ=MONTHLY", ErrInvalidRRule)
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != "MONTHLY" {
+		return nil, fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRRule)
+	}
+	return r, nil
+}
+
+func parseRRuleTime(v string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			if layout == "20060102" {
+				t = t.Add(24*time.Hour - time.Second) // 只有日期 → 當天結束
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad time %q", v)
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY %q", ErrInvalidRRule, s)
+	}
+	day, ok := weekdayCodes[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY %q", ErrInvalidRRule, s)
+	}
+	n := 0
+	if prefix := s[:len(s)-2]; prefix != "" {
+		var err error
+		if n, err = strconv.Atoi(prefix); err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("%w: BYDAY %q", ErrInvalidRRule, s)
+		}
+	}
+	return WeekdayNum{N: n, Day: day}, nil
+}
+
+// String 輸出正規化的 RRULE（不含 "RRULE:" 前綴）
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			code := strings.ToUpper(wd.Day.String()[:2])
+			if wd.N != 0 {
+				code = strconv.Itoa(wd.N) + code
+			}
+			days = append(days, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between 回傳開始時間落在 [from, to] 的發生時間（最多 limit 筆）
+// 時間在 dtstart 的時區計算，所以跨夏令時間仍維持當地時刻
+func (r *RRule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	r.iterate(dtstart, func(t time.Time) bool {
+		if t.After(to) {
+			return false
+		}
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+		return limit <= 0 || len(out) < limit
+	})
+	return out
+}
+
+// Contains 判斷 t 是否為規則中的一次發生
+func (r *RRule) Contains(dtstart, t time.Time) bool {
+	found := false
+	r.iterate(dtstart, func(o time.Time) bool {
+		if o.Equal(t) {
+			found = true
+		}
+		return !found && !o.After(t)
+	})
+	return found
+}
+
+// iterate 依時間順序逐一產生發生時間，fn 回 false 就停
+func (r *RRule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	emitted := 0
+	for k := 0; k < maxRRulePeriods; k++ {
+		cands := r.candidates(dtstart, k)
+		for _, t := range cands {
+			if t.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return
+			}
+			emitted++
+			if !fn(t) {
+				return
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// candidates 第 k 個週期（k*INTERVAL）內符合規則的時間，已排序
+func (r *RRule) candidates(dtstart time.Time, k int) []time.Time {
+	loc := dtstart.Location()
+	h, m, s := dtstart.Clock()
+	at := func(y int, mo time.Month, d int) time.Time { return time.Date(y, mo, d, h, m, s, 0, loc) }
+	step := k * r.Interval
+
+	var out []time.Time
+	switch r.Freq {
+	case "DAILY":
+		t := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+step)
+		if r.matchesWeekday(t.Weekday()) {
+			out = append(out, t)
+		}
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			out = append(out, at(dtstart.Year(), dtstart.Month(), dtstart.Day()+7*step))
+			break
+		}
+		// 以週一為一週開始
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := dtstart.Day() - offset + 7*step
+		for i := 0; i < 7; i++ {
+			t := at(dtstart.Year(), dtstart.Month(), monday+i)
+			if r.matchesWeekday(t.Weekday()) {
+				out = append(out, t)
+			}
+		}
+	case "MONTHLY":
+		first := at(dtstart.Year(), dtstart.Month()+time.Month(step), 1)
+		y, mo := first.Year(), first.Month()
+		days := daysIn(y, mo)
+		set := map[int]bool{}
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = days + d + 1
+			}
+			if d >= 1 && d <= days {
+				set[d] = true
+			}
+		}
+		for _, wd := range r.ByDay {
+			for _, d := range monthWeekdays(y, mo, wd.Day) {
+				set[d] = set[d] || wd.N == 0
+			}
+			if wd.N != 0 {
+				ds := monthWeekdays(y, mo, wd.Day)
+				idx := wd.N - 1
+				if wd.N < 0 {
+					idx = len(ds) + wd.N
+				}
+				if idx >= 0 && idx < len(ds) {
+					set[ds[idx]] = true
+				}
+			}
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && dtstart.Day() <= days {
+			set[dtstart.Day()] = true
+		}
+		for d, ok := range set {
+			if ok {
+				out = append(out, at(y, mo, d))
+			}
+		}
+	case "YEARLY":
+		y := dtstart.Year() + step
+		if dtstart.Day() <= daysIn(y, dtstart.Month()) {
+			out = append(out, at(y, dtstart.Month(), dtstart.Day()))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+func (r *RRule) matchesWeekday(d time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == d {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// monthWeekdays 該月所有「星期 wd」的日期
+func monthWeekdays(y int, m time.Month, wd time.Weekday) []int {
+	var out []int
+	for d := 1; d <= daysIn(y, m); d++ {
+		if time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday() == wd {
+			out = append(out, d)
+		}
+	}
+	return out
+}
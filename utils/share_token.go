@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 分享 token：簽章 + 到期時間，只對單一事件有效
+// typ=share 與登入用的 token 區分，兩者不能互用
+const shareTokenType = "share"
+
+var ErrInvalidShareToken = errors.New("invalid share token")
+
+func GenerateShareToken(eventID string, ttl time.Duration) (string, time.Time, error) {
+	exp := time.Now().Add(ttl).UTC().Truncate(time.Second)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ":     shareTokenType,
+		"eventId": eventID,
+		"exp":     exp.Unix(),
+	})
+	s, err := token.SignedString([]byte(secretKey))
+	return s, exp, err
+}
+
+// VerifyShareToken 檢查簽章、到期與事件 id
+func VerifyShareToken(token, eventID string) error {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("Unexpected signing method")
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil || !parsed.Valid {
+		return ErrInvalidShareToken
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != shareTokenType || claims["eventId"] != eventID {
+		return ErrInvalidShareToken
+	}
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil { // 沒有到期時間的不收
+		return ErrInvalidShareToken
+	}
+	return nil
+}
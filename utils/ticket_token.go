@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 票券 token：印在 QR code 裡，報到時驗證。
+// typ=ticket 與登入 / 分享 token 區分；不設到期時間，票券在報名被取消或作廢前都有效（由報到端檢查）。
+// claim 名稱用縮寫，讓 QR code 小一點
+const ticketTokenType = "ticket"
+
+var ErrInvalidTicket = errors.New("invalid ticket")
+
+// TicketClaims 票券內容：哪一筆報名、誰、哪個事件的哪一次
+type TicketClaims struct {
+	RegistrationID int64
+	UserID         int64
+	EventID        string
+	Occurrence     string
+}
+
+func GenerateTicketToken(t TicketClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ": ticketTokenType,
+		"rid": t.RegistrationID,
+		"uid": t.UserID,
+		"eid": t.EventID,
+		"occ": t.Occurrence,
+	})
+	return token.SignedString([]byte(secretKey))
+}
+
+// VerifyTicketToken 檢查簽章與 typ；被改過任何一個字都會失敗
+func VerifyTicketToken(token string) (TicketClaims, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("Unexpected signing method")
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil || !parsed.Valid {
+		return TicketClaims{}, ErrInvalidTicket
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != ticketTokenType {
+		return TicketClaims{}, ErrInvalidTicket
+	}
+	rid, _ := claims["rid"].(float64)
+	uid, _ := claims["uid"].(float64)
+	eid, _ := claims["eid"].(string)
+	occ, _ := claims["occ"].(string)
+	if rid == 0 || uid == 0 || eid == "" {
+		return TicketClaims{}, ErrInvalidTicket
+	}
+	return TicketClaims{RegistrationID: int64(rid), UserID: int64(uid), EventID: eid, Occurrence: occ}, nil
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 最小的 XLSX（Office Open XML）串流寫入器：單一工作表、全部是文字儲存格（inlineStr），沒有樣式。
+// 固定的 part 先寫，工作表最後寫、一列一列直接寫進 zip，不需要把整張表放在記憶體；
+// 匯出報名名單用，不想為了這個多一個相依套件
+
+var ErrXLSXClosed = errors.New("xlsx: writer is closed")
+
+const xlsxMaxSheetName = 31 // Excel 的工作表名稱上限
+
+var xlsxStaticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+	err   error // 第一個寫入錯誤；之後的呼叫都回傳它
+}
+
+// NewXLSXWriter 寫出固定的 part 與工作表開頭；sheetName 空白 → "Sheet1"
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	x := &XLSXWriter{zw: zip.NewWriter(w)}
+	for _, p := range xlsxStaticParts {
+		if err := x.writePart(p.name, p.body); err != nil {
+			return nil, err
+		}
+	}
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + xmlEscape(xlsxSheetName(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := x.writePart("xl/workbook.xml", workbook); err != nil {
+		return nil, err
+	}
+
+	sheet, err := x.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x.sheet = sheet
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *XLSXWriter) writePart(name, body string) error {
+	f, err := x.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, body)
+	return err
+}
+
+// WriteRow 新增一列；每個值都是文字儲存格
+func (x *XLSXWriter) WriteRow(cells []string) error {
+	if x.err != nil {
+		return x.err
+	}
+	if x.sheet == nil {
+		return ErrXLSXClosed
+	}
+	x.rows++
+	row := strconv.Itoa(x.rows)
+	var b strings.Builder
+	b.WriteString(`<row r="` + row + `">`)
+	for i, v := range cells {
+		if v == "" {
+			continue // 空白儲存格不用寫
+		}
+		b.WriteString(`<c r="` + XLSXColumn(i) + row + `" t="inlineStr"><is><t xml:space="preserve">`)
+		b.WriteString(xmlEscape(v))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+	_, x.err = io.WriteString(x.sheet, b.String())
+	return x.err
+}
+
+// Close 結束工作表並寫出 zip 的目錄；不會關閉底層的 io.Writer
+func (x *XLSXWriter) Close() error {
+	if x.sheet == nil {
+		return ErrXLSXClosed
+	}
+	if x.err == nil {
+		_, x.err = io.WriteString(x.sheet, `</sheetData></worksheet>`)
+	}
+	x.sheet = nil
+	if x.err != nil {
+		return x.err
+	}
+	return x.zw.Close()
+}
+
+// XLSXColumn 0 → "A"、25 → "Z"、26 → "AA"
+func XLSXColumn(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// 工作表名稱不能含 []:*?/\、最多 31 字
+func xlsxSheetName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if r := []rune(s); len(r) > xlsxMaxSheetName {
+		s = string(r[:xlsxMaxSheetName])
+	}
+	if s == "" {
+		return "Sheet1"
+	}
+	return s
+}
+
+// xml.EscapeText 也會把 XML 不允許的控制字元換成 U+FFFD
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}